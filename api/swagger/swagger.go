@@ -0,0 +1,16 @@
+// Package swagger embeds OpenAPI spec generated from the proto and Swagger UI page into the binary
+package swagger
+
+import (
+	_ "embed"
+)
+
+// Spec is OpenAPI (Swagger 2.0) spec of v1 API, it is generated by protoc-gen-swagger (make gen)
+//
+//go:embed v1/todo-service.swagger.json
+var Spec []byte
+
+// UI is Swagger UI page exploring Spec served at /swagger.json
+//
+//go:embed ui.html
+var UI []byte
@@ -3,104 +3,115 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
+	"strings"
 	"time"
 
-	"github.com/golang/protobuf/ptypes"
-	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
-	"google.golang.org/grpc"
-)
-
-const (
-	// apiVersion is version of API is provided
-	apiVersion = "v1"
+	"github.com/maslow123/go-grpc/pkg/client"
 )
 
 func main() {
 	// get configuration
-	address := flag.String("server", "", "gRPC server in format host:port")
+	address := flag.String("server", "localhost:9090", "gRPC server in format host:port, replicas are balanced for dns:///host:port or static:///host1:port,host2:port")
+	balancer := flag.String("balancer", client.DefaultBalancer, "Load balancing policy of server replicas: round_robin or pick_first")
+	useTLS := flag.Bool("tls", false, "Connect over TLS")
+	var tlsConfig client.TLSConfig
+	flag.StringVar(&tlsConfig.CAFile, "tls-ca-file", "", "PEM encoded CA certificates server certificate is verified with, system roots if empty")
+	flag.StringVar(&tlsConfig.CertFile, "tls-cert-file", "", "PEM encoded client certificate presented to server requiring mutual TLS")
+	flag.StringVar(&tlsConfig.KeyFile, "tls-key-file", "", "PEM encoded private key of -tls-cert-file")
+	flag.StringVar(&tlsConfig.ServerName, "tls-server-name", "", "Server name certificate is verified for, host of -server if empty")
+	flag.BoolVar(&tlsConfig.InsecureSkipVerify, "tls-skip-verify", false, "Do not verify server certificate, e.g. self-signed certificate of localhost")
+	keepaliveTime := flag.Duration("keepalive-time", 0, "How long connection is idle before server is pinged, server is not pinged if 0")
+	keepaliveTimeout := flag.Duration("keepalive-timeout", 20*time.Second, "How long ping ack is waited for before connection is closed")
+	md := flag.String("metadata", "", "Comma separated key=value pairs sent as metadata of every call, e.g. x-tenant-id=acme")
+	hmacSecret := flag.String("hmac-secret", "", "Shared secret to sign calls with, calls are not signed if empty")
+	timeout := flag.Duration("timeout", 5*time.Second, "Timeout of whole demo flow")
 	flag.Parse()
 
+	opts := []client.Option{client.WithBalancer(*balancer)}
+	if *useTLS {
+		cfg, err := tlsConfig.Load()
+		if err != nil {
+			log.Fatalf("Invalid TLS configuration: %v", err)
+		}
+		opts = append(opts, client.WithTLS(cfg))
+	}
+	if *keepaliveTime > 0 {
+		opts = append(opts, client.WithKeepalive(*keepaliveTime, *keepaliveTimeout))
+	}
+	if len(*md) > 0 {
+		kv, err := parseMetadata(*md)
+		if err != nil {
+			log.Fatalf("Invalid metadata: %v", err)
+		}
+		opts = append(opts, client.WithMetadata(kv...))
+	}
+	if len(*hmacSecret) > 0 {
+		opts = append(opts, client.WithHMACSecret(*hmacSecret))
+	}
+
 	// Set up a connection to the server
-	conn, err := grpc.Dial(*address, grpc.WithInsecure())
+	c, err := client.Dial(*address, opts...)
 	if err != nil {
 		log.Fatalf("Did not connect: %v", err)
 	}
-	defer conn.Close()
-
-	c := v1.NewTodoServiceClient(conn)
+	defer c.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
 	defer cancel()
 
 	t := time.Now().In(time.UTC)
-	reminder, _ := ptypes.TimestampProto(t)
 	pfx := t.Format(time.RFC3339Nano)
 
 	// Call Create
-	req1 := v1.CreateRequest{
-		Api: apiVersion,
-		Todo: &v1.Todo{
-			Title:       "title (" + pfx + ")",
-			Description: "description (" + pfx + ")",
-			Reminder:    reminder,
-		},
-	}
-	res1, err := c.Create(ctx, &req1)
+	id, err := c.Create(ctx, client.Todo{
+		Title:       "title (" + pfx + ")",
+		Description: "description (" + pfx + ")",
+		Reminder:    t,
+	})
 	if err != nil {
 		log.Fatalf("Create failed: %v", err)
 	}
-	log.Printf("Create resut: <%+v>\n\n", res1)
-
-	id := res1.Id
+	log.Printf("Create result: <%d>\n\n", id)
 
 	// Read
-	req2 := v1.ReadRequest{
-		Api: apiVersion,
-		Id:  id,
-	}
-
-	res2, err := c.Read(ctx, &req2)
+	todo, err := c.Get(ctx, id)
 	if err != nil {
 		log.Fatalf("Read failed: %v", err)
 	}
-	log.Printf("Read result: <%+v>\n\n", res2)
+	log.Printf("Read result: <%+v>\n\n", todo)
 
 	// Update
-	req3 := v1.UpdateRequest{
-		Api: apiVersion,
-		Todo: &v1.Todo{
-			Id:          res2.Todo.Id,
-			Title:       res2.Todo.Title,
-			Description: res2.Todo.Description + " updated",
-			Reminder:    res2.Todo.Reminder,
-		},
-	}
-
-	res3, err := c.Update(ctx, &req3)
-	if err != nil {
+	todo.Description += " updated"
+	if err := c.Update(ctx, todo); err != nil {
 		log.Fatalf("Update failed: %v", err)
 	}
-	log.Printf("Update result: <%+v>\n\n", res3)
+	log.Printf("Update result: <%+v>\n\n", todo)
 
 	// Call ReadAll
-	req4 := v1.ReadAllRequest{
-		Api: apiVersion,
-	}
-	res4, err := c.ReadAll(ctx, &req4)
+	todos, err := c.List(ctx)
 	if err != nil {
 		log.Fatalf("ReadAll failed: %v", err)
 	}
-	log.Printf("ReadAll result: <%+v>\n\n", res4)
+	log.Printf("ReadAll result: <%+v>\n\n", todos)
 
 	// Delete
-	req5 := v1.DeleteRequest{
-		Api: apiVersion,
-		Id:  id,
-	}
-	res5, err := c.Delete(ctx, &req5)
-	if err != nil {
+	if err := c.Delete(ctx, id); err != nil {
 		log.Fatalf("Delete failed: %v", err)
 	}
-	log.Printf("Delete result: <%+v>\n\n", res5)
+	log.Printf("Delete result: <%d>\n\n", id)
+}
+
+// parseMetadata parses comma separated key=value pairs into flat list of keys and values
+func parseMetadata(s string) ([]string, error) {
+	var kv []string
+	for _, pair := range strings.Split(s, ",") {
+		p := strings.SplitN(pair, "=", 2)
+		if len(p) != 2 || len(strings.TrimSpace(p[0])) == 0 {
+			return nil, fmt.Errorf("invalid pair '%s', key=value expected", pair)
+		}
+		kv = append(kv, strings.ToLower(strings.TrimSpace(p[0])), strings.TrimSpace(p[1]))
+	}
+	return kv, nil
 }
@@ -9,7 +9,7 @@ import (
 
 func main() {
 
-	if err := cmd.RunServer(); err != nil {
+	if err := cmd.NewCommand().Execute(); err != nil {
 		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
@@ -0,0 +1,203 @@
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// deviceTokenMaxLen is maximum length of stored device token
+	deviceTokenMaxLen = 4096
+
+	// webPushKeyLen and webPushAuthLen are decoded lengths of Web Push subscription key and secret
+	webPushKeyLen  = 65
+	webPushAuthLen = 16
+)
+
+// hashDeviceToken returns hash of device token, it is unique in database
+func hashDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// scanDevice reads device from the current row
+func scanDevice(rows *sql.Rows) (*Device, error) {
+	var (
+		d         Device
+		platform  string
+		createdAt time.Time
+	)
+
+	if err := rows.Scan(&d.Id, &platform, &d.Token, &createdAt); err != nil {
+		return nil, errDatabase("Failed to retrieve field values from device", err)
+	}
+	d.Platform = Device_Platform(Device_Platform_value[platform])
+
+	var err error
+	d.CreatedAt, err = ptypes.TimestampProto(createdAt)
+	if err != nil {
+		return nil, errInternal("Stored created_at has invalid format", err)
+	}
+
+	return &d, nil
+}
+
+// validateWebPushKey checks base64url encoded key of Web Push subscription has n bytes
+func validateWebPushKey(field, key string, n int) error {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+	if err != nil {
+		return errInvalidField(field, err)
+	}
+	if len(b) != n {
+		return errInvalidField(field, fmt.Errorf("%d bytes expected, got %d", n, len(b)))
+	}
+	return nil
+}
+
+// RegisterDevice registers device reminders are pushed to, registering the same token again updates its keys
+func (s *adminServiceServer) RegisterDevice(ctx context.Context, req *RegisterDeviceRequest) (*RegisterDeviceResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	if len(req.Token) == 0 || len(req.Token) > deviceTokenMaxLen {
+		return nil, errInvalidField("token", fmt.Errorf("length must be between 1 and %d", deviceTokenMaxLen))
+	}
+	switch req.Platform {
+	case Device_FCM:
+		if len(req.P256Dh) > 0 || len(req.Auth) > 0 {
+			return nil, errInvalidField("p256dh", fmt.Errorf("keys are used by Web Push subscriptions only"))
+		}
+	case Device_WEB_PUSH:
+		u, err := url.Parse(req.Token)
+		if err != nil || u.Scheme != "https" || len(u.Host) == 0 {
+			return nil, errInvalidField("token", fmt.Errorf("https endpoint of subscription expected"))
+		}
+		if err := validateWebPushKey("p256dh", req.P256Dh, webPushKeyLen); err != nil {
+			return nil, err
+		}
+		if err := validateWebPushKey("auth", req.Auth, webPushAuthLen); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errInvalidField("platform", fmt.Errorf("FCM or WEB_PUSH expected"))
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	hash := hashDeviceToken(req.Token)
+	query := `INSERT INTO device(platform, token, token_hash, p256dh, auth) VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE platform = VALUES(platform), p256dh = VALUES(p256dh), auth = VALUES(auth)`
+	if _, err := c.ExecContext(ctx, query, req.Platform.String(), req.Token, hash, req.P256Dh, req.Auth); err != nil {
+		return nil, errDatabase("Failed to insert into device", err)
+	}
+
+	rows, err := c.QueryContext(ctx, `SELECT id, platform, token, created_at FROM device WHERE token_hash = ?`, hash)
+	if err != nil {
+		return nil, errDatabase("Failed to select from device", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, errDatabase("Failed to retrieve data from device", err)
+		}
+		return nil, errInternal("Registered device is not found", nil)
+	}
+	d, err := scanDevice(rows)
+	if err != nil {
+		return nil, err
+	}
+	requestLogger(ctx).Info("Device registered", zap.Int64("id", d.Id), zap.String("platform", d.Platform.String()))
+
+	return &RegisterDeviceResponse{
+		Api:    apiVersion,
+		Device: d,
+	}, nil
+}
+
+// ListDevices lists all devices
+func (s *adminServiceServer) ListDevices(ctx context.Context, req *ListDevicesRequest) (*ListDevicesResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rows, err := c.QueryContext(ctx, `SELECT id, platform, token, created_at FROM device`)
+	if err != nil {
+		return nil, errDatabase("Failed to select from device", err)
+	}
+	defer rows.Close()
+
+	list := []*Device{}
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errDatabase("Failed to retrieve data from device", err)
+	}
+
+	return &ListDevicesResponse{
+		Api:     apiVersion,
+		Devices: list,
+	}, nil
+}
+
+// UnregisterDevice deletes device, reminders are not pushed to it any more
+func (s *adminServiceServer) UnregisterDevice(ctx context.Context, req *UnregisterDeviceRequest) (*UnregisterDeviceResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	res, err := c.ExecContext(ctx, `DELETE FROM device WHERE id = ?`, req.Id)
+	if err != nil {
+		return nil, errDatabase("Failed to delete device", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, errDatabase("Failed to retrieve rows affected value", err)
+	}
+
+	if rows == 0 {
+		return nil, errNotFound("Device", req.Id)
+	}
+	requestLogger(ctx).Info("Device unregistered", zap.Int64("id", req.Id))
+
+	return &UnregisterDeviceResponse{
+		Api:     apiVersion,
+		Deleted: rows,
+	}, nil
+}
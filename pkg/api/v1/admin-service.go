@@ -0,0 +1,325 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// apiKeyPrefixLen is number of leading key characters stored in plain text
+	apiKeyPrefixLen = 8
+
+	// apiKeySecretLen is number of random bytes the API key is generated from
+	apiKeySecretLen = 32
+)
+
+// adminServiceServer is implementation of v1.AdminServiceServer proto interface
+type adminServiceServer struct {
+	db *sql.DB
+}
+
+// NewAdminServiceServer creates Admin Service
+func NewAdminServiceServer(db *sql.DB) AdminServiceServer {
+	return &adminServiceServer{db: db}
+}
+
+// generateAPIKey returns new random API key together with its prefix and hash
+func generateAPIKey() (key, prefix, hash string, err error) {
+	var buf [apiKeySecretLen]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return "", "", "", err
+	}
+
+	key = base64.RawURLEncoding.EncodeToString(buf[:])
+	return key, key[:apiKeyPrefixLen], hashAPIKey(key), nil
+}
+
+// hashAPIKey returns hash of API key as it is stored in database
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAPIKeyLookup returns function returning scopes of API key stored in db, it returns error with
+// codes.Unauthenticated if the key is unknown, revoked or expired
+func NewAPIKeyLookup(db *sql.DB) func(ctx context.Context, key string) ([]string, error) {
+	return func(ctx context.Context, key string) ([]string, error) {
+		var (
+			scopes    string
+			expiresAt sql.NullTime
+			revokedAt sql.NullTime
+		)
+
+		// keys are looked up by hash, since the secret itself is not stored
+		query := `SELECT scopes, expires_at, revoked_at FROM api_key WHERE hash = ?`
+		err := db.QueryRowContext(ctx, query, hashAPIKey(key)).Scan(&scopes, &expiresAt, &revokedAt)
+		if err == sql.ErrNoRows {
+			return nil, errInvalidAPIKey("API key is not valid")
+		}
+		if err != nil {
+			return nil, errDatabase("Failed to select from api_key", err)
+		}
+
+		if revokedAt.Valid {
+			return nil, errInvalidAPIKey("API key is revoked")
+		}
+		if expiresAt.Valid && !time.Now().Before(expiresAt.Time) {
+			return nil, errInvalidAPIKey("API key is expired")
+		}
+		if len(scopes) == 0 {
+			return nil, nil
+		}
+
+		return strings.Split(scopes, ","), nil
+	}
+}
+
+// readAPIKey reads API key by ID
+func readAPIKey(ctx context.Context, c *sql.Conn, id int64) (*ApiKey, error) {
+	query := `SELECT id, name, prefix, scopes, expires_at, created_at, revoked_at FROM api_key WHERE id = ?`
+	rows, err := c.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, errDatabase("Failed to select from api_key", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, errDatabase("Failed to retrieve data from api_key", err)
+		}
+		return nil, errNotFound("API key", id)
+	}
+
+	return scanAPIKey(rows)
+}
+
+// scanAPIKey reads API key from the current row
+func scanAPIKey(rows *sql.Rows) (*ApiKey, error) {
+	var (
+		k         ApiKey
+		scopes    string
+		expiresAt sql.NullTime
+		createdAt time.Time
+		revokedAt sql.NullTime
+	)
+
+	if err := rows.Scan(
+		&k.Id,
+		&k.Name,
+		&k.Prefix,
+		&scopes,
+		&expiresAt,
+		&createdAt,
+		&revokedAt,
+	); err != nil {
+		return nil, errDatabase("Failed to retrieve field values from api_key", err)
+	}
+
+	if len(scopes) > 0 {
+		k.Scopes = strings.Split(scopes, ",")
+	}
+	k.Revoked = revokedAt.Valid
+
+	var err error
+	if expiresAt.Valid {
+		k.ExpiresAt, err = ptypes.TimestampProto(expiresAt.Time)
+		if err != nil {
+			return nil, errInternal("Stored expires_at has invalid format", err)
+		}
+	}
+
+	k.CreatedAt, err = ptypes.TimestampProto(createdAt)
+	if err != nil {
+		return nil, errInternal("Stored created_at has invalid format", err)
+	}
+
+	return &k, nil
+}
+
+// CreateApiKey creates new API key
+func (s *adminServiceServer) CreateApiKey(ctx context.Context, req *CreateApiKeyRequest) (*CreateApiKeyResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	for _, scope := range req.Scopes {
+		if len(scope) == 0 || strings.Contains(scope, ",") {
+			return nil, errInvalidField("scopes", fmt.Errorf("scope '%s' is empty or contains comma", scope))
+		}
+	}
+
+	var expiresAt sql.NullTime
+	if req.ExpiresAt != nil {
+		t, err := ptypes.Timestamp(req.ExpiresAt)
+		if err != nil {
+			return nil, errInvalidField("expires_at", err)
+		}
+		expiresAt = sql.NullTime{Time: t, Valid: true}
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	key, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, errInternal("Failed to generate API key", err)
+	}
+
+	// insert API key, only hash of the secret is stored
+	query := `INSERT INTO api_key(name, prefix, hash, scopes, expires_at) VALUES (?, ?, ?, ?, ?)`
+	res, err := c.ExecContext(ctx, query, req.Name, prefix, hash, strings.Join(req.Scopes, ","), expiresAt)
+	if err != nil {
+		return nil, errDatabase("Failed to insert into api_key", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, errDatabase("Failed to retrieve id for created API key", err)
+	}
+
+	k, err := readAPIKey(ctx, c, id)
+	if err != nil {
+		return nil, err
+	}
+	requestLogger(ctx).Info("API key created", zap.Int64("id", id), zap.String("prefix", prefix))
+
+	return &CreateApiKeyResponse{
+		Api:    apiVersion,
+		ApiKey: k,
+		Key:    key,
+	}, nil
+}
+
+// ListApiKeys lists all API keys
+func (s *adminServiceServer) ListApiKeys(ctx context.Context, req *ListApiKeysRequest) (*ListApiKeysResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	query := `SELECT id, name, prefix, scopes, expires_at, created_at, revoked_at FROM api_key`
+	rows, err := c.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errDatabase("Failed to select from api_key", err)
+	}
+	defer rows.Close()
+
+	list := []*ApiKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, k)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errDatabase("Failed to retrieve data from api_key", err)
+	}
+
+	return &ListApiKeysResponse{
+		Api:     apiVersion,
+		ApiKeys: list,
+	}, nil
+}
+
+// RotateApiKey replaces secret of API key keeping its name, scopes and expiry
+func (s *adminServiceServer) RotateApiKey(ctx context.Context, req *RotateApiKeyRequest) (*RotateApiKeyResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	key, prefix, hash, err := generateAPIKey()
+	if err != nil {
+		return nil, errInternal("Failed to generate API key", err)
+	}
+
+	query := `UPDATE api_key SET prefix = ?, hash = ? WHERE id = ? AND revoked_at IS NULL`
+	res, err := c.ExecContext(ctx, query, prefix, hash, req.Id)
+	if err != nil {
+		return nil, errDatabase("Failed to update api_key", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, errDatabase("Failed to retrieve rows affected value", err)
+	}
+
+	if rows == 0 {
+		return nil, errNotFound("API key", req.Id)
+	}
+
+	k, err := readAPIKey(ctx, c, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	requestLogger(ctx).Info("API key rotated", zap.Int64("id", req.Id), zap.String("prefix", prefix))
+
+	return &RotateApiKeyResponse{
+		Api:    apiVersion,
+		ApiKey: k,
+		Key:    key,
+	}, nil
+}
+
+// RevokeApiKey revokes API key
+func (s *adminServiceServer) RevokeApiKey(ctx context.Context, req *RevokeApiKeyRequest) (*RevokeApiKeyResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	query := `UPDATE api_key SET revoked_at = CURRENT_TIMESTAMP WHERE id = ? AND revoked_at IS NULL`
+	res, err := c.ExecContext(ctx, query, req.Id)
+	if err != nil {
+		return nil, errDatabase("Failed to revoke api_key", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, errDatabase("Failed to retrieve rows affected value", err)
+	}
+
+	if rows == 0 {
+		return nil, errNotFound("API key", req.Id)
+	}
+	requestLogger(ctx).Info("API key revoked", zap.Int64("id", req.Id))
+
+	return &RevokeApiKeyResponse{
+		Api:     apiVersion,
+		Revoked: rows,
+	}, nil
+}
@@ -0,0 +1,275 @@
+package v1
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"go.uber.org/zap"
+)
+
+const (
+	// webhookSecretLen is number of random bytes the webhook secret is generated from
+	webhookSecretLen = 32
+
+	// webhookDeliveriesLimit is number of recent deliveries listed by ListWebhookDeliveries
+	webhookDeliveriesLimit = 100
+)
+
+// webhookEvents are types of events webhooks can subscribe to, they match types of pkg/events
+var webhookEvents = []string{"TodoCreated", "TodoUpdated", "TodoDeleted"}
+
+// scanWebhook reads webhook from the current row
+func scanWebhook(rows *sql.Rows) (*Webhook, error) {
+	var (
+		w         Webhook
+		events    string
+		createdAt time.Time
+	)
+
+	if err := rows.Scan(&w.Id, &w.Url, &events, &createdAt); err != nil {
+		return nil, errDatabase("Failed to retrieve field values from webhook", err)
+	}
+
+	if len(events) > 0 {
+		w.Events = strings.Split(events, ",")
+	}
+
+	var err error
+	w.CreatedAt, err = ptypes.TimestampProto(createdAt)
+	if err != nil {
+		return nil, errInternal("Stored created_at has invalid format", err)
+	}
+
+	return &w, nil
+}
+
+// validWebhookEvent reports whether webhook can subscribe to events of type t
+func validWebhookEvent(t string) bool {
+	for _, e := range webhookEvents {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhook creates webhook events of todo changes are posted to
+func (s *adminServiceServer) CreateWebhook(ctx context.Context, req *CreateWebhookRequest) (*CreateWebhookResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	u, err := url.Parse(req.Url)
+	if err != nil {
+		return nil, errInvalidField("url", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return nil, errInvalidField("url", fmt.Errorf("'%s' is not http or https URL", req.Url))
+	}
+	for _, e := range req.Events {
+		if !validWebhookEvent(e) {
+			return nil, errInvalidField("events", fmt.Errorf("unknown event '%s', %s expected", e, strings.Join(webhookEvents, ", ")))
+		}
+	}
+
+	var buf [webhookSecretLen]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, errInternal("Failed to generate webhook secret", err)
+	}
+	secret := hex.EncodeToString(buf[:])
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	// secret is stored in plain text, payloads are signed with it
+	query := `INSERT INTO webhook(url, events, secret) VALUES (?, ?, ?)`
+	res, err := c.ExecContext(ctx, query, req.Url, strings.Join(req.Events, ","), secret)
+	if err != nil {
+		return nil, errDatabase("Failed to insert into webhook", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, errDatabase("Failed to retrieve id for created webhook", err)
+	}
+
+	rows, err := c.QueryContext(ctx, `SELECT id, url, events, created_at FROM webhook WHERE id = ?`, id)
+	if err != nil {
+		return nil, errDatabase("Failed to select from webhook", err)
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, errDatabase("Failed to retrieve data from webhook", err)
+		}
+		return nil, errNotFound("Webhook", id)
+	}
+	w, err := scanWebhook(rows)
+	if err != nil {
+		return nil, err
+	}
+	requestLogger(ctx).Info("Webhook created", zap.Int64("id", id), zap.String("url", u.Redacted()))
+
+	return &CreateWebhookResponse{
+		Api:     apiVersion,
+		Webhook: w,
+		Secret:  secret,
+	}, nil
+}
+
+// ListWebhooks lists all webhooks
+func (s *adminServiceServer) ListWebhooks(ctx context.Context, req *ListWebhooksRequest) (*ListWebhooksResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rows, err := c.QueryContext(ctx, `SELECT id, url, events, created_at FROM webhook`)
+	if err != nil {
+		return nil, errDatabase("Failed to select from webhook", err)
+	}
+	defer rows.Close()
+
+	list := []*Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errDatabase("Failed to retrieve data from webhook", err)
+	}
+
+	return &ListWebhooksResponse{
+		Api:      apiVersion,
+		Webhooks: list,
+	}, nil
+}
+
+// DeleteWebhook deletes webhook, its pending deliveries are not posted any more
+func (s *adminServiceServer) DeleteWebhook(ctx context.Context, req *DeleteWebhookRequest) (*DeleteWebhookResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errDatabase("Failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM webhook WHERE id = ?`, req.Id)
+	if err != nil {
+		return nil, errDatabase("Failed to delete webhook", err)
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return nil, errDatabase("Failed to retrieve rows affected value", err)
+	}
+
+	if rows == 0 {
+		return nil, errNotFound("Webhook", req.Id)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM webhook_delivery WHERE webhook_id = ?`, req.Id); err != nil {
+		return nil, errDatabase("Failed to delete webhook_delivery", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errDatabase("Failed to commit transaction", err)
+	}
+	requestLogger(ctx).Info("Webhook deleted", zap.Int64("id", req.Id))
+
+	return &DeleteWebhookResponse{
+		Api:     apiVersion,
+		Deleted: rows,
+	}, nil
+}
+
+// ListWebhookDeliveries lists recent deliveries of webhook, newest first
+func (s *adminServiceServer) ListWebhookDeliveries(ctx context.Context, req *ListWebhookDeliveriesRequest) (*ListWebhookDeliveriesResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	query := `SELECT id, webhook_id, event_id, event_type, status, attempts, response_code, last_error,
+		created_at, next_attempt_at, delivered_at FROM webhook_delivery WHERE webhook_id = ? ORDER BY id DESC LIMIT ?`
+	rows, err := c.QueryContext(ctx, query, req.WebhookId, webhookDeliveriesLimit)
+	if err != nil {
+		return nil, errDatabase("Failed to select from webhook_delivery", err)
+	}
+	defer rows.Close()
+
+	list := []*WebhookDelivery{}
+	for rows.Next() {
+		var (
+			d                        WebhookDelivery
+			status                   string
+			createdAt, nextAttemptAt time.Time
+			deliveredAt              sql.NullTime
+		)
+		if err := rows.Scan(&d.Id, &d.WebhookId, &d.EventId, &d.EventType, &status, &d.Attempts, &d.ResponseCode,
+			&d.LastError, &createdAt, &nextAttemptAt, &deliveredAt); err != nil {
+			return nil, errDatabase("Failed to retrieve field values from webhook_delivery", err)
+		}
+		d.Status = WebhookDelivery_Status(WebhookDelivery_Status_value[status])
+
+		if d.CreatedAt, err = ptypes.TimestampProto(createdAt); err != nil {
+			return nil, errInternal("Stored created_at has invalid format", err)
+		}
+		if d.Status == WebhookDelivery_PENDING {
+			if d.NextAttemptAt, err = ptypes.TimestampProto(nextAttemptAt); err != nil {
+				return nil, errInternal("Stored next_attempt_at has invalid format", err)
+			}
+		}
+		if deliveredAt.Valid {
+			if d.DeliveredAt, err = ptypes.TimestampProto(deliveredAt.Time); err != nil {
+				return nil, errInternal("Stored delivered_at has invalid format", err)
+			}
+		}
+		list = append(list, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errDatabase("Failed to retrieve data from webhook_delivery", err)
+	}
+
+	return &ListWebhookDeliveriesResponse{
+		Api:        apiVersion,
+		Deliveries: list,
+	}, nil
+}
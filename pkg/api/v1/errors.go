@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is domain of google.rpc.ErrorInfo details returned by services
+const errorDomain = "todo.v1"
+
+// Machine-readable error reasons sent to clients in google.rpc.ErrorInfo details
+const (
+	// ReasonUnsupportedAPI means API version requested by client is not implemented
+	ReasonUnsupportedAPI = "UNSUPPORTED_API_VERSION"
+	// ReasonInvalidField means request field has invalid value, metadata "field" names the field
+	ReasonInvalidField = "INVALID_FIELD"
+	// ReasonNotFound means requested entity does not exist, metadata "entity" and "id" identify it
+	ReasonNotFound = "NOT_FOUND"
+	// ReasonInvalidAPIKey means API key of the caller is unknown, revoked or expired
+	ReasonInvalidAPIKey = "INVALID_API_KEY"
+	// ReasonDatabaseUnavailable means server failed to connect to database
+	ReasonDatabaseUnavailable = "DATABASE_UNAVAILABLE"
+	// ReasonDatabaseError means database failed to execute query
+	ReasonDatabaseError = "DATABASE_ERROR"
+	// ReasonInternal means unexpected server side failure
+	ReasonInternal = "INTERNAL"
+)
+
+// Error is error returned by services. Client gets gRPC status with message and
+// google.rpc.ErrorInfo detail, the underlying cause and stack trace are only logged by server.
+type Error struct {
+	code     codes.Code
+	reason   string
+	message  string
+	metadata map[string]string
+	cause    error
+	stack    []uintptr
+}
+
+// newError creates Error and records stack trace of the caller
+func newError(code codes.Code, reason, message string, cause error) *Error {
+	var pcs [32]uintptr
+	n := runtime.Callers(3, pcs[:])
+
+	return &Error{
+		code:    code,
+		reason:  reason,
+		message: message,
+		cause:   cause,
+		stack:   pcs[:n],
+	}
+}
+
+// withMetadata adds key/value to error details
+func (e *Error) withMetadata(key, value string) *Error {
+	if e.metadata == nil {
+		e.metadata = map[string]string{}
+	}
+	e.metadata[key] = value
+
+	return e
+}
+
+// Error returns error message including its cause
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return e.message
+	}
+
+	return e.message + " -> " + e.cause.Error()
+}
+
+// Unwrap returns cause of the error
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Reason returns machine-readable reason of the error
+func (e *Error) Reason() string {
+	return e.reason
+}
+
+// GRPCStatus returns gRPC status sent to client, it is used by grpc and status packages
+func (e *Error) GRPCStatus() *status.Status {
+	s := status.New(e.code, e.message)
+
+	ds, err := s.WithDetails(&errdetails.ErrorInfo{
+		Reason:   e.reason,
+		Domain:   errorDomain,
+		Metadata: e.metadata,
+	})
+	if err != nil {
+		return s
+	}
+
+	return ds
+}
+
+// StackTrace returns stack trace of the place error was created at
+func (e *Error) StackTrace() string {
+	var sb strings.Builder
+
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+
+	return sb.String()
+}
+
+// errUnsupportedAPI returns error for API version that is not implemented
+func errUnsupportedAPI(api string) *Error {
+	return newError(codes.Unimplemented, ReasonUnsupportedAPI,
+		fmt.Sprintf("Unsupported API version: service implements API version '%s', but asked for '%s'", apiVersion, api), nil,
+	).withMetadata("api", api)
+}
+
+// errInvalidField returns error for request field with invalid value
+func errInvalidField(field string, cause error) *Error {
+	return newError(codes.InvalidArgument, ReasonInvalidField,
+		fmt.Sprintf("Field '%s' has invalid format", field), cause,
+	).withMetadata("field", field)
+}
+
+// errNotFound returns error for entity that does not exist
+func errNotFound(entity string, id int64) *Error {
+	return newError(codes.NotFound, ReasonNotFound,
+		fmt.Sprintf("%s with ID='%d' is not found", entity, id), nil,
+	).withMetadata("entity", entity).withMetadata("id", strconv.FormatInt(id, 10))
+}
+
+// errInvalidAPIKey returns error for API key that is not accepted
+func errInvalidAPIKey(message string) *Error {
+	return newError(codes.Unauthenticated, ReasonInvalidAPIKey, message, nil)
+}
+
+// errDatabaseUnavailable returns error for failed database connection
+func errDatabaseUnavailable(cause error) *Error {
+	return newError(codes.Unavailable, ReasonDatabaseUnavailable, "Failed to connect to database", cause)
+}
+
+// errDatabase returns error for failed database query
+func errDatabase(message string, cause error) *Error {
+	return newError(codes.Internal, ReasonDatabaseError, message, cause)
+}
+
+// errInternal returns error for unexpected server side failure
+func errInternal(message string, cause error) *Error {
+	return newError(codes.Internal, ReasonInternal, message, cause)
+}
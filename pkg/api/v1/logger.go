@@ -0,0 +1,13 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// requestLogger returns logger scoped to the call with its method, request id and caller
+func requestLogger(ctx context.Context) *zap.Logger {
+	return logger.FromContext(ctx)
+}
@@ -0,0 +1,254 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/maslow123/go-grpc/pkg/api/v1 (interfaces: TodoServiceClient,TodoStore)
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	grpc "google.golang.org/grpc"
+)
+
+// MockTodoServiceClient is a mock of TodoServiceClient interface.
+type MockTodoServiceClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockTodoServiceClientMockRecorder
+}
+
+// MockTodoServiceClientMockRecorder is the mock recorder for MockTodoServiceClient.
+type MockTodoServiceClientMockRecorder struct {
+	mock *MockTodoServiceClient
+}
+
+// NewMockTodoServiceClient creates a new mock instance.
+func NewMockTodoServiceClient(ctrl *gomock.Controller) *MockTodoServiceClient {
+	mock := &MockTodoServiceClient{ctrl: ctrl}
+	mock.recorder = &MockTodoServiceClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTodoServiceClient) EXPECT() *MockTodoServiceClientMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTodoServiceClient) Create(arg0 context.Context, arg1 *v1.CreateRequest, arg2 ...grpc.CallOption) (*v1.CreateResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(*v1.CreateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTodoServiceClientMockRecorder) Create(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodoServiceClient)(nil).Create), varargs...)
+}
+
+// Delete mocks base method.
+func (m *MockTodoServiceClient) Delete(arg0 context.Context, arg1 *v1.DeleteRequest, arg2 ...grpc.CallOption) (*v1.DeleteResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(*v1.DeleteResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTodoServiceClientMockRecorder) Delete(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoServiceClient)(nil).Delete), varargs...)
+}
+
+// GetVersion mocks base method.
+func (m *MockTodoServiceClient) GetVersion(arg0 context.Context, arg1 *v1.GetVersionRequest, arg2 ...grpc.CallOption) (*v1.GetVersionResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetVersion", varargs...)
+	ret0, _ := ret[0].(*v1.GetVersionResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVersion indicates an expected call of GetVersion.
+func (mr *MockTodoServiceClientMockRecorder) GetVersion(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVersion", reflect.TypeOf((*MockTodoServiceClient)(nil).GetVersion), varargs...)
+}
+
+// Read mocks base method.
+func (m *MockTodoServiceClient) Read(arg0 context.Context, arg1 *v1.ReadRequest, arg2 ...grpc.CallOption) (*v1.ReadResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Read", varargs...)
+	ret0, _ := ret[0].(*v1.ReadResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockTodoServiceClientMockRecorder) Read(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockTodoServiceClient)(nil).Read), varargs...)
+}
+
+// ReadAll mocks base method.
+func (m *MockTodoServiceClient) ReadAll(arg0 context.Context, arg1 *v1.ReadAllRequest, arg2 ...grpc.CallOption) (*v1.ReadAllResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReadAll", varargs...)
+	ret0, _ := ret[0].(*v1.ReadAllResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll.
+func (mr *MockTodoServiceClientMockRecorder) ReadAll(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockTodoServiceClient)(nil).ReadAll), varargs...)
+}
+
+// Update mocks base method.
+func (m *MockTodoServiceClient) Update(arg0 context.Context, arg1 *v1.UpdateRequest, arg2 ...grpc.CallOption) (*v1.UpdateResponse, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Update", varargs...)
+	ret0, _ := ret[0].(*v1.UpdateResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTodoServiceClientMockRecorder) Update(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoServiceClient)(nil).Update), varargs...)
+}
+
+// MockTodoStore is a mock of TodoStore interface.
+type MockTodoStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockTodoStoreMockRecorder
+}
+
+// MockTodoStoreMockRecorder is the mock recorder for MockTodoStore.
+type MockTodoStoreMockRecorder struct {
+	mock *MockTodoStore
+}
+
+// NewMockTodoStore creates a new mock instance.
+func NewMockTodoStore(ctrl *gomock.Controller) *MockTodoStore {
+	mock := &MockTodoStore{ctrl: ctrl}
+	mock.recorder = &MockTodoStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTodoStore) EXPECT() *MockTodoStoreMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockTodoStore) Create(arg0 context.Context, arg1 *v1.Todo) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTodoStoreMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTodoStore)(nil).Create), arg0, arg1)
+}
+
+// Delete mocks base method.
+func (m *MockTodoStore) Delete(arg0 context.Context, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockTodoStoreMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTodoStore)(nil).Delete), arg0, arg1)
+}
+
+// Read mocks base method.
+func (m *MockTodoStore) Read(arg0 context.Context, arg1 int64) (*v1.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Read", arg0, arg1)
+	ret0, _ := ret[0].(*v1.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Read indicates an expected call of Read.
+func (mr *MockTodoStoreMockRecorder) Read(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockTodoStore)(nil).Read), arg0, arg1)
+}
+
+// ReadAll mocks base method.
+func (m *MockTodoStore) ReadAll(arg0 context.Context) ([]*v1.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAll", arg0)
+	ret0, _ := ret[0].([]*v1.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAll indicates an expected call of ReadAll.
+func (mr *MockTodoStoreMockRecorder) ReadAll(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAll", reflect.TypeOf((*MockTodoStore)(nil).ReadAll), arg0)
+}
+
+// Update mocks base method.
+func (m *MockTodoStore) Update(arg0 context.Context, arg1 *v1.Todo) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockTodoStoreMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockTodoStore)(nil).Update), arg0, arg1)
+}
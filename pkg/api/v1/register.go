@@ -0,0 +1,12 @@
+package v1
+
+import (
+	"google.golang.org/grpc"
+)
+
+// RegisterServices registers Todo Service and Admin Service to s, unlike generated Register functions
+// it accepts any grpc.ServiceRegistrar, e.g. xDS server
+func RegisterServices(s grpc.ServiceRegistrar, todo TodoServiceServer, admin AdminServiceServer) {
+	s.RegisterService(&_TodoService_serviceDesc, todo)
+	s.RegisterService(&_AdminService_serviceDesc, admin)
+}
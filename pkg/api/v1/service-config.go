@@ -0,0 +1,79 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method"`
+}
+
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+type methodConfig struct {
+	Name        []methodName `json:"name"`
+	RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+}
+
+// idempotentMethods returns names of methods marked idempotent or free of side effects in proto
+func idempotentMethods() []methodName {
+	var methods []methodName
+	services := File_todo_service_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		sd := services.Get(i)
+		for j := 0; j < sd.Methods().Len(); j++ {
+			md := sd.Methods().Get(j)
+			opts, ok := md.Options().(*descriptorpb.MethodOptions)
+			if !ok || opts.GetIdempotencyLevel() == descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN {
+				continue
+			}
+			methods = append(methods, methodName{Service: string(sd.FullName()), Method: string(md.Name())})
+		}
+	}
+
+	return methods
+}
+
+// ServiceConfig returns default JSON gRPC service config for clients of v1 services. Calls of methods marked
+// idempotent in proto (idempotency_level option) are retried when server is unavailable.
+// Hedging is not published, grpc-go clients do not support it.
+func ServiceConfig() string {
+	b, err := json.Marshal(struct {
+		MethodConfig []methodConfig `json:"methodConfig"`
+	}{
+		MethodConfig: []methodConfig{{
+			Name: idempotentMethods(),
+			RetryPolicy: &retryPolicy{
+				MaxAttempts:          4,
+				InitialBackoff:       "0.1s",
+				MaxBackoff:           "1s",
+				BackoffMultiplier:    2,
+				RetryableStatusCodes: []string{"UNAVAILABLE"},
+			},
+		}},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return string(b)
+}
+
+// IdempotentMethods returns full names of methods marked idempotent or free of side effects in proto,
+// e.g. "/TodoService/Read", calls of them can be safely retried by clients
+func IdempotentMethods() []string {
+	var names []string
+	for _, m := range idempotentMethods() {
+		names = append(names, "/"+m.Service+"/"+m.Method)
+	}
+	return names
+}
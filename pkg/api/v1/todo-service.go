@@ -3,12 +3,11 @@ package v1
 import (
 	"context"
 	"database/sql"
-	"fmt"
-	"time"
+	"errors"
 
 	"github.com/golang/protobuf/ptypes"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/maslow123/go-grpc/pkg/version"
+	"go.uber.org/zap"
 )
 
 const (
@@ -18,22 +17,25 @@ const (
 
 // todoServiceServer is implementation of v1.TodoServiceServer proto interface
 type todoServiceServer struct {
-	db *sql.DB
+	store TodoStore
 }
 
-// NewTodoServiceServer creates Todo Service
+// NewTodoServiceServer creates Todo Service keeping todos in MySQL database db
 func NewTodoServiceServer(db *sql.DB) TodoServiceServer {
-	return &todoServiceServer{db: db}
+	return NewTodoServiceServerWithStore(NewSQLTodoStore(db))
 }
 
-// CheckAPI cheks if the API version requested by client is supported by server
-func (s *todoServiceServer) checkAPI(api string) error {
+// NewTodoServiceServerWithStore creates Todo Service keeping todos in store
+func NewTodoServiceServerWithStore(store TodoStore) TodoServiceServer {
+	return &todoServiceServer{store: store}
+}
+
+// checkAPI cheks if the API version requested by client is supported by server
+func checkAPI(api string) error {
 	// API version is "" means use current version of the service
 	if len(api) > 0 {
 		if apiVersion != api {
-			return status.Errorf(codes.Unimplemented,
-				"Unsupported API version: service implements API version '%s', but asked for '%s'", apiVersion, api,
-			)
+			return errUnsupportedAPI(api)
 		}
 	}
 
@@ -41,10 +43,10 @@ func (s *todoServiceServer) checkAPI(api string) error {
 }
 
 // connect returns SQL database connection from the pool
-func (s *todoServiceServer) connect(ctx context.Context) (*sql.Conn, error) {
-	c, err := s.db.Conn(ctx)
+func connect(ctx context.Context, db *sql.DB) (*sql.Conn, error) {
+	c, err := db.Conn(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to connect to database -> "+err.Error())
+		return nil, errDatabaseUnavailable(err)
 	}
 
 	return c, nil
@@ -53,35 +55,23 @@ func (s *todoServiceServer) connect(ctx context.Context) (*sql.Conn, error) {
 // Create new todo task
 func (s *todoServiceServer) Create(ctx context.Context, req *CreateRequest) (*CreateResponse, error) {
 	// check if the API version requested by client is suppoerted by server
-	if err := s.checkAPI(req.Api); err != nil {
+	if err := checkAPI(req.Api); err != nil {
 		return nil, err
 	}
 
-	// get SQL Connection from pool
-	c, err := s.connect(ctx)
-	if err != nil {
-		return nil, err
+	if req.Todo == nil {
+		return nil, errInvalidField("todo", nil)
 	}
 
-	defer c.Close()
-
-	reminder, err := ptypes.Timestamp(req.Todo.Reminder)
-	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "Reminder field has invalid format -> "+err.Error())
+	if _, err := ptypes.Timestamp(req.Todo.Reminder); err != nil {
+		return nil, errInvalidField("reminder", err)
 	}
 
-	// insert Todo entity data
-	query := `INSERT INTO todo(title, description, reminder) VALUES (?, ?, ?)`
-	res, err := c.ExecContext(ctx, query, req.Todo.Title, req.Todo.Description, reminder)
+	id, err := s.store.Create(ctx, req.Todo)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to insert into todo -> "+err.Error())
-	}
-
-	// get ID of creates Todo
-	id, err := res.LastInsertId()
-	if err != nil {
-		return nil, status.Error(codes.Unknown, "failed to retrieve id for created Todo -> "+err.Error())
+		return nil, err
 	}
+	requestLogger(ctx).Debug("Todo created", zap.Int64("id", id))
 
 	return &CreateResponse{
 		Api: apiVersion,
@@ -92,95 +82,43 @@ func (s *todoServiceServer) Create(ctx context.Context, req *CreateRequest) (*Cr
 // Read todo task
 func (s *todoServiceServer) Read(ctx context.Context, req *ReadRequest) (*ReadResponse, error) {
 	// check if the API version requested by client is supported by server
-	if err := s.checkAPI(req.Api); err != nil {
+	if err := checkAPI(req.Api); err != nil {
 		return nil, err
 	}
 
-	// get SQL connection from pool
-	c, err := s.connect(ctx)
-	if err != nil {
-		return nil, err
+	td, err := s.store.Read(ctx, req.Id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errNotFound("Todo", req.Id)
 	}
-
-	defer c.Close()
-
-	// query Todo by ID
-	query := `SELECT id, title, description, reminder FROM todo where id = ?`
-	rows, err := c.QueryContext(ctx, query, req.Id)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to select from Todo -> "+err.Error())
-	}
-
-	defer rows.Close()
-
-	if !rows.Next() {
-		if err := rows.Err(); err != nil {
-			return nil, status.Error(codes.Unknown, "failed to retrieve data from Todo -> "+err.Error())
-		}
-		return nil, status.Error(codes.NotFound, fmt.Sprintf("Todo with ID='%d' is not found"))
-	}
-
-	// get Todo Data
-	var td Todo
-	var reminder time.Time
-
-	if err := rows.Scan(
-		&td.Id,
-		&td.Title,
-		&td.Description,
-		&reminder,
-	); err != nil {
-		return nil, status.Error(codes.Unknown, fmt.Sprintf("Found multiple Todo rows with ID='%d'", req.Id))
-	}
-
-	td.Reminder, err = ptypes.TimestampProto(reminder)
-	if err != nil {
-		return nil, status.Error(codes.Unknown, "reminder field has invalid format -> "+err.Error())
-	}
-
-	if rows.Next() {
 		return nil, err
 	}
 
 	return &ReadResponse{
 		Api:  apiVersion,
-		Todo: &td,
+		Todo: td,
 	}, nil
 }
 
 // Update todo task
 func (s *todoServiceServer) Update(ctx context.Context, req *UpdateRequest) (*UpdateResponse, error) {
-	if err := s.checkAPI(req.Api); err != nil {
+	if err := checkAPI(req.Api); err != nil {
 		return nil, err
 	}
 
-	// get SQL connection from pool
-	c, err := s.connect(ctx)
-	if err != nil {
-		return nil, err
+	if req.Todo == nil {
+		return nil, errInvalidField("todo", nil)
 	}
-	defer c.Close()
 
-	reminder, err := ptypes.Timestamp(req.Todo.Reminder)
-	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, "Reminder field has invalid format -> "+err.Error())
+	if _, err := ptypes.Timestamp(req.Todo.Reminder); err != nil {
+		return nil, errInvalidField("reminder", err)
 	}
 
-	// update todo
-	query := `UPDATE todo SET title = ?, description = ?, reminder = ? WHERE id = ?`
-	res, err := c.ExecContext(
-		ctx,
-		query,
-		req.Todo.Title,
-		req.Todo.Description,
-		reminder,
-		req.Todo.Id,
-	)
-
-	rows, err := res.RowsAffected()
+	rows, err := s.store.Update(ctx, req.Todo)
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to retrieve rows affected value ->"+err.Error())
+		return nil, err
 	}
+	requestLogger(ctx).Debug("Todo updated", zap.Int64("id", req.Todo.Id), zap.Int64("rows", rows))
 
 	return &UpdateResponse{
 		Api:     apiVersion,
@@ -190,88 +128,53 @@ func (s *todoServiceServer) Update(ctx context.Context, req *UpdateRequest) (*Up
 
 // Delete todo task
 func (s *todoServiceServer) Delete(ctx context.Context, req *DeleteRequest) (*DeleteResponse, error) {
-	if err := s.checkAPI(req.Api); err != nil {
-		return nil, err
-	}
-
-	// get SQL Connection from pool
-	c, err := s.connect(ctx)
-	if err != nil {
+	if err := checkAPI(req.Api); err != nil {
 		return nil, err
 	}
-	defer c.Close()
 
-	// delete todo
-	query := "DELETE FROM todo WHERE id = ?"
-	res, err := c.ExecContext(ctx, query, req.Id)
-	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to delete Todo ->"+err.Error())
+	err := s.store.Delete(ctx, req.Id)
+	if errors.Is(err, ErrNotFound) {
+		return nil, errNotFound("Todo", req.Id)
 	}
-
-	rows, err := res.RowsAffected()
 	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to retrieve rows affected value -> "+err.Error())
-	}
-
-	if rows == 0 {
-		return nil, status.Error(codes.NotFound, fmt.Sprintf("Todo with ID = '%d' is not found", req.Id))
+		return nil, err
 	}
+	requestLogger(ctx).Debug("Todo deleted", zap.Int64("id", req.Id))
 
 	return &DeleteResponse{
 		Api:     apiVersion,
-		Deleted: rows,
+		Deleted: 1,
 	}, nil
 }
 
 // Read all todo tasks
 func (s *todoServiceServer) ReadAll(ctx context.Context, req *ReadAllRequest) (*ReadAllResponse, error) {
-	if err := s.checkAPI(req.Api); err != nil {
+	if err := checkAPI(req.Api); err != nil {
 		return nil, err
 	}
 
-	// get SQL Connection from pool
-	c, err := s.connect(ctx)
+	list, err := s.store.ReadAll(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer c.Close()
-
-	// get Todo Lost
-	query := `SELECT id, title, description, reminder FROM todo`
-	rows, err := c.QueryContext(ctx, query)
-
-	if err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to select from todo -> "+err.Error())
-	}
-	defer rows.Close()
-
-	var reminder time.Time
-	list := []*Todo{}
-
-	for rows.Next() {
-		td := new(Todo)
-		if err := rows.Scan(
-			&td.Id,
-			&td.Title,
-			&td.Description,
-			&reminder,
-		); err != nil {
-			return nil, status.Error(codes.Unknown, "Failed to retrieve field values from Todo -> "+err.Error())
-		}
-
-		td.Reminder, err = ptypes.TimestampProto(reminder)
-		if err != nil {
-			return nil, status.Error(codes.Unknown, "reminder field has invalid format -> "+err.Error())
-		}
-		list = append(list, td)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, status.Error(codes.Unknown, "Failed to retrieve data from Todo"+err.Error())
-	}
 
 	return &ReadAllResponse{
 		Api:   apiVersion,
 		Todos: list,
 	}, nil
 }
+
+// GetVersion returns build information of the server
+func (s *todoServiceServer) GetVersion(ctx context.Context, req *GetVersionRequest) (*GetVersionResponse, error) {
+	if err := checkAPI(req.Api); err != nil {
+		return nil, err
+	}
+
+	return &GetVersionResponse{
+		Api:       apiVersion,
+		Version:   version.Version,
+		GitCommit: version.GitCommit,
+		BuildDate: version.BuildDate,
+		GoVersion: version.GoVersion(),
+	}, nil
+}
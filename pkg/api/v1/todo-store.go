@@ -0,0 +1,302 @@
+package v1
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate mockgen -destination=mock/mock.go -package=mock . TodoServiceClient,TodoStore
+
+// ErrNotFound is returned by TodoStore for todo that does not exist
+var ErrNotFound = errors.New("todo is not found")
+
+// TodoStore is storage of todo tasks of Todo Service, requests are validated before they reach it.
+// Errors other than ErrNotFound are returned to clients, so they should carry gRPC status.
+type TodoStore interface {
+	// Create stores todo and returns its ID
+	Create(ctx context.Context, td *Todo) (int64, error)
+	// Read returns todo with id, ErrNotFound if it does not exist
+	Read(ctx context.Context, id int64) (*Todo, error)
+	// ReadAll returns all todos
+	ReadAll(ctx context.Context) ([]*Todo, error)
+	// Update replaces title, description and reminder of todo with td.Id and returns number of updated todos
+	Update(ctx context.Context, td *Todo) (int64, error)
+	// Delete deletes todo with id, ErrNotFound if it does not exist
+	Delete(ctx context.Context, id int64) error
+}
+
+// ChangeKind is kind of change of todo recorded by ChangeRecorder
+type ChangeKind int
+
+const (
+	// ChangeCreated is creation of todo
+	ChangeCreated ChangeKind = iota + 1
+	// ChangeUpdated is update of todo
+	ChangeUpdated
+	// ChangeDeleted is deletion of todo, only ID of the todo is recorded
+	ChangeDeleted
+)
+
+// ChangeRecorder records change of todo td in transaction tx of the change, e.g. into outbox table,
+// the change is rolled back if it fails
+type ChangeRecorder func(ctx context.Context, tx *sql.Tx, kind ChangeKind, td *Todo) error
+
+// sqlTodoStore is TodoStore keeping todos in todo table of MySQL database
+type sqlTodoStore struct {
+	db     *sql.DB
+	record ChangeRecorder
+}
+
+// NewSQLTodoStore creates TodoStore keeping todos in todo table of db
+func NewSQLTodoStore(db *sql.DB) TodoStore {
+	return &sqlTodoStore{db: db}
+}
+
+// NewSQLTodoStoreWithRecorder creates TodoStore keeping todos in todo table of db, every change is recorded
+// by record in transaction of the change
+func NewSQLTodoStoreWithRecorder(db *sql.DB, record ChangeRecorder) TodoStore {
+	return &sqlTodoStore{db: db, record: record}
+}
+
+// execer executes queries of store, it is either connection or transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// change runs mutate on connection c, if store has recorder it is run in transaction together with recording
+// of todo returned by mutate. Nothing is recorded if mutate returns nil todo.
+func (s *sqlTodoStore) change(ctx context.Context, c *sql.Conn, kind ChangeKind, mutate func(execer) (*Todo, error)) error {
+	if s.record == nil {
+		_, err := mutate(c)
+		return err
+	}
+
+	tx, err := c.BeginTx(ctx, nil)
+	if err != nil {
+		return errDatabase("Failed to begin transaction", err)
+	}
+
+	td, err := mutate(tx)
+	if err == nil && td != nil {
+		if err = s.record(ctx, tx, kind, td); err != nil {
+			err = errDatabase("Failed to record change of todo", err)
+		}
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errDatabase("Failed to commit transaction", err)
+	}
+	return nil
+}
+
+// Create inserts todo
+func (s *sqlTodoStore) Create(ctx context.Context, td *Todo) (int64, error) {
+	reminder, err := ptypes.Timestamp(td.Reminder)
+	if err != nil {
+		return 0, errInvalidField("reminder", err)
+	}
+
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	var id int64
+	err = s.change(ctx, c, ChangeCreated, func(ex execer) (*Todo, error) {
+		// insert Todo entity data
+		query := `INSERT INTO todo(title, description, reminder) VALUES (?, ?, ?)`
+		res, err := ex.ExecContext(ctx, query, td.Title, td.Description, reminder)
+		if err != nil {
+			return nil, errDatabase("Failed to insert into todo", err)
+		}
+
+		// get ID of creates Todo
+		id, err = res.LastInsertId()
+		if err != nil {
+			return nil, errDatabase("Failed to retrieve id for created Todo", err)
+		}
+
+		created := proto.Clone(td).(*Todo)
+		created.Id = id
+		return created, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// Read selects todo by ID
+func (s *sqlTodoStore) Read(ctx context.Context, id int64) (*Todo, error) {
+	// get SQL connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	// query Todo by ID
+	query := `SELECT id, title, description, reminder FROM todo where id = ?`
+	rows, err := c.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, errDatabase("Failed to select from todo", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, errDatabase("Failed to retrieve data from todo", err)
+		}
+		return nil, ErrNotFound
+	}
+
+	td, err := scanTodo(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if rows.Next() {
+		return nil, errInternal(fmt.Sprintf("Found multiple Todo rows with ID='%d'", id), nil)
+	}
+
+	return td, nil
+}
+
+// ReadAll selects all todos
+func (s *sqlTodoStore) ReadAll(ctx context.Context) ([]*Todo, error) {
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	// get Todo List
+	query := `SELECT id, title, description, reminder FROM todo`
+	rows, err := c.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errDatabase("Failed to select from todo", err)
+	}
+	defer rows.Close()
+
+	list := []*Todo{}
+	for rows.Next() {
+		td, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, td)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errDatabase("Failed to retrieve data from todo", err)
+	}
+
+	return list, nil
+}
+
+// Update updates todo by ID
+func (s *sqlTodoStore) Update(ctx context.Context, td *Todo) (int64, error) {
+	reminder, err := ptypes.Timestamp(td.Reminder)
+	if err != nil {
+		return 0, errInvalidField("reminder", err)
+	}
+
+	// get SQL connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return 0, err
+	}
+	defer c.Close()
+
+	var rows int64
+	err = s.change(ctx, c, ChangeUpdated, func(ex execer) (*Todo, error) {
+		// update todo, changed reminder fires again
+		query := `UPDATE todo SET title = ?, description = ?,
+			reminder_fired_at = IF(reminder <=> ?, reminder_fired_at, NULL), reminder = ? WHERE id = ?`
+		res, err := ex.ExecContext(ctx, query, td.Title, td.Description, reminder, reminder, td.Id)
+		if err != nil {
+			return nil, errDatabase("Failed to update todo", err)
+		}
+
+		rows, err = res.RowsAffected()
+		if err != nil {
+			return nil, errDatabase("Failed to retrieve rows affected value", err)
+		}
+
+		if rows == 0 {
+			return nil, nil
+		}
+		return proto.Clone(td).(*Todo), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rows, nil
+}
+
+// Delete deletes todo by ID
+func (s *sqlTodoStore) Delete(ctx context.Context, id int64) error {
+	// get SQL Connection from pool
+	c, err := connect(ctx, s.db)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return s.change(ctx, c, ChangeDeleted, func(ex execer) (*Todo, error) {
+		// delete todo
+		query := "DELETE FROM todo WHERE id = ?"
+		res, err := ex.ExecContext(ctx, query, id)
+		if err != nil {
+			return nil, errDatabase("Failed to delete from todo", err)
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return nil, errDatabase("Failed to retrieve rows affected value", err)
+		}
+
+		if rows == 0 {
+			return nil, ErrNotFound
+		}
+		return &Todo{Id: id}, nil
+	})
+}
+
+// scanTodo reads todo from current row of rows
+func scanTodo(rows *sql.Rows) (*Todo, error) {
+	var td Todo
+	var reminder time.Time
+
+	if err := rows.Scan(
+		&td.Id,
+		&td.Title,
+		&td.Description,
+		&reminder,
+	); err != nil {
+		return nil, errDatabase("Failed to retrieve field values from todo", err)
+	}
+
+	var err error
+	td.Reminder, err = ptypes.TimestampProto(reminder)
+	if err != nil {
+		return nil, errInternal("Stored reminder has invalid format", err)
+	}
+
+	return &td, nil
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// SignatureHeader is HTTP header and gRPC metadata key that contains request signature
+	SignatureHeader = "X-Signature"
+
+	// TimestampHeader is HTTP header and gRPC metadata key that contains unix time the request was signed at
+	TimestampHeader = "X-Signature-Timestamp"
+
+	// signaturePrefix is scheme of the signature in SignatureHeader
+	signaturePrefix = "sha256="
+)
+
+var (
+	// ErrMissingSignature is returned when request is not signed
+	ErrMissingSignature = errors.New("request signature is missing")
+
+	// ErrInvalidSignature is returned when signature does not match request
+	ErrInvalidSignature = errors.New("request signature is invalid")
+
+	// ErrExpiredSignature is returned when signature timestamp is outside of allowed tolerance
+	ErrExpiredSignature = errors.New("request signature is expired")
+)
+
+// Sign returns signature of request signed at t with shared secret.
+// Target is gRPC full method name (e.g. "/TodoService/Create") or HTTP method
+// and path (e.g. "POST /v1/todo"), body is serialized request message or HTTP body.
+func Sign(secret string, t time.Time, target string, body []byte) string {
+	sum := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10)))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(target))
+	mac.Write([]byte("\n"))
+	mac.Write([]byte(hex.EncodeToString(sum[:])))
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature of request, signatures older or newer than tolerance relative to now are rejected
+func Verify(secret, signature, timestamp, target string, body []byte, tolerance time.Duration, now time.Time) error {
+	if len(signature) == 0 || len(timestamp) == 0 {
+		return ErrMissingSignature
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	t := time.Unix(unix, 0)
+	if d := now.Sub(t); d > tolerance || d < -tolerance {
+		return ErrExpiredSignature
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, t, target, body))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// MessageBody returns bytes of gRPC message that are covered by signature.
+// Message is serialized deterministically, so client and server get the same bytes.
+func MessageBody(msg interface{}) ([]byte, error) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, nil
+	}
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
+// UnarySigningClientInterceptor returns client interceptor that signs every call with shared secret
+func UnarySigningClientInterceptor(secret string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		body, err := MessageBody(req)
+		if err != nil {
+			return err
+		}
+
+		t := time.Now()
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			strings.ToLower(TimestampHeader), strconv.FormatInt(t.Unix(), 10),
+			strings.ToLower(SignatureHeader), Sign(secret, t, method, body),
+		)
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
@@ -0,0 +1,53 @@
+package auth_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/auth"
+)
+
+func TestVerify(t *testing.T) {
+	const (
+		secret    = "secret"
+		target    = "POST /v1/todo"
+		tolerance = 5 * time.Minute
+	)
+	body := []byte(`{"title":"title"}`)
+	signedAt := time.Unix(1700000000, 0)
+	signature := auth.Sign(secret, signedAt, target, body)
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		timestamp string
+		target    string
+		body      []byte
+		now       time.Time
+		want      error
+	}{
+		{"valid", secret, signature, timestamp, target, body, signedAt, nil},
+		{"clock behind within tolerance", secret, signature, timestamp, target, body, signedAt.Add(-tolerance), nil},
+		{"clock ahead within tolerance", secret, signature, timestamp, target, body, signedAt.Add(tolerance), nil},
+		{"expired", secret, signature, timestamp, target, body, signedAt.Add(tolerance + time.Second), auth.ErrExpiredSignature},
+		{"signed in future", secret, signature, timestamp, target, body, signedAt.Add(-tolerance - time.Second), auth.ErrExpiredSignature},
+		{"missing signature", secret, "", timestamp, target, body, signedAt, auth.ErrMissingSignature},
+		{"missing timestamp", secret, signature, "", target, body, signedAt, auth.ErrMissingSignature},
+		{"unknown scheme", secret, "md5=" + signature[len("sha256="):], timestamp, target, body, signedAt, auth.ErrInvalidSignature},
+		{"invalid timestamp", secret, signature, "yesterday", target, body, signedAt, auth.ErrInvalidSignature},
+		{"other timestamp", secret, signature, strconv.FormatInt(signedAt.Unix()+1, 10), target, body, signedAt, auth.ErrInvalidSignature},
+		{"other secret", "other", signature, timestamp, target, body, signedAt, auth.ErrInvalidSignature},
+		{"other target", secret, signature, timestamp, "DELETE /v1/todo", body, signedAt, auth.ErrInvalidSignature},
+		{"other body", secret, signature, timestamp, target, []byte(`{}`), signedAt, auth.ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := auth.Verify(tt.secret, tt.signature, tt.timestamp, tt.target, tt.body, tolerance, tt.now); err != tt.want {
+				t.Fatalf("Verify returned %v, %v expected", err, tt.want)
+			}
+		})
+	}
+}
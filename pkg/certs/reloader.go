@@ -0,0 +1,131 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Reloader keeps TLS certificate loaded from files and reloads it when they change.
+// Existing connections keep using the certificate they were established with,
+// new handshakes get the reloaded one.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	log      *zap.Logger
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	modTime time.Time
+}
+
+// NewReloader loads certificate and key files, reloads are logged to log
+func NewReloader(certFile, keyFile string, log *zap.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, log: logger.OrNop(log)}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload reads certificate and key files, current certificate is kept if they are invalid
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+
+	modTime, err := r.lastModified()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+
+	return nil
+}
+
+// GetCertificate returns current certificate, it implements tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.cert, nil
+}
+
+// TLSConfig returns server TLS config that always uses current certificate
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: r.GetCertificate,
+	}
+}
+
+// Watch reloads certificate when files are modified, files are checked every interval.
+// It returns at once if interval is not positive, so certificate is reloaded only by Reload.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			modTime, err := r.lastModified()
+			if err != nil {
+				r.log.Warn("Failed to check TLS certificate files", zap.String("reason", err.Error()))
+				continue
+			}
+
+			r.mu.RLock()
+			changed := modTime.After(r.modTime)
+			r.mu.RUnlock()
+
+			if changed {
+				r.reload("certificate files changed")
+			}
+		}
+	}
+}
+
+// reload reloads certificate and logs the result
+func (r *Reloader) reload(reason string) {
+	if err := r.Reload(); err != nil {
+		r.log.Error("Failed to reload TLS certificate",
+			zap.String("trigger", reason),
+			zap.String("reason", err.Error()),
+		)
+		return
+	}
+
+	r.log.Info("TLS certificate reloaded", zap.String("trigger", reason))
+}
+
+// lastModified returns latest modification time of certificate and key files
+func (r *Reloader) lastModified() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+
+	return latest, nil
+}
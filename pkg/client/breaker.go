@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is returned without calling server while circuit breaker of its target is open,
+// it can be matched with errors.Is
+var ErrCircuitOpen = status.Error(codes.Unavailable, "circuit breaker is open")
+
+// BreakerPolicy configures circuit breaker failing calls fast while server fails most of them
+type BreakerPolicy struct {
+	// Window is period failure rate is measured over, counts are reset when it passes
+	Window time.Duration
+	// MinCalls is number of calls in window needed before breaker opens, so few failures do not open it
+	MinCalls int
+	// FailureRate is ratio (0..1) of failed calls in window breaker opens at
+	FailureRate float64
+	// OpenTimeout is how long breaker is open, then single trial call is let through
+	// and breaker is closed if it succeeds or opened again if it fails
+	OpenTimeout time.Duration
+	// Codes are status codes of calls counted as failures, other calls are counted as successes
+	Codes []codes.Code
+}
+
+// DefaultBreakerPolicy is policy of WithCircuitBreaker
+var DefaultBreakerPolicy = BreakerPolicy{
+	Window:      10 * time.Second,
+	MinCalls:    20,
+	FailureRate: 0.5,
+	OpenTimeout: 5 * time.Second,
+	Codes:       []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+}
+
+// breakerState is state of circuit breaker
+type breakerState int
+
+const (
+	// breakerClosed lets calls through and counts their failures
+	breakerClosed breakerState = iota
+	// breakerOpen fails calls without calling server
+	breakerOpen
+	// breakerHalfOpen lets single trial call through
+	breakerHalfOpen
+)
+
+// breaker is circuit breaker of single target
+type breaker struct {
+	p BreakerPolicy
+
+	mu          sync.Mutex
+	state       breakerState
+	windowStart time.Time
+	calls       int
+	failures    int
+	openedAt    time.Time
+}
+
+// allow reports whether call may be made, trial is set if it is trial call of half open breaker
+func (b *breaker) allow(now time.Time) (ok, trial bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if now.Sub(b.openedAt) < b.p.OpenTimeout {
+			return false, false
+		}
+		b.state = breakerHalfOpen
+		return true, true
+	case breakerHalfOpen:
+		// trial call is in flight
+		return false, false
+	}
+	return true, false
+}
+
+// done records result of call allowed by allow
+func (b *breaker) done(now time.Time, trial, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if trial {
+		if failed {
+			b.state, b.openedAt = breakerOpen, now
+		} else {
+			b.reset(now)
+		}
+		return
+	}
+	if b.state != breakerClosed {
+		return
+	}
+
+	if now.Sub(b.windowStart) >= b.p.Window {
+		b.windowStart, b.calls, b.failures = now, 0, 0
+	}
+	b.calls++
+	if failed {
+		b.failures++
+	}
+	if b.calls >= b.p.MinCalls && float64(b.failures) >= b.p.FailureRate*float64(b.calls) {
+		b.state, b.openedAt = breakerOpen, now
+	}
+}
+
+// cancelled records call allowed by allow that is cancelled by caller, trial is given to next call
+func (b *breaker) cancelled(trial bool) {
+	if !trial {
+		return
+	}
+	b.mu.Lock()
+	b.state, b.openedAt = breakerOpen, time.Time{}
+	b.mu.Unlock()
+}
+
+// reset closes breaker and starts new window
+func (b *breaker) reset(now time.Time) {
+	b.state = breakerClosed
+	b.windowStart, b.calls, b.failures = now, 0, 0
+}
+
+// failed reports whether call failed with err is counted as failure by policy
+func (p BreakerPolicy) failed(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakerInterceptor returns interceptor failing calls with ErrCircuitOpen while failure rate of calls
+// to their target is above policy, each target of connections using interceptor has its own breaker.
+// Calls cancelled by caller are not counted.
+func BreakerInterceptor(p BreakerPolicy) grpc.UnaryClientInterceptor {
+	var mu sync.Mutex
+	breakers := map[string]*breaker{}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		b, ok := breakers[cc.Target()]
+		if !ok {
+			b = &breaker{p: p, windowStart: time.Now()}
+			breakers[cc.Target()] = b
+		}
+		mu.Unlock()
+
+		ok, trial := b.allow(time.Now())
+		if !ok {
+			return ErrCircuitOpen
+		}
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) == codes.Canceled {
+			b.cancelled(trial)
+			return err
+		}
+		b.done(time.Now(), trial, p.failed(err))
+		return err
+	}
+}
@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testBreakerPolicy opens breaker when half of at least 4 calls in a second fail
+var testBreakerPolicy = BreakerPolicy{
+	Window:      time.Second,
+	MinCalls:    4,
+	FailureRate: 0.5,
+	OpenTimeout: time.Second,
+	Codes:       []codes.Code{codes.Unavailable},
+}
+
+// record makes call of breaker b at now with result failed, it reports whether call was allowed
+func record(b *breaker, now time.Time, failed bool) bool {
+	ok, trial := b.allow(now)
+	if ok {
+		b.done(now, trial, failed)
+	}
+	return ok
+}
+
+func TestBreakerOpens(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name     string
+		failures []bool
+		wantOpen bool
+	}{
+		{"successes", []bool{false, false, false, false}, false},
+		{"failures below rate", []bool{true, false, false, false}, false},
+		{"failures at rate", []bool{true, false, true, false}, true},
+		{"failures below min calls", []bool{true, true, true}, false},
+		{"all failed", []bool{true, true, true, true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &breaker{p: testBreakerPolicy, windowStart: start}
+			for i, failed := range tt.failures {
+				record(b, start.Add(time.Duration(i)*time.Millisecond), failed)
+			}
+			if got := b.state == breakerOpen; got != tt.wantOpen {
+				t.Fatalf("breaker is open: %v, %v expected", got, tt.wantOpen)
+			}
+		})
+	}
+}
+
+func TestBreakerWindow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	b := &breaker{p: testBreakerPolicy, windowStart: start}
+
+	// failures of passed window are not counted
+	record(b, start, true)
+	record(b, start, true)
+	record(b, start, true)
+	record(b, start.Add(time.Second), true)
+	if b.state != breakerClosed {
+		t.Fatalf("breaker opened by failures of different windows")
+	}
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	open := func() *breaker {
+		b := &breaker{p: testBreakerPolicy, windowStart: start}
+		for i := 0; i < testBreakerPolicy.MinCalls; i++ {
+			record(b, start, true)
+		}
+		return b
+	}
+
+	b := open()
+	if record(b, start.Add(testBreakerPolicy.OpenTimeout/2), false) {
+		t.Fatalf("call allowed while breaker is open")
+	}
+
+	// single trial call is let through after open timeout
+	trialAt := start.Add(testBreakerPolicy.OpenTimeout)
+	ok, trial := b.allow(trialAt)
+	if !ok || !trial {
+		t.Fatalf("trial call not allowed after open timeout")
+	}
+	if ok, _ := b.allow(trialAt); ok {
+		t.Fatalf("second call allowed while trial call is in flight")
+	}
+
+	// successful trial closes breaker
+	b.done(trialAt, true, false)
+	if b.state != breakerClosed || !record(b, trialAt, false) {
+		t.Fatalf("breaker not closed by successful trial call")
+	}
+
+	// failed trial opens breaker again
+	b = open()
+	ok, trial = b.allow(trialAt)
+	if !ok || !trial {
+		t.Fatalf("trial call not allowed after open timeout")
+	}
+	b.done(trialAt, true, true)
+	if b.state != breakerOpen || record(b, trialAt.Add(testBreakerPolicy.OpenTimeout/2), false) {
+		t.Fatalf("breaker not opened again by failed trial call")
+	}
+
+	// cancelled trial is given to next call
+	b = open()
+	if ok, trial = b.allow(trialAt); !ok || !trial {
+		t.Fatalf("trial call not allowed after open timeout")
+	}
+	b.cancelled(true)
+	if ok, trial = b.allow(trialAt); !ok || !trial {
+		t.Fatalf("trial call not allowed after trial call was cancelled")
+	}
+}
+
+func TestBreakerInterceptor(t *testing.T) {
+	intercept := BreakerInterceptor(testBreakerPolicy)
+	cc, err := grpc.Dial("passthrough:///breaker-test", grpc.WithInsecure())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer cc.Close()
+
+	var calls int
+	failing := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "failed")
+	}
+	for i := 0; i < testBreakerPolicy.MinCalls; i++ {
+		_ = intercept(context.Background(), "/TodoService/Read", nil, nil, cc, failing)
+	}
+
+	err = intercept(context.Background(), "/TodoService/Read", nil, nil, cc, failing)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call returned %v, %v expected", err, ErrCircuitOpen)
+	}
+	if calls != testBreakerPolicy.MinCalls {
+		t.Fatalf("server was called %d times, %d expected", calls, testBreakerPolicy.MinCalls)
+	}
+}
@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	// register client side health checking, so replicas that are not serving are skipped
+	_ "google.golang.org/grpc/health"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// apiVersion is version of API requested by client
+	apiVersion = "v1"
+)
+
+// Full gRPC method names of Todo Service calls, e.g. to set their timeouts by WithMethodTimeout
+const (
+	MethodCreate     = "/TodoService/Create"
+	MethodRead       = "/TodoService/Read"
+	MethodReadAll    = "/TodoService/ReadAll"
+	MethodUpdate     = "/TodoService/Update"
+	MethodDelete     = "/TodoService/Delete"
+	MethodGetVersion = "/TodoService/GetVersion"
+)
+
+// Todo is todo task
+type Todo struct {
+	ID          int64
+	Title       string
+	Description string
+	// Reminder is time todo is due at, it is required by server
+	Reminder time.Time
+}
+
+// Version is build information of server
+type Version struct {
+	Version   string
+	GitCommit string
+	BuildDate string
+	GoVersion string
+}
+
+// Client is client of Todo Service, it is safe for concurrent use
+type Client struct {
+	conn     *grpc.ClientConn
+	todo     v1.TodoServiceClient
+	timeout  time.Duration
+	timeouts map[string]time.Duration
+	// owned is set if conn is opened by Dial, so it is closed by Close
+	owned bool
+}
+
+// Dial connects to Todo Service at address in format host:port, connection is established in background
+// and re-established when it is lost.
+// Calls are balanced over replicas if address resolves to several of them, e.g. dns:///todo.example.com:9090
+// or static:///10.0.0.1:9090,10.0.0.2:9090.
+func Dial(address string, opts ...Option) (*Client, error) {
+	retry := DefaultRetryPolicy
+	o := options{timeout: DefaultTimeout, retry: &retry, balancer: DefaultBalancer}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithInsecure()}
+	if o.tlsConfig != nil {
+		dialOpts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(o.tlsConfig))}
+	}
+	// calls are retried by interceptor, so retries of service config do not multiply attempts
+	dialOpts = append(dialOpts,
+		grpc.WithDisableRetry(),
+		grpc.WithResolvers(staticBuilder{}),
+		grpc.WithDefaultServiceConfig(fmt.Sprintf(
+			`{"loadBalancingConfig": [{"%s": {}}], "healthCheckConfig": {"serviceName": ""}}`, o.balancer)),
+	)
+	if o.keepalive != nil {
+		dialOpts = append(dialOpts, grpc.WithKeepaliveParams(*o.keepalive))
+	}
+
+	interceptors := o.interceptors
+	if o.metrics != nil {
+		// calls are measured as seen by caller, including retries and calls failed by breaker
+		metrics, err := MetricsInterceptor(o.metrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register client metrics: %v", err)
+		}
+		interceptors = append(interceptors, metrics)
+	}
+	if len(o.metadata) > 0 {
+		interceptors = append(interceptors, metadataInterceptor(o.metadata))
+	}
+	if o.breaker != nil {
+		// breaker counts calls, not their retries
+		interceptors = append(interceptors, BreakerInterceptor(*o.breaker))
+	}
+	if o.retry != nil {
+		interceptors = append(interceptors, RetryInterceptor(*o.retry))
+	}
+	if len(o.hmacSecret) > 0 {
+		// signing goes last, so every attempt is signed at time it is sent
+		interceptors = append(interceptors, auth.UnarySigningClientInterceptor(o.hmacSecret))
+	}
+	if len(interceptors) > 0 {
+		dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(interceptors...))
+	}
+	dialOpts = append(dialOpts, o.dialOptions...)
+
+	conn, err := grpc.Dial(address, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	c := newClient(conn, o)
+	c.owned = true
+	return c, nil
+}
+
+// NewFromConn returns client calling Todo Service over conn, only timeout options are used.
+// Conn is not closed by Close.
+func NewFromConn(conn *grpc.ClientConn, opts ...Option) *Client {
+	o := options{timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return newClient(conn, o)
+}
+
+// newClient returns client calling Todo Service over conn with timeouts of o
+func newClient(conn *grpc.ClientConn, o options) *Client {
+	return &Client{
+		conn:     conn,
+		todo:     v1.NewTodoServiceClient(conn),
+		timeout:  o.timeout,
+		timeouts: o.methodTimeouts,
+	}
+}
+
+// Conn returns connection of client, e.g. to call other services of the server
+func (c *Client) Conn() *grpc.ClientConn {
+	return c.conn
+}
+
+// Close closes connection opened by Dial
+func (c *Client) Close() error {
+	if !c.owned {
+		return nil
+	}
+	return c.conn.Close()
+}
+
+// Create creates todo, its ID is returned
+func (c *Client) Create(ctx context.Context, t Todo) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx, MethodCreate)
+	defer cancel()
+
+	res, err := c.todo.Create(ctx, &v1.CreateRequest{
+		Api: apiVersion,
+		Todo: &v1.Todo{
+			Title:       t.Title,
+			Description: t.Description,
+			Reminder:    timestampProto(t.Reminder),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return res.Id, nil
+}
+
+// Get returns todo with id, error satisfying IsNotFound is returned if it does not exist
+func (c *Client) Get(ctx context.Context, id int64) (Todo, error) {
+	ctx, cancel := c.withTimeout(ctx, MethodRead)
+	defer cancel()
+
+	res, err := c.todo.Read(ctx, &v1.ReadRequest{Api: apiVersion, Id: id})
+	if err != nil {
+		return Todo{}, err
+	}
+
+	return fromProto(res.Todo), nil
+}
+
+// List returns all todos
+func (c *Client) List(ctx context.Context) ([]Todo, error) {
+	ctx, cancel := c.withTimeout(ctx, MethodReadAll)
+	defer cancel()
+
+	res, err := c.todo.ReadAll(ctx, &v1.ReadAllRequest{Api: apiVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	todos := make([]Todo, 0, len(res.Todos))
+	for _, t := range res.Todos {
+		todos = append(todos, fromProto(t))
+	}
+	return todos, nil
+}
+
+// Update replaces title, description and reminder of todo with t.ID
+func (c *Client) Update(ctx context.Context, t Todo) error {
+	ctx, cancel := c.withTimeout(ctx, MethodUpdate)
+	defer cancel()
+
+	_, err := c.todo.Update(ctx, &v1.UpdateRequest{
+		Api: apiVersion,
+		Todo: &v1.Todo{
+			Id:          t.ID,
+			Title:       t.Title,
+			Description: t.Description,
+			Reminder:    timestampProto(t.Reminder),
+		},
+	})
+	return err
+}
+
+// Delete deletes todo with id, error satisfying IsNotFound is returned if it does not exist
+func (c *Client) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := c.withTimeout(ctx, MethodDelete)
+	defer cancel()
+
+	_, err := c.todo.Delete(ctx, &v1.DeleteRequest{Api: apiVersion, Id: id})
+	return err
+}
+
+// Version returns build information of server
+func (c *Client) Version(ctx context.Context) (Version, error) {
+	ctx, cancel := c.withTimeout(ctx, MethodGetVersion)
+	defer cancel()
+
+	res, err := c.todo.GetVersion(ctx, &v1.GetVersionRequest{Api: apiVersion})
+	if err != nil {
+		return Version{}, err
+	}
+
+	return Version{
+		Version:   res.Version,
+		GitCommit: res.GitCommit,
+		BuildDate: res.BuildDate,
+		GoVersion: res.GoVersion,
+	}, nil
+}
+
+// IsNotFound reports whether err is returned for todo that does not exist
+func IsNotFound(err error) bool {
+	return status.Code(err) == codes.NotFound
+}
+
+// withTimeout applies timeout of method to ctx without deadline
+func (c *Client) withTimeout(ctx context.Context, method string) (context.Context, context.CancelFunc) {
+	timeout, ok := c.timeouts[method]
+	if !ok {
+		timeout = c.timeout
+	}
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// metadataInterceptor returns interceptor sending key/value pairs kv as metadata of every call
+func metadataInterceptor(kv []string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, kv...)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// timestampProto converts t to protobuf timestamp, zero time is sent as missing timestamp
+func timestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// fromProto converts todo message to Todo
+func fromProto(t *v1.Todo) Todo {
+	todo := Todo{
+		ID:          t.GetId(),
+		Title:       t.GetTitle(),
+		Description: t.GetDescription(),
+	}
+	if t.GetReminder() != nil {
+		todo.Reminder = t.Reminder.AsTime()
+	}
+	return todo
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyHeader is metadata API key is sent in
+const APIKeyHeader = "x-api-key"
+
+// tokenCredentials sends bearer token, e.g. JWT, in authorization metadata of every call
+type tokenCredentials struct {
+	src oauth2.TokenSource
+}
+
+// NewTokenCredentials returns credentials sending token of src in authorization metadata of every call.
+// Token is reused until it is about to expire, then new token is requested from src, e.g. to refresh JWT.
+// Credentials are only sent over TLS.
+func NewTokenCredentials(src oauth2.TokenSource) credentials.PerRPCCredentials {
+	return &tokenCredentials{src: oauth2.ReuseTokenSource(nil, src)}
+}
+
+// GetRequestMetadata returns authorization metadata with valid token
+func (c *tokenCredentials) GetRequestMetadata(ctx context.Context, _ ...string) (map[string]string, error) {
+	t, err := c.src.Token()
+	if err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "failed to get token: %v", err)
+	}
+	return map[string]string{"authorization": t.Type() + " " + t.AccessToken}, nil
+}
+
+// RequireTransportSecurity returns true, so token is not sent in plain text
+func (c *tokenCredentials) RequireTransportSecurity() bool {
+	return true
+}
+
+// apiKeyCredentials sends API key in APIKeyHeader metadata of every call
+type apiKeyCredentials struct {
+	key string
+}
+
+// NewAPIKeyCredentials returns credentials sending API key created by Admin Service in APIKeyHeader
+// metadata of every call. Credentials are only sent over TLS.
+func NewAPIKeyCredentials(key string) credentials.PerRPCCredentials {
+	return apiKeyCredentials{key: key}
+}
+
+// GetRequestMetadata returns API key metadata
+func (c apiKeyCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{APIKeyHeader: c.key}, nil
+}
+
+// RequireTransportSecurity returns true, so API key is not sent in plain text
+func (c apiKeyCredentials) RequireTransportSecurity() bool {
+	return true
+}
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"errors"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+)
+
+// MetricsInterceptor returns interceptor exporting per-method call counts, status codes and latency histogram
+// of calls as seen by caller to reg, e.g. prometheus.DefaultRegisterer. Metrics are named grpc_client_*,
+// interceptors of clients registered to same reg share them.
+func MetricsInterceptor(reg prometheus.Registerer) (grpc.UnaryClientInterceptor, error) {
+	m := grpc_prometheus.NewClientMetrics()
+	m.EnableClientHandlingTimeHistogram()
+
+	if err := reg.Register(m); err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if !errors.As(err, &are) {
+			return nil, err
+		}
+		existing, ok := are.ExistingCollector.(*grpc_prometheus.ClientMetrics)
+		if !ok {
+			return nil, err
+		}
+		m = existing
+	}
+
+	return m.UnaryClientInterceptor(), nil
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// DefaultTimeout is timeout of calls made with context without deadline
+const DefaultTimeout = 10 * time.Second
+
+// DefaultBalancer is load balancing policy spreading calls over all replicas server address resolves to
+const DefaultBalancer = "round_robin"
+
+// options are settings of Client collected from Option values
+type options struct {
+	tlsConfig *tls.Config
+	timeout   time.Duration
+	// methodTimeouts are timeouts of calls keyed by full method name, they override timeout
+	methodTimeouts map[string]time.Duration
+	retry          *RetryPolicy
+	breaker        *BreakerPolicy
+	metrics        prometheus.Registerer
+	balancer       string
+	keepalive      *keepalive.ClientParameters
+	hmacSecret     string
+	metadata       []string
+	interceptors   []grpc.UnaryClientInterceptor
+	dialOptions    []grpc.DialOption
+}
+
+// Option configures Client
+type Option func(*options)
+
+// WithTLS connects over TLS configured by cfg, e.g. loaded by TLSConfig.Load, connection is plain text if it is not set
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.tlsConfig = cfg
+	}
+}
+
+// WithTimeout sets timeout of calls made with context without deadline, they are not limited if it is 0.
+// DefaultTimeout is used if it is not set.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithMethodTimeout sets timeout of calls of method made with context without deadline, it overrides
+// timeout set by WithTimeout. Method is full gRPC method name, e.g. MethodReadAll.
+func WithMethodTimeout(method string, timeout time.Duration) Option {
+	return func(o *options) {
+		if o.methodTimeouts == nil {
+			o.methodTimeouts = map[string]time.Duration{}
+		}
+		o.methodTimeouts[method] = timeout
+	}
+}
+
+// WithRetryPolicy sets policy of retrying calls of idempotent methods, DefaultRetryPolicy is used if it is not set
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(o *options) {
+		o.retry = &p
+	}
+}
+
+// WithoutRetries turns off retrying of calls
+func WithoutRetries() Option {
+	return func(o *options) {
+		o.retry = nil
+	}
+}
+
+// WithKeepalive pings server after connection is idle for interval, connection is closed if ping is not
+// acknowledged within timeout. Pings are sent when there are no active calls too, server must allow them
+// with -grpc-keepalive-permit-without-stream and -grpc-keepalive-min-time not above interval.
+func WithKeepalive(interval, timeout time.Duration) Option {
+	return func(o *options) {
+		o.keepalive = &keepalive.ClientParameters{Time: interval, Timeout: timeout, PermitWithoutStream: true}
+	}
+}
+
+// WithCircuitBreaker fails calls fast with ErrCircuitOpen while server fails most of them as configured by p,
+// e.g. DefaultBreakerPolicy, so callers do not pile up on dead server. Calls are not limited if it is not set.
+func WithCircuitBreaker(p BreakerPolicy) Option {
+	return func(o *options) {
+		o.breaker = &p
+	}
+}
+
+// WithMetrics exports metrics of calls made by client to reg as MetricsInterceptor does
+func WithMetrics(reg prometheus.Registerer) Option {
+	return func(o *options) {
+		o.metrics = reg
+	}
+}
+
+// WithBalancer sets load balancing policy of connections to server replicas: round_robin or pick_first.
+// Replicas reporting they are not serving by gRPC health service are skipped by round_robin.
+// DefaultBalancer is used if it is not set.
+func WithBalancer(policy string) Option {
+	return func(o *options) {
+		o.balancer = policy
+	}
+}
+
+// WithBearerToken sends token of src, e.g. JWT, in authorization metadata of every call, token is requested
+// from src again when it is about to expire. It requires TLS, see NewTokenCredentials.
+func WithBearerToken(src oauth2.TokenSource) Option {
+	return WithDialOptions(grpc.WithPerRPCCredentials(NewTokenCredentials(src)))
+}
+
+// WithAPIKey sends API key in APIKeyHeader metadata of every call. It requires TLS, see NewAPIKeyCredentials.
+func WithAPIKey(key string) Option {
+	return WithDialOptions(grpc.WithPerRPCCredentials(NewAPIKeyCredentials(key)))
+}
+
+// WithHMACSecret signs every call with shared secret of server started with -auth-hmac-secret
+func WithHMACSecret(secret string) Option {
+	return func(o *options) {
+		o.hmacSecret = secret
+	}
+}
+
+// WithMetadata sends key/value pairs as metadata of every call, e.g. "x-tenant-id", "acme"
+func WithMetadata(kv ...string) Option {
+	return func(o *options) {
+		o.metadata = append(o.metadata, kv...)
+	}
+}
+
+// WithUnaryInterceptor adds interceptor of calls, interceptors are run in order they are added
+func WithUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, interceptor)
+	}
+}
+
+// WithDialOptions adds gRPC dial options, they are applied after options set by Client
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
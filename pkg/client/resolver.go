@@ -0,0 +1,40 @@
+package client
+
+import (
+	"strings"
+
+	"google.golang.org/grpc/resolver"
+)
+
+// StaticScheme is scheme of targets listing addresses of server replicas, e.g. static:///10.0.0.1:9090,10.0.0.2:9090
+const StaticScheme = "static"
+
+// staticBuilder builds resolvers of static targets, it is registered for connections of Dial only
+type staticBuilder struct{}
+
+// Build resolves target to its comma separated list of addresses
+func (staticBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	var addrs []resolver.Address
+	for _, a := range strings.Split(target.Endpoint(), ",") {
+		if a = strings.TrimSpace(a); len(a) > 0 {
+			addrs = append(addrs, resolver.Address{Addr: a})
+		}
+	}
+
+	if err := cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		return nil, err
+	}
+	return staticResolver{}, nil
+}
+
+// Scheme returns StaticScheme
+func (staticBuilder) Scheme() string {
+	return StaticScheme
+}
+
+// staticResolver keeps addresses it is built with
+type staticResolver struct{}
+
+func (staticResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (staticResolver) Close() {}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryBurst is maximum number of retries allowed by budget in a row
+const retryBurst = 10
+
+// tokenEpsilon is rounding error of tokens added by successful calls, e.g. ten times 0.1 is less than 1
+const tokenEpsilon = 1e-9
+
+// RetryPolicy configures retrying of calls of idempotent methods
+type RetryPolicy struct {
+	// MaxAttempts is maximum number of attempts including the first one, calls are not retried if it is 1 or less
+	MaxAttempts int
+	// InitialBackoff is wait before first retry, it grows by Multiplier up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	// Jitter is fraction (0..1) of backoff randomized, so clients failed at once do not retry at once
+	Jitter float64
+	// PerAttemptTimeout limits each attempt, so attempt hanging on dead server is retried, it is not limited if 0
+	PerAttemptTimeout time.Duration
+	// Budget is ratio of retries to successful calls allowed after burst of retries is spent,
+	// e.g. 0.1 allows one retry per 10 successful calls during outage. Retries are not limited if 0.
+	Budget float64
+	// Codes are status codes calls are retried on
+	Codes []codes.Code
+}
+
+// DefaultRetryPolicy is retry policy of clients created by Dial
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+	Budget:         0.1,
+	Codes:          []codes.Code{codes.Unavailable, codes.DeadlineExceeded},
+}
+
+// retryBudget is token bucket of retries, it is filled by successful calls
+type retryBudget struct {
+	mu     sync.Mutex
+	ratio  float64
+	tokens float64
+}
+
+// success refills budget after successful call
+func (b *retryBudget) success() {
+	b.mu.Lock()
+	b.tokens = math.Min(b.tokens+b.ratio, retryBurst)
+	b.mu.Unlock()
+}
+
+// take reports whether retry is allowed and spends token for it
+func (b *retryBudget) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1-tokenEpsilon {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// backoff returns wait before retry number n (1 for first retry)
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(n-1))
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	d -= d * p.Jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+// retryable reports whether call failed with err is retried by policy
+func (p RetryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	for _, c := range p.Codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryInterceptor returns interceptor retrying calls of idempotent methods of Todo Service
+// with exponential backoff while ctx of the call is not done
+func RetryInterceptor(p RetryPolicy) grpc.UnaryClientInterceptor {
+	idempotent := map[string]bool{}
+	for _, m := range v1.IdempotentMethods() {
+		idempotent[m] = true
+	}
+	var budget *retryBudget
+	if p.Budget > 0 {
+		budget = &retryBudget{ratio: p.Budget, tokens: retryBurst}
+	}
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !idempotent[method] || p.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := invokeAttempt(ctx, p.PerAttemptTimeout, method, req, reply, cc, invoker, opts)
+			if err == nil {
+				if budget != nil {
+					budget.success()
+				}
+				return nil
+			}
+			if attempt >= p.MaxAttempts || !p.retryable(err) || ctx.Err() != nil {
+				return err
+			}
+			if budget != nil && !budget.take() {
+				return err
+			}
+
+			// retry is not started if call runs out of time while waiting for it
+			wait := p.backoff(attempt)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return err
+			}
+			t := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				t.Stop()
+				return err
+			case <-t.C:
+			}
+		}
+	}
+}
+
+// invokeAttempt makes single attempt of call limited by timeout if it is not 0
+func invokeAttempt(ctx context.Context, timeout time.Duration, method string, req, reply interface{}, cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker, opts []grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
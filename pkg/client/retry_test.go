@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	tests := []struct {
+		retry int
+		want  time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	}
+	for _, tt := range tests {
+		if got := p.backoff(tt.retry); got != tt.want {
+			t.Errorf("backoff of retry %d is %v, %v expected", tt.retry, got, tt.want)
+		}
+	}
+}
+
+func TestRetryPolicyBackoffJitter(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, Multiplier: 2, Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		if got := p.backoff(1); got < 80*time.Millisecond || got > 100*time.Millisecond {
+			t.Fatalf("backoff with jitter is %v, 80ms to 100ms expected", got)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := &retryBudget{ratio: 0.1, tokens: retryBurst}
+	for i := 0; i < retryBurst; i++ {
+		if !b.take() {
+			t.Fatalf("retry %d of burst is not allowed", i+1)
+		}
+	}
+	if b.take() {
+		t.Fatalf("retry is allowed after burst is spent")
+	}
+
+	// ten successful calls allow one retry
+	for i := 0; i < 9; i++ {
+		b.success()
+	}
+	if b.take() {
+		t.Fatalf("retry is allowed after 9 successful calls")
+	}
+	b.success()
+	if !b.take() {
+		t.Fatalf("retry is not allowed after 10 successful calls")
+	}
+
+	// budget is not filled above burst
+	for i := 0; i < 1000; i++ {
+		b.success()
+	}
+	if b.tokens > retryBurst {
+		t.Fatalf("budget has %v tokens, at most %d expected", b.tokens, retryBurst)
+	}
+}
+
+// failingInvoker returns invoker failing first failures calls with code, it counts calls in calls
+func failingInvoker(failures int, code codes.Code, calls *int) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+		if *calls <= failures {
+			return status.Error(code, "failed")
+		}
+		return nil
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		Multiplier:     1,
+		Codes:          []codes.Code{codes.Unavailable},
+	}
+
+	tests := []struct {
+		name      string
+		policy    RetryPolicy
+		method    string
+		failures  int
+		code      codes.Code
+		wantCode  codes.Code
+		wantCalls int
+	}{
+		{"success", p, "/TodoService/Read", 0, codes.Unavailable, codes.OK, 1},
+		{"retried until success", p, "/TodoService/Read", 2, codes.Unavailable, codes.OK, 3},
+		{"attempts run out", p, "/TodoService/Read", 5, codes.Unavailable, codes.Unavailable, 3},
+		{"code is not retried", p, "/TodoService/Read", 1, codes.InvalidArgument, codes.InvalidArgument, 1},
+		{"method is not idempotent", p, "/TodoService/Create", 1, codes.Unavailable, codes.Unavailable, 1},
+		{"retries are off", RetryPolicy{MaxAttempts: 1, Codes: p.Codes}, "/TodoService/Read", 1, codes.Unavailable, codes.Unavailable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			err := RetryInterceptor(tt.policy)(context.Background(), tt.method, nil, nil, nil, failingInvoker(tt.failures, tt.code, &calls))
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("call returned %v, %v expected", err, tt.wantCode)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("call was attempted %d times, %d expected", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryInterceptorBudget(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		Multiplier:     1,
+		Budget:         0.1,
+		Codes:          []codes.Code{codes.Unavailable},
+	}
+	retry := RetryInterceptor(p)
+
+	// every call fails, burst of retries is spent and further calls are not retried
+	var calls int
+	invoker := failingInvoker(1000, codes.Unavailable, &calls)
+	for i := 0; i < retryBurst+5; i++ {
+		_ = retry(context.Background(), "/TodoService/Read", nil, nil, nil, invoker)
+	}
+	if want := 2*retryBurst + 5; calls != want {
+		t.Fatalf("calls were attempted %d times, %d expected", calls, want)
+	}
+}
+
+func TestRetryInterceptorDeadline(t *testing.T) {
+	p := RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		Multiplier:     1,
+		Codes:          []codes.Code{codes.Unavailable},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// retry is not started if call runs out of time while waiting for it
+	var calls int
+	start := time.Now()
+	err := RetryInterceptor(p)(ctx, "/TodoService/Read", nil, nil, nil, failingInvoker(5, codes.Unavailable, &calls))
+	if status.Code(err) != codes.Unavailable || calls != 1 {
+		t.Fatalf("call returned %v after %d attempts, Unavailable after 1 attempt expected", err, calls)
+	}
+	if d := time.Since(start); d > 50*time.Millisecond {
+		t.Fatalf("call returned after %v, backoff longer than deadline must not be waited for", d)
+	}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSConfig is TLS settings of connection to server read from PEM files, it is passed to WithTLS by Load
+type TLSConfig struct {
+	// CAFile is CA certificates server certificate is verified with, system roots are used if it is empty
+	CAFile string
+	// CertFile is client certificate presented to server requiring mutual TLS, KeyFile is its private key
+	CertFile string
+	KeyFile  string
+	// ServerName is name server certificate is verified for, host of server address if it is empty
+	ServerName string
+	// InsecureSkipVerify turns off verification of server certificate, it is meant for development servers
+	// with self-signed certificates only
+	InsecureSkipVerify bool
+}
+
+// Load reads certificates of c and returns TLS configuration of connection
+func (c TLSConfig) Load() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if len(c.CAFile) > 0 {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %v", err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates found in '%s'", c.CAFile)
+		}
+	}
+
+	switch {
+	case len(c.CertFile) > 0 && len(c.KeyFile) > 0:
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case len(c.CertFile) > 0 || len(c.KeyFile) > 0:
+		return nil, fmt.Errorf("client certificate and key are both required for mutual TLS")
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/config"
+	"github.com/maslow123/go-grpc/pkg/server"
+)
+
+// runCreateAPIKey creates API key directly in database and prints it, so the first key with "admin" scope
+// can be created before Admin Service is reachable
+func runCreateAPIKey(args []string) error {
+	cfg := server.DefaultConfig()
+	fs := newFlagSet("create-api-key")
+	name := fs.String("name", "", "Human readable name of the API key owner")
+	scopes := fs.String("scopes", "admin", "Comma separated scopes granted to the API key, e.g. admin,todo:write")
+	ttl := fs.Duration("ttl", 0, "How long the API key is valid, it never expires if 0")
+	databaseFlags(fs, &cfg)
+
+	// command line overrides environment
+	if err := config.Load(fs, args, config.Options{}); err != nil {
+		return err
+	}
+
+	req := &v1.CreateApiKeyRequest{Api: "v1", Name: *name}
+	if len(*scopes) > 0 {
+		req.Scopes = strings.Split(*scopes, ",")
+	}
+	if *ttl > 0 {
+		expiresAt, err := ptypes.TimestampProto(time.Now().Add(*ttl))
+		if err != nil {
+			return fmt.Errorf("Failed to convert expiry: %v", err)
+		}
+		req.ExpiresAt = expiresAt
+	}
+
+	db, err := sql.Open("mysql", cfg.DatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// key is created by the service, so it is validated like keys created by callers
+	res, err := v1.NewAdminServiceServer(db).CreateApiKey(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("Failed to create API key: %v", err)
+	}
+	fmt.Println(res.Key)
+
+	return nil
+}
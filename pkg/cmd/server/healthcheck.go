@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/maslow123/go-grpc/pkg/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// runHealthcheck checks health of gRPC server, e.g. by HEALTHCHECK of container, error is returned if it is not serving
+func runHealthcheck(args []string) error {
+	fs := newFlagSet("healthcheck")
+	address := fs.String("addr", "localhost:9090", "gRPC server to check in format host:port or unix:///path")
+	service := fs.String("service", "", "Service to check, whole server is checked if empty")
+	timeout := fs.Duration("timeout", 5*time.Second, "How long connecting and checking may take")
+	useTLS := fs.Bool("tls", false, "Connect over TLS")
+	var tlsConfig client.TLSConfig
+	fs.StringVar(&tlsConfig.CAFile, "tls-ca-file", "", "PEM encoded CA certificates server certificate is verified with, system roots if empty")
+	fs.StringVar(&tlsConfig.CertFile, "tls-cert-file", "", "PEM encoded client certificate presented to server requiring mutual TLS")
+	fs.StringVar(&tlsConfig.KeyFile, "tls-key-file", "", "PEM encoded private key of -tls-cert-file")
+	fs.StringVar(&tlsConfig.ServerName, "tls-server-name", "", "Server name certificate is verified for, host of -addr if empty")
+	fs.BoolVar(&tlsConfig.InsecureSkipVerify, "tls-skip-verify", false, "Do not verify server certificate, e.g. self-signed certificate of localhost")
+
+	if err := config.Load(fs, args, config.Options{}); err != nil {
+		return err
+	}
+
+	creds := grpc.WithInsecure()
+	if *useTLS {
+		cfg, err := tlsConfig.Load()
+		if err != nil {
+			return fmt.Errorf("Failed to configure TLS: %v", err)
+		}
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(cfg))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, *address, creds, grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("Failed to connect to '%s': %v", *address, err)
+	}
+	defer conn.Close()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: *service})
+	if err != nil {
+		return fmt.Errorf("Failed to check health: %v", err)
+	}
+	if res.Status != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Errorf("server is %s", res.Status)
+	}
+
+	fmt.Println(res.Status)
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	// mysql driver
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/maslow123/go-grpc/pkg/config"
+	"github.com/maslow123/go-grpc/pkg/migrations"
+	"github.com/maslow123/go-grpc/pkg/server"
+)
+
+// runMigrate applies pending database migrations
+func runMigrate(args []string) error {
+	cfg := server.DefaultConfig()
+	fs := newFlagSet("migrate")
+	dryRun := fs.Bool("dry-run", false, "List pending migrations without applying them")
+	timeout := fs.Duration("timeout", 0, "How long applying of all migrations may take, not limited if 0")
+	databaseFlags(fs, &cfg)
+
+	// command line overrides environment
+	if err := config.Load(fs, args, config.Options{}); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("mysql", cfg.DatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	if *dryRun {
+		pending, err := migrations.Pending(ctx, db)
+		if err != nil {
+			return fmt.Errorf("Failed to read migrations: %v", err)
+		}
+		for _, m := range pending {
+			fmt.Printf("pending %s.%s\n", m.Version, m.Name)
+		}
+		return nil
+	}
+
+	applied, err := migrations.Up(ctx, db)
+	for _, m := range applied {
+		fmt.Printf("applied %s.%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to migrate database: %v", err)
+	}
+	if len(applied) == 0 {
+		fmt.Println("database is up to date")
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/config"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/server"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// reloadMu serializes reloads triggered by SIGHUP and by remote config store
+var reloadMu sync.Mutex
+
+// reloadableFlags are flags that are applied to running server by reload, others take effect on restart
+var reloadableFlags = map[string]bool{
+	"log-level": true,
+	"read-only": true,
+}
+
+// loadReloadable loads configuration from all sources again like on start and returns values of reloadable flags,
+// remote config store is not read if remote is not nil, its values are used instead
+func loadReloadable(args []string, remote map[string]string) (map[string]string, error) {
+	cfg := Config{Config: server.DefaultConfig()}
+	fs, _, _ := newServeFlagSet(&cfg)
+	fs.SetOutput(io.Discard)
+	o := serveLoadOptions
+	o.RemoteValues = remote
+	if err := config.Load(fs, args, o); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for name := range reloadableFlags {
+		values[name] = fs.Lookup(name).Value.String()
+	}
+
+	return values, nil
+}
+
+// applyValues sets flags of fs to values, names of changed flags are returned
+func applyValues(fs *flag.FlagSet, values map[string]string) ([]string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var changed []string
+	for _, name := range names {
+		f := fs.Lookup(name)
+		old := f.Value.String()
+		if err := fs.Set(name, values[name]); err != nil {
+			return changed, fmt.Errorf("invalid value of flag '%s': %v", name, err)
+		}
+		if f.Value.String() != old {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed, nil
+}
+
+// reload loads configuration again, applies reloadable settings to flags of fs and reloads TLS certificate,
+// settings that changed are logged
+func (cfg *Config) reload(fs *flag.FlagSet, args []string, log *zap.Logger, srv *server.Server) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	// running configuration is kept if new one cannot be loaded
+	values, err := loadReloadable(args, nil)
+	if err != nil {
+		log.Error("Failed to reload configuration", zap.String("reason", err.Error()))
+		return
+	}
+	changed, err := applyValues(fs, values)
+	if err != nil {
+		log.Error("Failed to reload configuration", zap.String("reason", err.Error()))
+		return
+	}
+	cfg.applyReloadable(srv)
+
+	if len(cfg.TLSCertFile) > 0 {
+		if err := srv.ReloadTLS(); err != nil {
+			log.Error("Failed to reload TLS certificate", zap.String("reason", err.Error()))
+		} else {
+			changed = append(changed, "tls-certificate")
+		}
+	}
+
+	// summary is logged as warning, so it is not dropped when level is raised
+	log.Warn("Configuration reloaded", zap.Strings("changed", changed))
+}
+
+// applyReloadable applies current values of reloadable settings to running server
+func (cfg *Config) applyReloadable(srv *server.Server) {
+	logger.Level.SetLevel(zapcore.Level(cfg.LogLevel))
+	srv.SetReadOnly(cfg.ReadOnly)
+}
+
+// watchRemote applies changes of reloadable settings in remote config store to flags of fs until ctx is done,
+// changes of other settings take effect on restart
+func (cfg *Config) watchRemote(ctx context.Context, fs *flag.FlagSet, args []string, log *zap.Logger, remote config.Remote, srv *server.Server) {
+	// store may have changed since it was read by config.Load
+	if values, err := remote.Get(ctx); err != nil {
+		log.Warn("Failed to read remote config store", zap.String("reason", err.Error()))
+	} else {
+		cfg.applyRemote(fs, args, log, srv, values)
+	}
+
+	for {
+		values, err := remote.Watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Warn("Failed to watch remote config store", zap.String("reason", err.Error()))
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(10 * time.Second):
+			}
+			continue
+		}
+
+		cfg.applyRemote(fs, args, log, srv, values)
+	}
+}
+
+// applyRemote loads configuration again with values of remote config store and applies reloadable settings,
+// so settings set by flags or environment keep precedence and settings removed from store fall back
+// to config file or defaults
+func (cfg *Config) applyRemote(fs *flag.FlagSet, args []string, log *zap.Logger, srv *server.Server, values map[string]string) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	layered, err := loadReloadable(args, values)
+	if err != nil {
+		log.Error("Failed to reload configuration", zap.String("source", "remote"), zap.String("reason", err.Error()))
+		return
+	}
+	changed, err := applyValues(fs, layered)
+	if err != nil {
+		log.Error("Failed to reload configuration", zap.String("source", "remote"), zap.String("reason", err.Error()))
+	}
+	cfg.applyReloadable(srv)
+
+	if len(changed) > 0 {
+		// summary is logged as warning, so it is not dropped when level is raised
+		log.Warn("Configuration reloaded", zap.String("source", "remote"), zap.Strings("changed", changed))
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns todo-server command with subcommands for running the server and operational tasks
+func NewCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:           "todo-server",
+		Short:         "Todo Service gRPC server and HTTP/REST gateway",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	root.AddCommand(
+		flagCommand("serve", "Run gRPC server and HTTP/REST gateway", runServe),
+		flagCommand("migrate", "Apply pending database migrations", runMigrate),
+		flagCommand("healthcheck", "Check gRPC server is serving, exit status is 1 if it is not", runHealthcheck),
+		flagCommand("seed", "Insert sample todos, meant for development", runSeed),
+		flagCommand("create-api-key", "Create API key and print it, e.g. the first key with admin scope", runCreateAPIKey),
+	)
+
+	return root
+}
+
+// flagCommand returns subcommand parsing its args by flag package, so flags keep their -name=value form
+// and are read from environment like before subcommands were added
+func flagCommand(name, short string, run func(args []string) error) *cobra.Command {
+	return &cobra.Command{
+		Use:                name + " [flags]",
+		Short:              short,
+		DisableFlagParsing: true,
+		RunE: func(_ *cobra.Command, args []string) error {
+			if err := run(args); err != flag.ErrHelp {
+				return err
+			}
+			return nil
+		},
+	}
+}
+
+// newFlagSet returns flag set of subcommand name, parse errors are returned instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: todo-server %s [flags]\n\nFlags:\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/config"
+	"github.com/maslow123/go-grpc/pkg/server"
+)
+
+// runSeed inserts sample todos with reminders spread over following days
+func runSeed(args []string) error {
+	cfg := server.DefaultConfig()
+	fs := newFlagSet("seed")
+	count := fs.Int("count", 10, "Number of todos to insert")
+	databaseFlags(fs, &cfg)
+
+	// command line overrides environment
+	if err := config.Load(fs, args, config.Options{}); err != nil {
+		return err
+	}
+
+	db, err := sql.Open("mysql", cfg.DatabaseDSN())
+	if err != nil {
+		return fmt.Errorf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	// todos are created by the service, so they are validated like todos created by clients
+	api := v1.NewTodoServiceServer(db)
+	now := time.Now().In(time.UTC).Truncate(time.Hour)
+	for i := 1; i <= *count; i++ {
+		reminder, _ := ptypes.TimestampProto(now.Add(time.Duration(i) * 6 * time.Hour))
+		res, err := api.Create(context.Background(), &v1.CreateRequest{
+			Api: "v1",
+			Todo: &v1.Todo{
+				Title:       fmt.Sprintf("Sample todo %d", i),
+				Description: fmt.Sprintf("Description of sample todo %d", i),
+				Reminder:    reminder,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to create todo: %v", err)
+		}
+		fmt.Printf("created todo %d\n", res.Id)
+	}
+
+	return nil
+}
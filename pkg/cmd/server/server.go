@@ -2,98 +2,284 @@ package cmd
 
 import (
 	"context"
-	"database/sql"
 	"flag"
 	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 
-	// mysql driver
-	_ "github.com/go-sql-driver/mysql"
-
-	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/config"
 	"github.com/maslow123/go-grpc/pkg/logger"
-	"github.com/maslow123/go-grpc/pkg/protocol/grpc"
-	"github.com/maslow123/go-grpc/pkg/protocol/rest"
+	"github.com/maslow123/go-grpc/pkg/server"
+	"github.com/maslow123/go-grpc/pkg/version"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
 )
 
 // Config is configuration for Server
 type Config struct {
-	// gRPC server start parameters section
-	// gRPC is TCP port to listen by gRPC server
-	GRPCPort string
-
-	// HTTP/REST gateway start parameters section
-	// HTTPPort is TCP port to listen by HTTP/REST gateway
-	HTTPPort string
-
-	// DB DataStore parameters section
-	// DatastoreDBHost is host of database
-	DatastoreDBHost string
-	// DatastoreDBUser string
-	DatastoreDBUser string
-	// DatastoreDBPassword string
-	DatastoreDBPassword string
-	// DatastoreDBSchema string
-	DatastoreDBSchema string
-
-	// Log parameters section
-	// LogLevel is global log level: Debug(-1), Info(0), Warn(1), Error(2), DPanic(3), Panic(4), Fatal(5)
-	LogLevel      int
-	LogTimeFormat string
+	server.Config
+
+	// ConfigFile is YAML file settings not set by flags or environment are read from
+	ConfigFile string
+	// ConfigRemote is URL of Consul or etcd prefix settings are read from, reloadable settings are watched
+	ConfigRemote string
 }
 
-// RunServer runs gRPC server and HTTP gateway
-func RunServer() error {
-	ctx := context.Background()
-
-	// get configuration
-	var cfg Config
-	flag.StringVar(&cfg.GRPCPort, "grpc-port", "", "gRPC port to bind")
-	flag.StringVar(&cfg.HTTPPort, "http-port", "", "HTTP port to bind")
-	flag.StringVar(&cfg.DatastoreDBHost, "db-host", "", "Database Host")
-	flag.StringVar(&cfg.DatastoreDBUser, "db-user", "", "Database User")
-	flag.StringVar(&cfg.DatastoreDBPassword, "db-password", "", "Database Password")
-	flag.StringVar(&cfg.DatastoreDBSchema, "db-schema", "", "Database Schema")
-	flag.IntVar(&cfg.LogLevel, "log-level", 0, "Global log level")
-	flag.StringVar(&cfg.LogTimeFormat, "log-time-format", "", "Print time format for logger e.g. 2006-01-02T15:04:05Z07:00")
-
-	flag.Parse()
-
-	if len(cfg.GRPCPort) == 0 {
-		return fmt.Errorf("invalid TCP port for gRPC server: '%s'", cfg.GRPCPort)
+// serveLoadOptions locates configuration sources of serve command, flags printing information and locating
+// sources are only read from command line, so generic environment variables like VERSION are not mistaken for them
+var serveLoadOptions = config.Options{
+	FileFlag:        "config",
+	RemoteFlag:      "config-remote",
+	CommandLineOnly: []string{"version", "print-config", "config", "config-remote"},
+}
+
+// runServe runs gRPC server and HTTP gateway
+func runServe(args []string) error {
+	// get configuration, flags default to settings of embedded server
+	cfg := Config{Config: server.DefaultConfig()}
+	fs, printVersion, printConfig := newServeFlagSet(&cfg)
+
+	// command line overrides environment, environment overrides remote store and config file
+	if err := config.Load(fs, args, serveLoadOptions); err != nil {
+		return err
 	}
 
-	if len(cfg.HTTPPort) == 0 {
-		return fmt.Errorf("invalid TCP port for HTTP gateway: '%s'", cfg.HTTPPort)
+	if *printVersion {
+		fmt.Println(version.String())
+		return nil
+	}
+
+	if *printConfig {
+		return config.WriteYAML(os.Stdout, fs, "config", "config-remote", "print-config", "version")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
 	}
 
 	// Initialize logger
-	if err := logger.Init(cfg.LogLevel, cfg.LogTimeFormat); err != nil {
+	if err := logger.Init(logger.Config{
+		Level:              cfg.LogLevel,
+		TimeFormat:         cfg.LogTimeFormat,
+		Format:             cfg.LogFormat,
+		SamplingInitial:    cfg.LogSamplingInitial,
+		SamplingThereafter: cfg.LogSamplingThereafter,
+		File:               cfg.LogFile,
+		ErrorFile:          cfg.LogErrorFile,
+		FileMaxSize:        cfg.LogFileMaxSize,
+		FileMaxAge:         cfg.LogFileMaxAge,
+		FileMaxBackups:     cfg.LogFileMaxBackups,
+		FileCompress:       cfg.LogFileCompress,
+	}); err != nil {
 		return fmt.Errorf("Failed to initialize logger: %v", err)
 	}
+	log := logger.Log
 
-	// add MySQL driver
-	param := "parseTime=true"
-
-	dsn := fmt.Sprintf("%s:%s@tcp(%s)/%s?%s",
-		cfg.DatastoreDBUser,
-		cfg.DatastoreDBPassword,
-		cfg.DatastoreDBHost,
-		cfg.DatastoreDBSchema,
-		param,
+	log.Info("Build information",
+		zap.String("version", version.Version),
+		zap.String("git-commit", version.GitCommit),
+		zap.String("build-date", version.BuildDate),
 	)
+	log.Info("Effective configuration", zap.Any("config", config.Effective(fs)))
 
-	db, err := sql.Open("mysql", dsn)
-	if err != nil {
-		return fmt.Errorf("Failed to open database: %v", err)
+	srv := server.New(server.WithConfig(cfg.Config), server.WithLogger(log))
+
+	// server and background workers share ctx, it is canceled on signal or when any of them fails
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	g, ctx := errgroup.WithContext(ctx)
+
+	// reload configuration on SIGHUP
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	g.Go(func() error {
+		for {
+			select {
+			case <-hup:
+				cfg.reload(fs, args, log, srv)
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	})
+
+	// apply changes of remote config store
+	if len(cfg.ConfigRemote) > 0 {
+		remote, err := config.NewRemote(cfg.ConfigRemote)
+		if err != nil {
+			return fmt.Errorf("Failed to create remote config store: %v", err)
+		}
+		g.Go(func() error {
+			cfg.watchRemote(ctx, fs, args, log, remote, srv)
+			return nil
+		})
 	}
-	defer db.Close()
 
-	v1API := v1.NewTodoServiceServer(db)
+	// stop on ^C and on SIGTERM sent by process managers (e.g. Kubernetes)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(c)
+
+	g.Go(func() error {
+		select {
+		case sig := <-c:
+			log.Warn("Shutting down...", zap.String("signal", sig.String()))
+			cancel()
+		case <-ctx.Done():
+		}
+		return nil
+	})
 
-	// run HTTP gateway
-	go func() {
-		_ = rest.RunServer(ctx, cfg.GRPCPort, cfg.HTTPPort)
-	}()
+	g.Go(func() error {
+		return srv.Run(ctx)
+	})
+
+	// first failure is returned, nil if server is stopped by signal
+	return g.Wait()
+}
+
+// newServeFlagSet returns flag set of serve command setting cfg together with flags printing information and exiting
+func newServeFlagSet(cfg *Config) (fs *flag.FlagSet, printVersion, printConfig *bool) {
+	fs = newFlagSet("serve")
+	printVersion = fs.Bool("version", false, "Print build information and exit")
+	printConfig = fs.Bool("print-config", false, "Print effective configuration as YAML with secrets masked and exit")
+	fs.StringVar(&cfg.ConfigRemote, "config-remote", "",
+		"Consul or etcd prefix to read settings shared by replicas from, e.g. consul://localhost:8500/todo/config, reloadable settings are applied live")
+	fs.StringVar(&cfg.ConfigFile, "config", "", "YAML config file with sections named after flag prefixes, e.g. port of section grpc sets -grpc-port")
+	fs.StringVar(&cfg.GRPCPort, "grpc-port", cfg.GRPCPort, "gRPC port to bind")
+	fs.StringVar(&cfg.GRPCAddr, "grpc-addr", cfg.GRPCAddr, "Comma separated TCP addresses to bind instead of gRPC port on all interfaces, e.g. 127.0.0.1:9090,[::1]:9090")
+	fs.StringVar(&cfg.GRPCPlaintextAddr, "grpc-plaintext-addr", cfg.GRPCPlaintextAddr, "Comma separated TCP addresses gRPC server also listens on without TLS, e.g. 127.0.0.1:9091")
+	fs.StringVar(&cfg.GRPCListen, "grpc-listen", cfg.GRPCListen, "Unix domain socket to listen instead of gRPC port, e.g. unix:///var/run/todo.sock")
+	fs.BoolVar(&cfg.XDS, "xds", cfg.XDS, "Create gRPC server managed by xDS control plane of service mesh, bootstrapped from file set by GRPC_XDS_BOOTSTRAP")
+	fs.BoolVar(&cfg.GRPCReflection, "grpc-reflection", cfg.GRPCReflection, "Register gRPC reflection service for tools like grpcurl, meant for development")
+	fs.IntVar(&cfg.GRPCMaxConnections, "grpc-max-connections", cfg.GRPCMaxConnections, "Maximum number of gRPC connections served at the same time, not limited if 0")
+	fs.UintVar(&cfg.GRPCMaxConcurrentStreams, "grpc-max-concurrent-streams", cfg.GRPCMaxConcurrentStreams, "Maximum number of concurrent calls per gRPC connection, gRPC default if 0")
+	fs.IntVar(&cfg.GRPCMaxClientCalls, "grpc-max-client-calls", cfg.GRPCMaxClientCalls, "Maximum number of concurrent calls and streams per client, not limited if 0")
+	fs.IntVar(&cfg.GRPCMaxRecvMsgSize, "grpc-max-recv-msg-size", cfg.GRPCMaxRecvMsgSize, "Maximum size of message received by gRPC server in bytes, also used by HTTP/REST gateway. gRPC default (4MB) if 0")
+	fs.IntVar(&cfg.GRPCMaxSendMsgSize, "grpc-max-send-msg-size", cfg.GRPCMaxSendMsgSize, "Maximum size of message sent by gRPC server in bytes, also used by HTTP/REST gateway. gRPC default if 0")
+	fs.DurationVar(&cfg.GRPCDefaultTimeout, "grpc-default-timeout", cfg.GRPCDefaultTimeout, "Timeout of gRPC calls sent without deadline, not limited if 0")
+	fs.IntVar(&cfg.GRPCCompressionThreshold, "grpc-compression-threshold", cfg.GRPCCompressionThreshold,
+		"Size in bytes gRPC responses are gzip compressed from for clients accepting gzip, only responses to compressed requests are compressed if 0")
+	fs.DurationVar(&cfg.GRPCKeepaliveMaxConnectionIdle, "grpc-keepalive-max-connection-idle", cfg.GRPCKeepaliveMaxConnectionIdle, "How long idle gRPC connection is kept open, forever if 0")
+	fs.DurationVar(&cfg.GRPCKeepaliveMaxConnectionAge, "grpc-keepalive-max-connection-age", cfg.GRPCKeepaliveMaxConnectionAge, "How long gRPC connection is kept open before it is cycled, forever if 0")
+	fs.DurationVar(&cfg.GRPCKeepaliveMaxConnectionAgeGrace, "grpc-keepalive-max-connection-age-grace", cfg.GRPCKeepaliveMaxConnectionAgeGrace, "How long calls of cycled gRPC connection are waited for, forever if 0")
+	fs.DurationVar(&cfg.GRPCKeepaliveTime, "grpc-keepalive-time", cfg.GRPCKeepaliveTime, "How long gRPC connection is idle before client is pinged")
+	fs.DurationVar(&cfg.GRPCKeepaliveTimeout, "grpc-keepalive-timeout", cfg.GRPCKeepaliveTimeout, "How long ping ack is waited for before gRPC connection is closed")
+	fs.DurationVar(&cfg.GRPCKeepaliveMinTime, "grpc-keepalive-min-time", cfg.GRPCKeepaliveMinTime, "Minimum interval gRPC clients are allowed to ping at")
+	fs.BoolVar(&cfg.GRPCKeepalivePermitWithoutStream, "grpc-keepalive-permit-without-stream", cfg.GRPCKeepalivePermitWithoutStream, "Allow gRPC clients to ping when there are no active calls")
+	fs.StringVar(&cfg.HTTPPort, "http-port", cfg.HTTPPort, "HTTP port to bind")
+	fs.StringVar(&cfg.HTTPAddr, "http-addr", cfg.HTTPAddr, "Comma separated TCP addresses to bind instead of HTTP port on all interfaces, e.g. 127.0.0.1:8080,[::1]:8080")
+	fs.StringVar(&cfg.HTTPPlaintextAddr, "http-plaintext-addr", cfg.HTTPPlaintextAddr, "Comma separated TCP addresses HTTP gateway also listens on without TLS, e.g. 127.0.0.1:8081")
+	fs.StringVar(&cfg.HTTPListen, "http-listen", cfg.HTTPListen, "Unix domain socket to listen instead of HTTP port, e.g. unix:///var/run/todo-http.sock")
+	fs.BoolVar(&cfg.HTTPH2C, "http-h2c", cfg.HTTPH2C, "Accept HTTP/2 without TLS (h2c) on HTTP port, e.g. from proxies and load balancers")
+	fs.BoolVar(&cfg.HTTPDocs, "http-docs", cfg.HTTPDocs, "Serve OpenAPI spec at /swagger.json and Swagger UI at /docs")
+	fs.BoolVar(&cfg.HTTPGraphQL, "http-graphql", cfg.HTTPGraphQL, "Serve GraphQL endpoint at /graphql")
+	fs.StringVar(&cfg.HTTPRedirectAddr, "http-redirect-addr", cfg.HTTPRedirectAddr, "TCP address of plain text server redirecting to HTTPS gateway, e.g. :80, requires TLS")
+	fs.DurationVar(&cfg.HTTPHSTSMaxAge, "http-hsts-max-age", cfg.HTTPHSTSMaxAge, "max-age of Strict-Transport-Security header set on HTTPS responses, e.g. 8760h, not set if 0")
+	fs.BoolVar(&cfg.HTTPHSTSIncludeSubdomains, "http-hsts-include-subdomains", cfg.HTTPHSTSIncludeSubdomains, "Apply Strict-Transport-Security header to subdomains too")
+	fs.IntVar(&cfg.HTTPCompressionMinSize, "http-compression-min-size", cfg.HTTPCompressionMinSize, "Size in bytes HTTP responses are gzip/deflate compressed from, they are not compressed if 0")
+	fs.StringVar(&cfg.HTTPCompressionTypes, "http-compression-types", cfg.HTTPCompressionTypes, "Comma separated list of media types of compressed HTTP responses")
+	fs.BoolVar(&cfg.HTTPJSONEmitUnpopulated, "http-json-emit-unpopulated", cfg.HTTPJSONEmitUnpopulated, "Send fields with zero values in JSON responses")
+	fs.BoolVar(&cfg.HTTPJSONCamelCase, "http-json-camel-case", cfg.HTTPJSONCamelCase, "Use lowerCamelCase JSON field names instead of proto field names")
+	fs.BoolVar(&cfg.HTTPJSONEnumsAsInts, "http-json-enums-as-ints", cfg.HTTPJSONEnumsAsInts, "Send enum values as numbers instead of names in JSON responses")
+	fs.BoolVar(&cfg.HTTPJSONIndent, "http-json-indent", cfg.HTTPJSONIndent, "Indent JSON responses, meant for development")
+	fs.StringVar(&cfg.HTTPForwardHeaders, "http-forward-headers", cfg.HTTPForwardHeaders, "Comma separated list of request headers forwarded to gRPC server as metadata, e.g. X-Tenant-ID,Authorization")
+	fs.StringVar(&cfg.HTTPResponseMetadata, "http-response-metadata", cfg.HTTPResponseMetadata, "Comma separated list of gRPC response metadata keys sent as response headers without Grpc-Metadata- prefix")
+	fs.DurationVar(&cfg.HTTPReadHeaderTimeout, "http-read-header-timeout", cfg.HTTPReadHeaderTimeout, "How long reading of HTTP request headers may take, not limited if 0")
+	fs.DurationVar(&cfg.HTTPReadTimeout, "http-read-timeout", cfg.HTTPReadTimeout, "How long reading of whole HTTP request may take, not limited if 0")
+	fs.DurationVar(&cfg.HTTPWriteTimeout, "http-write-timeout", cfg.HTTPWriteTimeout, "How long handling of HTTP request and writing of response may take, not limited if 0")
+	fs.DurationVar(&cfg.HTTPIdleTimeout, "http-idle-timeout", cfg.HTTPIdleTimeout, "How long keep-alive HTTP connections wait for next request, read timeout is used if 0")
+	fs.DurationVar(&cfg.HTTPRequestTimeout, "http-request-timeout", cfg.HTTPRequestTimeout, "How long gRPC calls forwarded for single HTTP request may take, not limited if 0")
+	fs.BoolVar(&cfg.SinglePort, "single-port", cfg.SinglePort, "Serve HTTP gateway on gRPC port, -http-port is not used")
+	fs.BoolVar(&cfg.ProxyProtocol, "proxy-protocol", cfg.ProxyProtocol, "Read PROXY protocol v1/v2 headers sent by load balancers in TCP mode on gRPC and HTTP ports")
+	fs.StringVar(&cfg.ProxyProtocolTrusted, "proxy-protocol-trusted", cfg.ProxyProtocolTrusted, "Comma separated list of CIDRs of load balancers PROXY headers are read from, required with -proxy-protocol")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout,
+		"How long each server waits for in-flight requests on shutdown before they are cut off, 0 waits without limit")
+	fs.StringVar(&cfg.MetricsPort, "metrics-port", cfg.MetricsPort, "Prometheus metrics port to bind, metrics are not published if empty")
+	fs.DurationVar(&cfg.MetricsLogRuntimeInterval, "metrics-log-runtime-interval", cfg.MetricsLogRuntimeInterval, "How often Go runtime stats are logged, they are not logged if 0")
+	fs.StringVar(&cfg.AdminPort, "admin-port", cfg.AdminPort, "Admin port to bind to publish pprof endpoints, they are not published if empty")
+	fs.BoolVar(&cfg.AdminLocalhostOnly, "admin-localhost-only", cfg.AdminLocalhostOnly, "Bind admin ports on localhost only")
+	fs.StringVar(&cfg.AdminChannelzPort, "admin-channelz-port", cfg.AdminChannelzPort, "Admin port to bind to publish gRPC channelz service for grpcdebug, it is not published if empty")
+	databaseFlags(fs, &cfg.Config)
+	fs.DurationVar(&cfg.DatastoreHealthCheckInterval, "db-health-check-interval", cfg.DatastoreHealthCheckInterval, "How often database is checked to be reachable")
+	fs.Float64Var(&cfg.DatastoreErrorRateThreshold, "db-error-rate-threshold", cfg.DatastoreErrorRateThreshold, "Database error rate (0..1) server stops being ready at, it is not tracked if 0")
+	fs.DurationVar(&cfg.DatastoreErrorRateWindow, "db-error-rate-window", cfg.DatastoreErrorRateWindow, "Rolling window database error rate is computed over")
+	fs.IntVar(&cfg.DatastoreErrorRateMinRequests, "db-error-rate-min-requests", cfg.DatastoreErrorRateMinRequests, "Number of calls in the window required to exceed database error rate threshold")
+	fs.BoolVar(&cfg.DatastoreCircuitBreaker, "db-circuit-breaker", cfg.DatastoreCircuitBreaker, "Reject calls using database while its error rate exceeds threshold")
+	fs.BoolVar(&cfg.ReadOnly, "read-only", cfg.ReadOnly, "Reject calls changing todos with FailedPrecondition, e.g. during migrations, Admin Service calls are allowed, reloaded on SIGHUP")
+	fs.StringVar(&cfg.AlertWebhookURL, "alert-webhook-url", cfg.AlertWebhookURL, "URL dependency error rate alerts are posted to, they are not posted if empty")
+	fs.StringVar(&cfg.AlertWebhookSecret, "alert-webhook-secret", cfg.AlertWebhookSecret, "Secret alert webhook requests are signed with")
+	fs.StringVar(&cfg.EventsKafkaBrokers, "events-kafka-brokers", cfg.EventsKafkaBrokers, "Comma separated host:port addresses of Kafka brokers todo change events are published to, they are not published if empty")
+	fs.StringVar(&cfg.EventsKafkaTopic, "events-kafka-topic", cfg.EventsKafkaTopic, "Kafka topic todo change events are published to")
+	fs.StringVar(&cfg.EventsNATSURL, "events-nats-url", cfg.EventsNATSURL, "Comma separated NATS server URLs todo change events are published to with JetStream instead of Kafka, e.g. nats://localhost:4222")
+	fs.StringVar(&cfg.EventsNATSStream, "events-nats-stream", cfg.EventsNATSStream, "JetStream stream todo change events are stored in, it is created if it does not exist")
+	fs.StringVar(&cfg.EventsNATSSubject, "events-nats-subject", cfg.EventsNATSSubject, "NATS subject todo change events are published to")
+	fs.StringVar(&cfg.EventsEncoding, "events-encoding", cfg.EventsEncoding, "Encoding of todo change events: json or protobuf")
+	fs.DurationVar(&cfg.EventsRelayInterval, "events-relay-interval", cfg.EventsRelayInterval, "How often todo change events stored in outbox table are polled for and published")
+	fs.IntVar(&cfg.EventsRelayMaxAttempts, "events-relay-max-attempts", cfg.EventsRelayMaxAttempts, "Number of attempts after which todo change event stays in outbox table as failed and is not published")
+	fs.BoolVar(&cfg.WebhookDelivery, "webhook-delivery", cfg.WebhookDelivery, "Post todo change events to webhooks created by Admin Service, it needs webhook_delivery and event_outbox tables created by migrate")
+	fs.DurationVar(&cfg.WebhookPollInterval, "webhook-poll-interval", cfg.WebhookPollInterval, "How often pending webhook deliveries are looked for")
+	fs.DurationVar(&cfg.WebhookTimeout, "webhook-timeout", cfg.WebhookTimeout, "How long single webhook request may take")
+	fs.IntVar(&cfg.WebhookMaxAttempts, "webhook-max-attempts", cfg.WebhookMaxAttempts, "Number of attempts after which webhook delivery fails")
+	fs.DurationVar(&cfg.ReminderInterval, "reminder-interval", cfg.ReminderInterval, "How far ahead due reminders are looked for, database is scanned once per interval")
+	fs.DurationVar(&cfg.ReminderMaxDelay, "reminder-max-delay", cfg.ReminderMaxDelay, "How late reminder may fire, e.g. after server was down, older reminders are skipped")
+	fs.StringVar(&cfg.ReminderEmailHost, "reminder-email-host", cfg.ReminderEmailHost, "SMTP server host reminders are emailed through, they are not emailed if empty")
+	fs.IntVar(&cfg.ReminderEmailPort, "reminder-email-port", cfg.ReminderEmailPort, "SMTP server port, STARTTLS is used if server supports it")
+	fs.StringVar(&cfg.ReminderEmailUsername, "reminder-email-username", cfg.ReminderEmailUsername, "SMTP username, authentication is skipped if empty")
+	fs.StringVar(&cfg.ReminderEmailPassword, "reminder-email-password", cfg.ReminderEmailPassword, "SMTP password")
+	fs.StringVar(&cfg.ReminderEmailFrom, "reminder-email-from", cfg.ReminderEmailFrom, "Sender address of reminder emails")
+	fs.StringVar(&cfg.ReminderEmailTo, "reminder-email-to", cfg.ReminderEmailTo, "Comma separated list of addresses reminders are emailed to")
+	fs.StringVar(&cfg.ReminderEmailSubject, "reminder-email-subject", cfg.ReminderEmailSubject, "Go template of reminder email subject, e.g. 'Reminder: {{.Title}}'")
+	fs.StringVar(&cfg.ReminderEmailBody, "reminder-email-body", cfg.ReminderEmailBody, "Go template of reminder email body with .Title, .Description and .At of todo")
+	fs.StringVar(&cfg.ReminderFCMCredentialsFile, "reminder-fcm-credentials-file", cfg.ReminderFCMCredentialsFile,
+		"Google service account key file reminders are pushed to FCM devices with, they are not pushed to FCM if empty")
+	fs.StringVar(&cfg.ReminderFCMProjectID, "reminder-fcm-project-id", cfg.ReminderFCMProjectID, "Firebase project ID, it is read from credentials if empty")
+	fs.StringVar(&cfg.ReminderWebPushPublicKey, "reminder-webpush-public-key", cfg.ReminderWebPushPublicKey, "VAPID public key reminders are pushed to browsers with, they are not pushed to browsers if empty")
+	fs.StringVar(&cfg.ReminderWebPushPrivateKey, "reminder-webpush-private-key", cfg.ReminderWebPushPrivateKey, "VAPID private key")
+	fs.StringVar(&cfg.ReminderWebPushSubscriber, "reminder-webpush-subscriber", cfg.ReminderWebPushSubscriber, "Contact email sent to Web Push services")
+	fs.StringVar(&cfg.ReminderChatWebhookURL, "reminder-chat-webhook-url", cfg.ReminderChatWebhookURL, "Slack or Teams incoming webhook URL reminders are posted to, they are not posted if empty")
+	fs.StringVar(&cfg.ReminderChatFormat, "reminder-chat-format", cfg.ReminderChatFormat, "Format of chat messages: slack or teams")
+	fs.StringVar(&cfg.ReminderChatTemplate, "reminder-chat-template", cfg.ReminderChatTemplate, "Go template of reminder chat message with .Title, .Description and .At of todo")
+	fs.StringVar(&cfg.ReminderChatDigestTemplate, "reminder-chat-digest-template", cfg.ReminderChatDigestTemplate, "Go template of digest chat message with .From, .To and .Reminders")
+	fs.Float64Var(&cfg.ReminderChatRateLimit, "reminder-chat-rate-limit", cfg.ReminderChatRateLimit, "Maximum number of chat messages posted per second")
+	fs.StringVar(&cfg.ReminderDigestCron, "reminder-digest-cron", cfg.ReminderDigestCron, "Cron spec digests of upcoming reminders are posted to chat on, e.g. '0 8 * * *', they are not posted if empty")
+	fs.DurationVar(&cfg.ReminderDigestWindow, "reminder-digest-window", cfg.ReminderDigestWindow, "How far ahead digest lists reminders")
+	fs.StringVar(&cfg.TLSCertFile, "tls-cert-file", cfg.TLSCertFile, "TLS certificate file, TLS is disabled if empty")
+	fs.StringVar(&cfg.TLSKeyFile, "tls-key-file", cfg.TLSKeyFile, "TLS private key file")
+	fs.DurationVar(&cfg.TLSReloadInterval, "tls-reload-interval", cfg.TLSReloadInterval, "How often TLS certificate files are checked for changes, they are only reloaded on SIGHUP if 0")
+	fs.StringVar(&cfg.AuthHMACSecret, "auth-hmac-secret", cfg.AuthHMACSecret, "Shared secret to verify HMAC signed requests, authentication is disabled if empty")
+	fs.BoolVar(&cfg.AuthAPIKey, "auth-api-key", cfg.AuthAPIKey, "Require API key created by Admin Service for Todo Service calls, Admin Service calls always require API key with 'admin' scope")
+	fs.StringVar(&cfg.SentryDSN, "sentry-dsn", cfg.SentryDSN, "Sentry DSN to report panics and server side errors to, they are not reported if empty")
+	fs.StringVar(&cfg.SentryEnvironment, "sentry-environment", cfg.SentryEnvironment, "Environment name Sentry events are tagged with")
+	fs.StringVar(&cfg.TracingOTLPEndpoint, "tracing-otlp-endpoint", cfg.TracingOTLPEndpoint, "OTLP gRPC collector host:port to export trace spans to, tracing is disabled if empty")
+	fs.StringVar(&cfg.TracingServiceName, "tracing-service-name", cfg.TracingServiceName, "Service name trace spans are tagged with")
+	fs.IntVar(&cfg.LogLevel, "log-level", cfg.LogLevel, "Global log level")
+	fs.StringVar(&cfg.LogTimeFormat, "log-time-format", cfg.LogTimeFormat, "Print time format for logger e.g. 2006-01-02T15:04:05Z07:00")
+	fs.StringVar(&cfg.LogFormat, "log-format", cfg.LogFormat, "Console log format: json or console (human readable with colored levels)")
+	fs.BoolVar(&cfg.LogPayloads, "log-payloads", cfg.LogPayloads, "Log gRPC request and response messages")
+	fs.StringVar(&cfg.LogRedactFields, "log-redact-fields", cfg.LogRedactFields,
+		"Comma separated message fields redacted from logged payloads, e.g. description,Todo.title")
+	fs.BoolVar(&cfg.LogErrorStacks, "log-error-stacks", cfg.LogErrorStacks, "Log stack traces of server side errors")
+	fs.IntVar(&cfg.LogSamplingInitial, "log-sampling-initial", cfg.LogSamplingInitial, "Number of identical log entries logged every second, sampling is disabled if 0")
+	fs.IntVar(&cfg.LogSamplingThereafter, "log-sampling-thereafter", cfg.LogSamplingThereafter, "Log every Nth identical entry after initial entries are logged")
+	fs.StringVar(&cfg.LogFile, "log-file", cfg.LogFile, "File to also write Debug, Info and Warn entries to")
+	fs.StringVar(&cfg.LogErrorFile, "log-error-file", cfg.LogErrorFile, "File to also write Error and higher entries to")
+	fs.IntVar(&cfg.LogFileMaxSize, "log-file-max-size", cfg.LogFileMaxSize, "Size in megabytes log files are rotated at")
+	fs.IntVar(&cfg.LogFileMaxAge, "log-file-max-age", cfg.LogFileMaxAge, "Number of days rotated log files are kept, 0 keeps them regardless of age")
+	fs.IntVar(&cfg.LogFileMaxBackups, "log-file-max-backups", cfg.LogFileMaxBackups, "Number of rotated log files kept, 0 keeps all")
+	fs.BoolVar(&cfg.LogFileCompress, "log-file-compress", cfg.LogFileCompress, "Compress rotated log files with gzip")
+	fs.StringVar(&cfg.LogHTTPAccessFormat, "log-http-access-format", cfg.LogHTTPAccessFormat, "HTTP access log format: json or combined")
+
+	return fs, printVersion, printConfig
+}
 
-	return grpc.RunServer(ctx, v1API, cfg.GRPCPort)
+// databaseFlags defines flags of database settings of cfg
+func databaseFlags(fs *flag.FlagSet, cfg *server.Config) {
+	fs.StringVar(&cfg.DatastoreDBHost, "db-host", cfg.DatastoreDBHost, "Database Host")
+	fs.StringVar(&cfg.DatastoreDBUser, "db-user", cfg.DatastoreDBUser, "Database User")
+	fs.StringVar(&cfg.DatastoreDBPassword, "db-password", cfg.DatastoreDBPassword, "Database Password")
+	fs.StringVar(&cfg.DatastoreDBSchema, "db-schema", cfg.DatastoreDBSchema, "Database Schema")
 }
@@ -0,0 +1,339 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// benchOps are operations of bench in order they are reported
+var benchOps = []string{"create", "read", "update", "delete"}
+
+// benchMix is weighted mix of bench operations
+type benchMix struct {
+	ops     []string
+	weights []int
+	total   int
+}
+
+// parseMix parses mix of operations, e.g. create=10,read=80,update=5,delete=5
+func parseMix(s string) (benchMix, error) {
+	var m benchMix
+	for _, pair := range strings.Split(s, ",") {
+		p := strings.SplitN(pair, "=", 2)
+		if len(p) != 2 {
+			return benchMix{}, fmt.Errorf("invalid mix '%s', op=weight pairs expected", s)
+		}
+		op := strings.ToLower(strings.TrimSpace(p[0]))
+		known := false
+		for _, o := range benchOps {
+			known = known || o == op
+		}
+		if !known {
+			return benchMix{}, fmt.Errorf("invalid mix operation '%s', %s expected", op, strings.Join(benchOps, ", "))
+		}
+		w, err := strconv.Atoi(strings.TrimSpace(p[1]))
+		if err != nil || w < 0 {
+			return benchMix{}, fmt.Errorf("invalid weight '%s' of operation '%s'", p[1], op)
+		}
+		m.ops = append(m.ops, op)
+		m.weights = append(m.weights, w)
+		m.total += w
+	}
+	if m.total == 0 {
+		return benchMix{}, fmt.Errorf("invalid mix '%s', at least one weight must be positive", s)
+	}
+	return m, nil
+}
+
+// pick returns random operation of mix
+func (m benchMix) pick(r *rand.Rand) string {
+	n := r.Intn(m.total)
+	for i, w := range m.weights {
+		if n < w {
+			return m.ops[i]
+		}
+		n -= w
+	}
+	return m.ops[len(m.ops)-1]
+}
+
+// benchResults are latencies and errors of bench calls
+type benchResults struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	codes     map[codes.Code]int
+	skipped   int
+}
+
+// record records call of op that took d and failed with err if it is not nil
+func (r *benchResults) record(op string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[op] = append(r.latencies[op], d)
+	if err != nil {
+		r.errors[op]++
+		r.codes[status.Code(err)]++
+	}
+}
+
+// benchIDs are IDs of todos created by bench, they are read, updated and deleted by it
+type benchIDs struct {
+	mu  sync.Mutex
+	ids []int64
+}
+
+func (b *benchIDs) add(id int64) {
+	b.mu.Lock()
+	b.ids = append(b.ids, id)
+	b.mu.Unlock()
+}
+
+// random returns random ID, remove removes it, so it is not deleted twice
+func (b *benchIDs) random(r *rand.Rand, remove bool) (int64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.ids) == 0 {
+		return 0, false
+	}
+	i := r.Intn(len(b.ids))
+	id := b.ids[i]
+	if remove {
+		b.ids[i] = b.ids[len(b.ids)-1]
+		b.ids = b.ids[:len(b.ids)-1]
+	}
+	return id, true
+}
+
+// newBenchCommand returns command load testing gRPC server
+func newBenchCommand(s *settings) *cobra.Command {
+	var (
+		rps, concurrency, preload int
+		duration                  time.Duration
+		mix                       string
+		cleanup                   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Load test gRPC server and report latency percentiles and errors",
+		Long: `Load test gRPC server at constant rate with mix of operations and report latency percentiles
+and errors. Read, update and delete use todos created by bench, calls are not retried and each call
+is limited by --timeout. Ctrl-C stops bench early and reports calls made so far.`,
+		Example: `  todo bench --rps 500 --duration 60s --mix create=10,read=80,update=5,delete=5`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := parseMix(mix)
+			if err != nil {
+				return err
+			}
+			if rps <= 0 || concurrency <= 0 {
+				return fmt.Errorf("invalid rate %d or concurrency %d, positive numbers expected", rps, concurrency)
+			}
+
+			tlsConfig, err := loadTLS(s)
+			if err != nil {
+				return err
+			}
+			c, err := dialClient(s, tlsConfig, client.WithoutRetries(), client.WithTimeout(s.Timeout))
+			if err != nil {
+				return err
+			}
+			defer c.Close()
+
+			ctx := cmd.Context()
+			ids := &benchIDs{}
+			if cleanup {
+				defer func() {
+					// todos are deleted even if bench is interrupted
+					for _, id := range ids.ids {
+						_ = c.Delete(context.Background(), id)
+					}
+				}()
+			}
+			for i := 0; i < preload; i++ {
+				id, err := c.Create(ctx, benchTodo(i))
+				if err != nil {
+					return fmt.Errorf("Failed to preload todos: %v", s.explain(err))
+				}
+				ids.add(id)
+			}
+
+			res := runBench(ctx, c, m, ids, rps, concurrency, duration)
+			return res.print(os.Stdout)
+		},
+	}
+	cmd.Flags().IntVar(&rps, "rps", 100, "Calls started per second")
+	cmd.Flags().DurationVar(&duration, "duration", 10*time.Second, "How long calls are started")
+	cmd.Flags().StringVar(&mix, "mix", "create=10,read=80,update=5,delete=5", "Weights of operations: create, read, update and delete")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 100, "Maximum number of calls in flight, calls over it are skipped and reported")
+	cmd.Flags().IntVar(&preload, "preload", 100, "Number of todos created before bench for reads, updates and deletes")
+	cmd.Flags().BoolVar(&cleanup, "cleanup", true, "Delete todos created by bench when it ends")
+
+	return cmd
+}
+
+// benchReport is result of bench run
+type benchReport struct {
+	*benchResults
+	elapsed time.Duration
+}
+
+// runBench starts calls of mix at rps for duration, at most concurrency calls are in flight
+func runBench(ctx context.Context, c *client.Client, m benchMix, ids *benchIDs, rps, concurrency int, duration time.Duration) benchReport {
+	res := &benchResults{
+		latencies: map[string][]time.Duration{},
+		errors:    map[string]int{},
+		codes:     map[codes.Code]int{},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	ticker := time.NewTicker(time.Second / time.Duration(rps))
+	defer ticker.Stop()
+
+	// call starts n-th call of bench unless concurrency limit is reached
+	call := func(n int) {
+		select {
+		case sem <- struct{}{}:
+		default:
+			res.mu.Lock()
+			res.skipped++
+			res.mu.Unlock()
+			return
+		}
+
+		op := m.pick(r)
+		id, ok := ids.random(r, op == "delete")
+		if !ok {
+			// there is no todo to read, update or delete
+			op = "create"
+		}
+		wg.Add(1)
+		go func(op string, id int64, n int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			// calls started before end of bench are not cancelled by it
+			callCtx := context.Background()
+			started := time.Now()
+			var err error
+			switch op {
+			case "create":
+				id, err = c.Create(callCtx, benchTodo(n))
+				if err == nil {
+					ids.add(id)
+				}
+			case "read":
+				_, err = c.Get(callCtx, id)
+			case "update":
+				t := benchTodo(n)
+				t.ID = id
+				err = c.Update(callCtx, t)
+			case "delete":
+				err = c.Delete(callCtx, id)
+			}
+			res.record(op, time.Since(started), err)
+		}(op, id, n)
+	}
+
+	start := time.Now()
+	for n := 0; ; {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return benchReport{benchResults: res, elapsed: time.Since(start)}
+		case <-ticker.C:
+		}
+
+		// ticks missed by slow loop are caught up, so rate is kept
+		for due := int(time.Since(start).Seconds() * float64(rps)); n < due; n++ {
+			call(n)
+		}
+	}
+}
+
+// benchTodo returns todo number n created or updated by bench
+func benchTodo(n int) client.Todo {
+	return client.Todo{
+		Title:       fmt.Sprintf("bench todo %d", n),
+		Description: "created by todo bench",
+		Reminder:    time.Now().Add(24 * time.Hour),
+	}
+}
+
+// print writes latency percentiles by operation and error counts by status code to w
+func (r benchReport) print(w io.Writer) error {
+	var all []time.Duration
+	errors := 0
+	for _, op := range benchOps {
+		all = append(all, r.latencies[op]...)
+		errors += r.errors[op]
+	}
+	fmt.Fprintf(w, "%d calls in %s (%.1f/s), %d errors, %d skipped at concurrency limit\n\n",
+		len(all), r.elapsed.Round(time.Millisecond), float64(len(all))/r.elapsed.Seconds(), errors, r.skipped)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OP\tCALLS\tERRORS\tP50\tP90\tP99\tMAX\t")
+	row := func(name string, lat []time.Duration, errs int) {
+		if len(lat) == 0 {
+			return
+		}
+		sort.Slice(lat, func(i, j int) bool { return lat[i] < lat[j] })
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n", name, len(lat), errs,
+			percentile(lat, 0.5), percentile(lat, 0.9), percentile(lat, 0.99), lat[len(lat)-1].Round(time.Microsecond))
+	}
+	for _, op := range benchOps {
+		row(op, r.latencies[op], r.errors[op])
+	}
+	row("total", all, errors)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(r.codes) == 0 {
+		return nil
+	}
+	var cs []codes.Code
+	for c := range r.codes {
+		cs = append(cs, c)
+	}
+	sort.Slice(cs, func(i, j int) bool { return r.codes[cs[i]] > r.codes[cs[j]] })
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ERROR\tCALLS")
+	for _, c := range cs {
+		fmt.Fprintf(tw, "%s\t%d\n", c, r.codes[c])
+	}
+	return tw.Flush()
+}
+
+// percentile returns latency at quantile q (0..1) of sorted latencies
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	i := int(q*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i].Round(time.Microsecond)
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// newAddCommand returns command creating todo
+func newAddCommand(s *settings) *cobra.Command {
+	var description, remind string
+	cmd := &cobra.Command{
+		Use:     "add TITLE",
+		Short:   "Create todo",
+		Example: `  todo add "buy milk" --remind 2h`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reminder, err := parseReminder(remind, time.Now())
+			if err != nil {
+				return err
+			}
+
+			st, close, err := openStore(s)
+			if err != nil {
+				return err
+			}
+			defer close()
+
+			ctx, cancel := s.context(cmd.Context())
+			defer cancel()
+
+			id, err := st.Create(ctx, client.Todo{
+				Title:       args[0],
+				Description: description,
+				Reminder:    reminder,
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to create todo: %v", s.explain(err))
+			}
+
+			fmt.Printf("created todo %d, due %s\n", id, reminder.Local().Format("2006-01-02 15:04"))
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&description, "description", "d", "", "Description of todo")
+	cmd.Flags().StringVarP(&remind, "remind", "r", "24h",
+		"When todo is due: duration from now (e.g. 2h), time today (e.g. 17:30), date and time (e.g. '2006-01-02 15:04') or RFC 3339 time")
+
+	return cmd
+}
+
+// newListCommand returns command listing todos ordered by reminder
+func newListCommand(s *settings) *cobra.Command {
+	var due, output string
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List todos ordered by reminder",
+		Example: `  todo list --due today
+  todo list -o json | jq '.[].title'`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+			match, err := dueFilter(due, time.Now())
+			if err != nil {
+				return err
+			}
+
+			st, close, err := openStore(s)
+			if err != nil {
+				return err
+			}
+			defer close()
+
+			ctx, cancel := s.context(cmd.Context())
+			defer cancel()
+
+			todos, err := st.List(ctx)
+			if err != nil {
+				return fmt.Errorf("Failed to list todos: %v", s.explain(err))
+			}
+
+			var listed []client.Todo
+			for _, t := range todos {
+				if match(t.Reminder) {
+					listed = append(listed, t)
+				}
+			}
+			sort.SliceStable(listed, func(i, j int) bool {
+				return listed[i].Reminder.Before(listed[j].Reminder)
+			})
+
+			return printTodos(os.Stdout, output, listed, false)
+		},
+	}
+	cmd.Flags().StringVar(&due, "due", "", "Only list todos due: overdue, today, tomorrow, week or within duration from now (e.g. 48h), all if empty")
+	outputFlag(cmd, &output)
+
+	return cmd
+}
+
+// newGetCommand returns command printing todos
+func newGetCommand(s *settings) *cobra.Command {
+	var output string
+	cmd := &cobra.Command{
+		Use:     "get ID...",
+		Short:   "Print todos",
+		Example: `  todo get 42 -o yaml`,
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := validateOutput(output); err != nil {
+				return err
+			}
+			ids, err := parseIDs(args)
+			if err != nil {
+				return err
+			}
+
+			st, close, err := openStore(s)
+			if err != nil {
+				return err
+			}
+			defer close()
+
+			ctx, cancel := s.context(cmd.Context())
+			defer cancel()
+
+			todos := make([]client.Todo, 0, len(ids))
+			for _, id := range ids {
+				t, err := st.Get(ctx, id)
+				if err != nil {
+					if client.IsNotFound(err) {
+						return fmt.Errorf("todo %d does not exist", id)
+					}
+					return fmt.Errorf("Failed to read todo %d: %v", id, s.explain(err))
+				}
+				todos = append(todos, t)
+			}
+
+			return printTodos(os.Stdout, output, todos, len(ids) == 1)
+		},
+	}
+	outputFlag(cmd, &output)
+
+	return cmd
+}
+
+// newDoneCommand returns command completing todos, service keeps no completion state, so they are removed
+func newDoneCommand(s *settings) *cobra.Command {
+	return &cobra.Command{
+		Use:   "done ID...",
+		Short: "Complete todos, completed todos are removed",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteTodos(cmd.Context(), s, args, "done")
+		},
+	}
+}
+
+// newRemoveCommand returns command deleting todos
+func newRemoveCommand(s *settings) *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm ID...",
+		Aliases: []string{"remove"},
+		Short:   "Delete todos",
+		Args:    cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return deleteTodos(cmd.Context(), s, args, "removed")
+		},
+	}
+}
+
+// deleteTodos deletes todos with ids of args, each deleted todo is reported with verb
+func deleteTodos(ctx context.Context, s *settings, args []string, verb string) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+
+	st, close, err := openStore(s)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	ctx, cancel := s.context(ctx)
+	defer cancel()
+
+	for _, id := range ids {
+		if err := st.Delete(ctx, id); err != nil {
+			if client.IsNotFound(err) {
+				return fmt.Errorf("todo %d does not exist", id)
+			}
+			return fmt.Errorf("Failed to delete todo %d: %v", id, s.explain(err))
+		}
+		fmt.Printf("todo %d %s\n", id, verb)
+	}
+	return nil
+}
+
+// parseIDs parses todo IDs of args
+func parseIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid todo ID '%s'", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// parseReminder parses value of --remind relative to now
+func parseReminder(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("15:04", s, time.Local); err == nil {
+		y, m, d := now.Date()
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, time.Local), nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid reminder '%s', duration (2h), time (17:30), date and time (2006-01-02 15:04) or RFC 3339 time expected", s)
+}
+
+// dueFilter returns function matching reminders of todos that are due as described by value of --due
+func dueFilter(due string, now time.Time) (func(time.Time) bool, error) {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	between := func(from, to time.Time) func(time.Time) bool {
+		return func(t time.Time) bool {
+			return !t.Before(from) && t.Before(to)
+		}
+	}
+
+	switch strings.ToLower(due) {
+	case "", "all":
+		return func(time.Time) bool { return true }, nil
+	case "overdue":
+		return func(t time.Time) bool { return t.Before(now) }, nil
+	case "today":
+		return between(today, today.AddDate(0, 0, 1)), nil
+	case "tomorrow":
+		return between(today.AddDate(0, 0, 1), today.AddDate(0, 0, 2)), nil
+	case "week":
+		return between(today, today.AddDate(0, 0, 7)), nil
+	}
+
+	dur, err := time.ParseDuration(due)
+	if err != nil {
+		return nil, fmt.Errorf("invalid due '%s', overdue, today, tomorrow, week or duration (48h) expected", due)
+	}
+	return between(now, now.Add(dur)), nil
+}
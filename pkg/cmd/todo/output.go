@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// Output formats of commands printing todos
+const (
+	// outputTable prints aligned columns of ID, due time and title
+	outputTable = "table"
+	// outputWide prints table with description too
+	outputWide = "wide"
+	// outputJSON prints JSON, e.g. for jq
+	outputJSON = "json"
+	// outputYAML prints YAML
+	outputYAML = "yaml"
+)
+
+// todoOutput is todo printed as JSON or YAML
+type todoOutput struct {
+	ID          int64     `json:"id" yaml:"id"`
+	Title       string    `json:"title" yaml:"title"`
+	Description string    `json:"description" yaml:"description"`
+	Reminder    time.Time `json:"reminder" yaml:"reminder"`
+}
+
+// outputFlag defines --output flag of cmd
+func outputFlag(cmd *cobra.Command, output *string) {
+	cmd.Flags().StringVarP(output, "output", "o", outputTable, "Output format: table, wide, json or yaml")
+}
+
+// validateOutput returns error if output is not known format
+func validateOutput(output string) error {
+	switch output {
+	case outputTable, outputWide, outputJSON, outputYAML:
+		return nil
+	}
+	return fmt.Errorf("invalid output '%s', %s, %s, %s or %s expected", output, outputTable, outputWide, outputJSON, outputYAML)
+}
+
+// printTodos writes todos to w in output format, single is set if one todo is requested,
+// so it is printed as object instead of list by JSON and YAML
+func printTodos(w io.Writer, output string, todos []client.Todo, single bool) error {
+	out := make([]todoOutput, 0, len(todos))
+	for _, t := range todos {
+		out = append(out, todoOutput{ID: t.ID, Title: t.Title, Description: t.Description, Reminder: t.Reminder})
+	}
+	var v interface{} = out
+	if single && len(out) == 1 {
+		v = out[0]
+	}
+
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	if output == outputWide {
+		fmt.Fprintln(tw, "ID\tDUE\tTITLE\tDESCRIPTION")
+	} else {
+		fmt.Fprintln(tw, "ID\tDUE\tTITLE")
+	}
+	for _, t := range todos {
+		fmt.Fprintf(tw, "%d\t%s\t%s", t.ID, t.Reminder.Local().Format("2006-01-02 15:04"), t.Title)
+		if output == outputWide {
+			fmt.Fprintf(tw, "\t%s", t.Description)
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
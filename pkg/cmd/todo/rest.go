@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/auth"
+	"github.com/maslow123/go-grpc/pkg/client"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// restClient calls Todo Service through HTTP/REST gateway, failures are returned as gRPC status errors
+// like errors of gRPC client
+type restClient struct {
+	baseURL    string
+	hmacSecret string
+	http       *http.Client
+}
+
+// newRESTClient returns client of gateway at baseURL, e.g. http://localhost:8080, https URL is verified
+// with tlsConfig, default TLS configuration is used if it is nil
+func newRESTClient(baseURL, hmacSecret string, tlsConfig *tls.Config) *restClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &restClient{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		hmacSecret: hmacSecret,
+		http:       &http.Client{Transport: transport},
+	}
+}
+
+// Create creates todo, its ID is returned
+func (c *restClient) Create(ctx context.Context, t client.Todo) (int64, error) {
+	var res v1.CreateResponse
+	err := c.do(ctx, http.MethodPost, "/v1/todo", &v1.CreateRequest{Api: "v1", Todo: toProto(t)}, &res)
+	return res.Id, err
+}
+
+// Get returns todo with id
+func (c *restClient) Get(ctx context.Context, id int64) (client.Todo, error) {
+	var res v1.ReadResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/todo/"+strconv.FormatInt(id, 10), nil, &res); err != nil {
+		return client.Todo{}, err
+	}
+	return fromProto(res.Todo), nil
+}
+
+// List returns all todos
+func (c *restClient) List(ctx context.Context) ([]client.Todo, error) {
+	var res v1.ReadAllResponse
+	if err := c.do(ctx, http.MethodGet, "/v1/todo/all", nil, &res); err != nil {
+		return nil, err
+	}
+
+	todos := make([]client.Todo, 0, len(res.Todos))
+	for _, t := range res.Todos {
+		todos = append(todos, fromProto(t))
+	}
+	return todos, nil
+}
+
+// Update replaces title, description and reminder of todo with t.ID
+func (c *restClient) Update(ctx context.Context, t client.Todo) error {
+	return c.do(ctx, http.MethodPut, "/v1/todo/"+strconv.FormatInt(t.ID, 10),
+		&v1.UpdateRequest{Api: "v1", Todo: toProto(t)}, &v1.UpdateResponse{})
+}
+
+// Delete deletes todo with id
+func (c *restClient) Delete(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/v1/todo/"+strconv.FormatInt(id, 10), nil, &v1.DeleteResponse{})
+}
+
+// do sends req as JSON body of request to path and reads response into res,
+// error envelope of gateway is converted to gRPC status error
+func (c *restClient) do(ctx context.Context, method, path string, req, res proto.Message) error {
+	var body []byte
+	if req != nil {
+		var err error
+		if body, err = protojson.Marshal(req); err != nil {
+			return err
+		}
+	}
+
+	r, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	if len(c.hmacSecret) > 0 {
+		t := time.Now()
+		r.Header.Set(auth.TimestampHeader, strconv.FormatInt(t.Unix(), 10))
+		r.Header.Set(auth.SignatureHeader, auth.Sign(c.hmacSecret, t, method+" "+r.URL.RequestURI(), body))
+	}
+
+	resp, err := c.http.Do(r)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(b, &e); err != nil || len(e.Error.Code) == 0 {
+			return status.Error(codes.Unknown, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(b))))
+		}
+		return status.Error(codes.Code(code.Code_value[e.Error.Code]), e.Error.Message)
+	}
+
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(b, res)
+}
+
+// toProto converts t to todo message
+func toProto(t client.Todo) *v1.Todo {
+	todo := &v1.Todo{Id: t.ID, Title: t.Title, Description: t.Description}
+	if !t.Reminder.IsZero() {
+		todo.Reminder = timestamppb.New(t.Reminder)
+	}
+	return todo
+}
+
+// fromProto converts todo message to client.Todo
+func fromProto(t *v1.Todo) client.Todo {
+	todo := client.Todo{ID: t.GetId(), Title: t.GetTitle(), Description: t.GetDescription()}
+	if t.GetReminder() != nil {
+		todo.Reminder = t.Reminder.AsTime()
+	}
+	return todo
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns todo command managing todos of Todo Service
+func NewCommand() *cobra.Command {
+	s := &settings{}
+	root := &cobra.Command{
+		Use:           "todo",
+		Short:         "Manage todos of Todo Service",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return s.load(cmd.Flags())
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&s.Server, "server", "localhost:9090", "gRPC server in format host:port, replicas are balanced for dns:///host:port or static:///host1:port,host2:port")
+	flags.StringVar(&s.Balancer, "balancer", client.DefaultBalancer, "Load balancing policy of gRPC server replicas: round_robin or pick_first")
+	flags.StringVar(&s.RESTServer, "rest-server", "http://localhost:8080", "HTTP gateway URL calls fall back to when gRPC server is unavailable")
+	flags.StringVar(&s.Transport, "transport", transportAuto, "Transport of calls: auto (gRPC with REST fallback), grpc or rest")
+	flags.StringVar(&s.HMACSecret, "hmac-secret", "", "Shared secret to sign calls with, calls are not signed if empty")
+	flags.BoolVar(&s.TLS, "tls", false, "Connect to gRPC server over TLS")
+	flags.StringVar(&s.TLSConfig.CAFile, "tls-ca-file", "", "PEM encoded CA certificates server certificate is verified with, system roots if empty")
+	flags.StringVar(&s.TLSConfig.CertFile, "tls-cert-file", "", "PEM encoded client certificate presented to server requiring mutual TLS")
+	flags.StringVar(&s.TLSConfig.KeyFile, "tls-key-file", "", "PEM encoded private key of --tls-cert-file")
+	flags.StringVar(&s.TLSConfig.ServerName, "tls-server-name", "", "Server name certificate is verified for, host of --server if empty")
+	flags.BoolVar(&s.TLSConfig.InsecureSkipVerify, "tls-skip-verify", false, "Do not verify server certificate, e.g. self-signed certificate of development server")
+	flags.DurationVar(&s.KeepaliveTime, "keepalive-time", 0, "How long gRPC connection is idle before server is pinged, server is not pinged if 0")
+	flags.DurationVar(&s.KeepaliveTimeout, "keepalive-timeout", 20*time.Second, "How long ping ack is waited for before gRPC connection is closed")
+	flags.DurationVar(&s.Timeout, "timeout", client.DefaultTimeout, "How long command may take, including retries and REST fallback, it is not limited if 0")
+	flags.StringVar(&s.ConfigFile, "config", defaultConfigFile(),
+		"YAML file of settings keyed by flag names, e.g. server: todo.example.com:9090. Settings are also read from TODO_* environment variables, e.g. TODO_SERVER")
+
+	root.AddCommand(
+		newAddCommand(s),
+		newListCommand(s),
+		newGetCommand(s),
+		newDoneCommand(s),
+		newRemoveCommand(s),
+		newBenchCommand(s),
+	)
+
+	return root
+}
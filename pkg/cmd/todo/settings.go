@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v2"
+)
+
+// settingFlags are flags that are also read from environment and config file
+var settingFlags = []string{
+	"server", "balancer", "rest-server", "transport", "hmac-secret",
+	"tls", "tls-ca-file", "tls-cert-file", "tls-key-file", "tls-server-name", "tls-skip-verify",
+	"keepalive-time", "keepalive-timeout", "timeout",
+}
+
+// settings are connection settings shared by all commands
+type settings struct {
+	// Server is gRPC server address host:port
+	Server string
+	// Balancer is load balancing policy of gRPC server replicas
+	Balancer string
+	// RESTServer is HTTP gateway URL calls fall back to when gRPC server is unavailable
+	RESTServer string
+	// Transport is auto (gRPC with REST fallback), grpc or rest
+	Transport string
+	// HMACSecret is shared secret calls are signed with, they are not signed if empty
+	HMACSecret string
+	// TLS enables TLS of gRPC server connection configured by TLSConfig, it is also used for https gateway URL
+	TLS       bool
+	TLSConfig client.TLSConfig
+	// KeepaliveTime is how long gRPC connection is idle before server is pinged, it is not pinged if 0
+	KeepaliveTime time.Duration
+	// KeepaliveTimeout is how long ping ack is waited for before connection is closed
+	KeepaliveTimeout time.Duration
+	// Timeout limits how long command may take, it is not limited if 0
+	Timeout time.Duration
+	// ConfigFile is YAML file of settings keyed by flag names
+	ConfigFile string
+}
+
+// context returns context of command run with parent ctx, it is done after timeout of s
+func (s *settings) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, s.Timeout)
+}
+
+// explain returns err of call made with context of s, timed out and interrupted calls are described
+// without details of transport
+func (s *settings) explain(err error) error {
+	switch status.Code(err) {
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("timed out after %s, see --timeout", s.Timeout)
+	case codes.Canceled:
+		return fmt.Errorf("interrupted")
+	}
+	return err
+}
+
+// defaultConfigFile returns path of config file in user config directory, e.g. ~/.config/todo/config.yaml
+func defaultConfigFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "todo", "config.yaml")
+}
+
+// envName returns environment variable of flag name, e.g. TODO_REST_SERVER for rest-server
+func envName(name string) string {
+	return "TODO_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// load sets setting flags of fs not set on command line from environment, then from config file
+func (s *settings) load(fs *pflag.FlagSet) error {
+	values := map[string]string{}
+	if len(s.ConfigFile) > 0 {
+		b, err := ioutil.ReadFile(s.ConfigFile)
+		switch {
+		case os.IsNotExist(err) && !fs.Changed("config"):
+			// default config file is optional
+		case err != nil:
+			return fmt.Errorf("Failed to read config file: %v", err)
+		default:
+			if err := yaml.Unmarshal(b, &values); err != nil {
+				return fmt.Errorf("Failed to parse config file '%s': %v", s.ConfigFile, err)
+			}
+		}
+	}
+
+	for _, name := range settingFlags {
+		if fs.Changed(name) {
+			continue
+		}
+
+		value, ok := os.LookupEnv(envName(name))
+		if !ok {
+			value, ok = values[name]
+		}
+		if !ok {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value of setting '%s': %v", name, err)
+		}
+	}
+
+	switch s.Transport {
+	case transportAuto, transportGRPC, transportREST:
+	default:
+		return fmt.Errorf("invalid transport '%s', %s, %s or %s expected", s.Transport, transportAuto, transportGRPC, transportREST)
+	}
+
+	return nil
+}
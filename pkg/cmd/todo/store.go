@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/maslow123/go-grpc/pkg/client"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Transports of commands
+const (
+	// transportAuto calls gRPC server and falls back to HTTP gateway when it is unavailable
+	transportAuto = "auto"
+	// transportGRPC calls gRPC server only
+	transportGRPC = "grpc"
+	// transportREST calls HTTP gateway only
+	transportREST = "rest"
+)
+
+// store is todo operations of commands, it is implemented by gRPC SDK client and REST gateway client
+type store interface {
+	Create(ctx context.Context, t client.Todo) (int64, error)
+	Get(ctx context.Context, id int64) (client.Todo, error)
+	List(ctx context.Context) ([]client.Todo, error)
+	Update(ctx context.Context, t client.Todo) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// openStore returns store of transport of s, close releases its connection
+func openStore(s *settings) (st store, close func(), err error) {
+	tlsConfig, err := loadTLS(s)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rest := newRESTClient(s.RESTServer, s.HMACSecret, tlsConfig)
+	if s.Transport == transportREST {
+		return rest, func() {}, nil
+	}
+
+	// calls are limited by context of command, so they are not limited
+	c, err := dialClient(s, tlsConfig, client.WithTimeout(0))
+	if err != nil {
+		return nil, nil, err
+	}
+	close = func() { c.Close() }
+
+	if s.Transport == transportGRPC {
+		return c, close, nil
+	}
+	return &fallback{primary: c, secondary: rest}, close, nil
+}
+
+// loadTLS returns TLS configuration of s if it is used by gRPC server or HTTP gateway connection, nil otherwise
+func loadTLS(s *settings) (*tls.Config, error) {
+	if !s.TLS && !strings.HasPrefix(s.RESTServer, "https:") {
+		return nil, nil
+	}
+	cfg, err := s.TLSConfig.Load()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %v", err)
+	}
+	return cfg, nil
+}
+
+// dialClient connects SDK client to gRPC server of s, opts are applied after settings of s
+func dialClient(s *settings, tlsConfig *tls.Config, opts ...client.Option) (*client.Client, error) {
+	o := []client.Option{client.WithBalancer(s.Balancer)}
+	if s.TLS {
+		o = append(o, client.WithTLS(tlsConfig))
+	}
+	if s.KeepaliveTime > 0 {
+		o = append(o, client.WithKeepalive(s.KeepaliveTime, s.KeepaliveTimeout))
+	}
+	if len(s.HMACSecret) > 0 {
+		o = append(o, client.WithHMACSecret(s.HMACSecret))
+	}
+	return client.Dial(s.Server, append(o, opts...)...)
+}
+
+// fallback calls secondary store when primary is unavailable, error of primary is returned if both fail
+type fallback struct {
+	primary   store
+	secondary store
+}
+
+// unavailable reports whether store failed with err is unavailable, so call is retried on secondary
+func unavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+func (f *fallback) Create(ctx context.Context, t client.Todo) (int64, error) {
+	id, err := f.primary.Create(ctx, t)
+	if unavailable(err) {
+		if id, err2 := f.secondary.Create(ctx, t); err2 == nil {
+			return id, nil
+		}
+	}
+	return id, err
+}
+
+func (f *fallback) Get(ctx context.Context, id int64) (client.Todo, error) {
+	t, err := f.primary.Get(ctx, id)
+	if unavailable(err) {
+		if t, err2 := f.secondary.Get(ctx, id); err2 == nil {
+			return t, nil
+		}
+	}
+	return t, err
+}
+
+func (f *fallback) List(ctx context.Context) ([]client.Todo, error) {
+	todos, err := f.primary.List(ctx)
+	if unavailable(err) {
+		if todos, err2 := f.secondary.List(ctx); err2 == nil {
+			return todos, nil
+		}
+	}
+	return todos, err
+}
+
+func (f *fallback) Update(ctx context.Context, t client.Todo) error {
+	err := f.primary.Update(ctx, t)
+	if unavailable(err) && f.secondary.Update(ctx, t) == nil {
+		return nil
+	}
+	return err
+}
+
+func (f *fallback) Delete(ctx context.Context, id int64) error {
+	err := f.primary.Delete(ctx, id)
+	if unavailable(err) && f.secondary.Delete(ctx, id) == nil {
+		return nil
+	}
+	return err
+}
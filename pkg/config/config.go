@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+// Options names flags locating configuration sources, sources are not used if their flag name is empty
+// or the flag is not set
+type Options struct {
+	// FileFlag is flag holding path of YAML config file
+	FileFlag string
+	// RemoteFlag is flag holding URL of remote key/value store, see NewRemote
+	RemoteFlag string
+	// CommandLineOnly are flags only read from command line args, e.g. -version, they are not read
+	// from environment, remote store or config file
+	CommandLineOnly []string
+	// RemoteValues are used instead of reading remote store named by RemoteFlag if they are not nil,
+	// e.g. values returned by Remote.Watch
+	RemoteValues map[string]string
+}
+
+// commandLineOnly reports whether flag name is only read from command line args
+func (o Options) commandLineOnly(name string) bool {
+	for _, n := range o.CommandLineOnly {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Load sets flags of fs from all configuration sources, earlier sources take precedence:
+//  1. command line args
+//  2. environment variables named after flags, e.g. GRPC_PORT for -grpc-port, except o.CommandLineOnly
+//  3. variables of DotenvFile in working directory, if it exists
+//  4. remote key/value store named by flag o.RemoteFlag
+//  5. YAML config file named by flag o.FileFlag
+//  6. defaults of flags
+func Load(fs *flag.FlagSet, args []string, o Options) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := loadDotenv(DotenvFile); err != nil {
+		return fmt.Errorf("Failed to read %s file: %v", DotenvFile, err)
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	if err := setFromEnv(fs, set, o); err != nil {
+		return err
+	}
+
+	remoteValues := o.RemoteValues
+	if u := flagValue(fs, o.RemoteFlag); len(u) > 0 && remoteValues == nil {
+		remote, err := NewRemote(u)
+		if err != nil {
+			return fmt.Errorf("Failed to create remote config store: %v", err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		remoteValues, err = remote.Get(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to read remote config store: %v", err)
+		}
+	}
+	if err := setValues(fs, remoteValues, set, "remote config store", o); err != nil {
+		return err
+	}
+
+	if path := flagValue(fs, o.FileFlag); len(path) > 0 {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("Failed to read config file: %v", err)
+		}
+		if err := setValues(fs, values, set, "config file", o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// flagValue returns value of flag name, it is empty if name is empty
+func flagValue(fs *flag.FlagSet, name string) string {
+	if len(name) == 0 {
+		return ""
+	}
+	return fs.Lookup(name).Value.String()
+}
+
+// setValues sets flags of fs not set yet to values read from source, names of flags it sets are added to set
+func setValues(fs *flag.FlagSet, values map[string]string, set map[string]bool, source string, o Options) error {
+	for name, value := range values {
+		if fs.Lookup(name) == nil || name == o.FileFlag || name == o.RemoteFlag || o.commandLineOnly(name) {
+			return fmt.Errorf("unknown setting in %s: '%s'", source, name)
+		}
+		if set[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("invalid value of setting '%s' in %s: %v", name, source, err)
+		}
+		set[name] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// settings are flags of test flag set, each is set by sources up to the one that must win
+var settings = []string{"from-args", "from-env", "from-dotenv", "from-remote", "from-file", "from-default"}
+
+// newTestFlagSet returns flag set with settings, workers, config and version flags
+func newTestFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	for _, name := range settings {
+		fs.String(name, "default", "")
+	}
+	fs.Int("workers", 1, "")
+	fs.String("config", "", "")
+	fs.Bool("version", false, "")
+	return fs
+}
+
+// inTempDir runs test in empty working directory, so .env is read from it
+func inTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd failed: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir failed: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+	return dir
+}
+
+// writeFile writes content to file name in dir, it returns path of the file
+func writeFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// unsetEnv removes variables set by .env when test finishes
+func unsetEnv(t *testing.T, names ...string) {
+	t.Cleanup(func() {
+		for _, name := range names {
+			_ = os.Unsetenv(name)
+		}
+	})
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	dir := inTempDir(t)
+	unsetEnv(t, "FROM_ARGS", "FROM_ENV", "FROM_DOTENV")
+
+	t.Setenv("FROM_ARGS", "env")
+	t.Setenv("FROM_ENV", "env")
+	writeFile(t, dir, DotenvFile, "FROM_ARGS=dotenv\nFROM_ENV=dotenv\nFROM_DOTENV=dotenv\n")
+	path := writeFile(t, dir, "config.yaml", "from:\n  args: file\n  env: file\n  dotenv: file\n  remote: file\n  file: file\n")
+	remote := map[string]string{"from-args": "remote", "from-env": "remote", "from-dotenv": "remote", "from-remote": "remote"}
+
+	fs := newTestFlagSet()
+	if err := Load(fs, []string{"-from-args", "args", "-config", path}, Options{FileFlag: "config", RemoteValues: remote}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	for _, name := range settings {
+		want := strings.TrimPrefix(name, "from-")
+		if got := fs.Lookup(name).Value.String(); got != want {
+			t.Errorf("-%s is '%s', '%s' expected", name, got, want)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		remote map[string]string
+		want   string
+	}{
+		{"unknown setting in file", "unknown: 1\n", nil, "unknown setting in config file: 'unknown'"},
+		{"unknown setting in remote", "", map[string]string{"unknown": "1"}, "unknown setting in remote config store: 'unknown'"},
+		{"command line only setting in file", "version: true\n", nil, "unknown setting in config file: 'version'"},
+		{"config file in file", "config: other.yaml\n", nil, "unknown setting in config file: 'config'"},
+		{"command line only setting in remote", "", map[string]string{"version": "true"}, "unknown setting in remote config store: 'version'"},
+		{"invalid value", "workers: many\n", nil, "invalid value of setting 'workers' in config file: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := inTempDir(t)
+			path := writeFile(t, dir, "config.yaml", tt.file)
+
+			o := Options{FileFlag: "config", CommandLineOnly: []string{"version"}, RemoteValues: tt.remote}
+			err := Load(newTestFlagSet(), []string{"-config", path}, o)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Fatalf("Load returned %v, '%s...' expected", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadCommandLineOnlyFromEnv(t *testing.T) {
+	inTempDir(t)
+	t.Setenv("VERSION", "true")
+
+	fs := newTestFlagSet()
+	if err := Load(fs, nil, Options{CommandLineOnly: []string{"version"}}); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if got := fs.Lookup("version").Value.String(); got != "false" {
+		t.Fatalf("-version is '%s', it must not be read from environment", got)
+	}
+}
+
+func TestReadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", "grpc-port: 9090\ntls:\n  cert-file: cert.pem\n  reload:\n    interval: 1m\nkafka-brokers:\n  - a:9092\n  - b:9092\nempty:\n")
+
+	values, err := readConfigFile(path)
+	if err != nil {
+		t.Fatalf("readConfigFile failed: %v", err)
+	}
+	want := map[string]string{
+		"grpc-port":           "9090",
+		"tls-cert-file":       "cert.pem",
+		"tls-reload-interval": "1m",
+		"kafka-brokers":       "a:9092,b:9092",
+		"empty":               "",
+	}
+	if len(values) != len(want) {
+		t.Fatalf("readConfigFile returned %v, %v expected", values, want)
+	}
+	for k, v := range want {
+		if got, ok := values[k]; !ok || got != v {
+			t.Errorf("value of %s is '%s', '%s' expected", k, got, v)
+		}
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	dir := inTempDir(t)
+	unsetEnv(t, "DOTENV_PLAIN", "DOTENV_DOUBLE", "DOTENV_SINGLE", "DOTENV_EXPORTED", "DOTENV_SET")
+	t.Setenv("DOTENV_SET", "env")
+
+	writeFile(t, dir, DotenvFile, "# comment\n\nDOTENV_PLAIN = plain\nDOTENV_DOUBLE=\"a\\tb\"\nDOTENV_SINGLE='a\\tb'\nexport DOTENV_EXPORTED=exported\nDOTENV_SET=dotenv\n")
+	if err := loadDotenv(DotenvFile); err != nil {
+		t.Fatalf("loadDotenv failed: %v", err)
+	}
+
+	want := map[string]string{
+		"DOTENV_PLAIN":    "plain",
+		"DOTENV_DOUBLE":   "a\tb",
+		"DOTENV_SINGLE":   `a\tb`,
+		"DOTENV_EXPORTED": "exported",
+		"DOTENV_SET":      "env",
+	}
+	for k, v := range want {
+		if got := os.Getenv(k); got != v {
+			t.Errorf("%s is '%s', '%s' expected", k, got, v)
+		}
+	}
+}
+
+func TestEffective(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("db-password", "password", "")
+	fs.String("auth-hmac-secret", "", "")
+	fs.String("alert-webhook-url", "https://hooks.example.com/token", "")
+	fs.String("grpc-port", "9090", "")
+
+	want := map[string]string{
+		"db-password":       redactedValue,
+		"auth-hmac-secret":  "",
+		"alert-webhook-url": redactedValue,
+		"grpc-port":         "9090",
+	}
+	values := Effective(fs)
+	for k, v := range want {
+		if got := values[k]; got != v {
+			t.Errorf("%s is '%s', '%s' expected", k, got, v)
+		}
+	}
+}
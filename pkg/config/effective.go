@@ -0,0 +1,55 @@
+package config
+
+import (
+	"flag"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// redactedValue replaces values of secret settings
+const redactedValue = "[REDACTED]"
+
+// secretWords are parts of names of settings holding secrets, URLs of incoming webhooks carry their credentials
+var secretWords = []string{"password", "secret", "dsn", "token", "private-key", "webhook-url"}
+
+// secret reports whether setting name holds secret
+func secret(name string) bool {
+	for _, w := range secretWords {
+		if strings.Contains(name, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Effective returns values of all flags of fs by their names, set values of secrets are redacted
+func Effective(fs *flag.FlagSet) map[string]string {
+	values := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		v := f.Value.String()
+		if len(v) > 0 && secret(f.Name) {
+			v = redactedValue
+		}
+		values[f.Name] = v
+	})
+
+	return values
+}
+
+// WriteYAML writes effective values of flags of fs except excluded ones to w as YAML with sorted keys,
+// output can be used as config file
+func WriteYAML(w io.Writer, fs *flag.FlagSet, excluded ...string) error {
+	values := Effective(fs)
+	for _, name := range excluded {
+		delete(values, name)
+	}
+
+	b, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
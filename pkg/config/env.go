@@ -0,0 +1,35 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envName returns name of environment variable of flag, e.g. GRPC_PORT for -grpc-port
+func envName(flagName string) string {
+	return strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// setFromEnv sets flags of fs that are not set yet to values of their environment variables,
+// names of flags it sets are added to set. Flags o.CommandLineOnly are skipped.
+func setFromEnv(fs *flag.FlagSet, set map[string]bool, o Options) error {
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if o.commandLineOnly(f.Name) {
+			return
+		}
+		v, ok := os.LookupEnv(envName(f.Name))
+		if !ok || set[f.Name] || err != nil {
+			return
+		}
+		if e := fs.Set(f.Name, v); e != nil {
+			err = fmt.Errorf("invalid value of environment variable %s: %v", envName(f.Name), e)
+			return
+		}
+		set[f.Name] = true
+	})
+
+	return err
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// readConfigFile reads YAML config file into values of flags. Keys of nested sections are joined with dashes,
+// e.g. key cert-file of section tls sets -tls-cert-file, lists are joined with commas.
+func readConfigFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[interface{}]interface{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	flatten("", doc, values)
+	return values, nil
+}
+
+// flatten adds values of section to values, their keys are prefixed by prefix
+func flatten(prefix string, section map[interface{}]interface{}, values map[string]string) {
+	for k, v := range section {
+		key := fmt.Sprint(k)
+		if len(prefix) > 0 {
+			key = prefix + "-" + key
+		}
+
+		switch v := v.(type) {
+		case map[interface{}]interface{}:
+			flatten(key, v, values)
+		case []interface{}:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
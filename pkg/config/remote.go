@@ -0,0 +1,219 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Remote is key/value store settings are shared by all replicas in. Keys are flag names under prefix,
+// slashes of nested keys are joined with dashes, e.g. prefix/grpc/port sets -grpc-port.
+type Remote interface {
+	// Get returns current values of settings
+	Get(ctx context.Context) (map[string]string, error)
+	// Watch blocks until values of settings differ from last values returned, then it returns them
+	Watch(ctx context.Context) (map[string]string, error)
+}
+
+// NewRemote creates Remote from URL of store and prefix of keys, e.g. consul://localhost:8500/todo/config
+// or etcd://localhost:2379/todo/config
+func NewRemote(rawURL string) (Remote, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "consul":
+		return &consul{address: "http://" + u.Host, prefix: prefix}, nil
+	case "etcd":
+		return &etcd{address: "http://" + u.Host, prefix: prefix, interval: 10 * time.Second}, nil
+	default:
+		return nil, fmt.Errorf("unsupported remote config store '%s', consul or etcd expected", u.Scheme)
+	}
+}
+
+// settingName returns flag name of key relative to prefix
+func settingName(prefix, key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, prefix), "/")
+	return strings.ReplaceAll(key, "/", "-")
+}
+
+// httpClient is client remote stores are called with, blocking queries of Consul wait up to 5 minutes
+var httpClient = &http.Client{Timeout: 6 * time.Minute}
+
+// consul reads settings from Consul KV and watches them with blocking queries
+type consul struct {
+	address string
+	prefix  string
+
+	index  uint64
+	values map[string]string
+}
+
+// Get returns current values of settings
+func (c *consul) Get(ctx context.Context) (map[string]string, error) {
+	return c.query(ctx, 0)
+}
+
+// Watch blocks until values of settings change
+func (c *consul) Watch(ctx context.Context) (map[string]string, error) {
+	last := c.values
+	for {
+		values, err := c.query(ctx, c.index)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(values, last) {
+			return values, nil
+		}
+	}
+}
+
+// query reads keys under prefix, it blocks until index of Consul is greater than index if it is not 0
+func (c *consul) query(ctx context.Context, index uint64) (map[string]string, error) {
+	u := c.address + "/v1/kv/" + c.prefix + "?recurse=true"
+	if index > 0 {
+		u += "&wait=5m&index=" + strconv.FormatUint(index, 10)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var pairs []struct {
+		Key   string
+		Value []byte
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&pairs); err != nil {
+			return nil, fmt.Errorf("invalid response of Consul: %v", err)
+		}
+	case http.StatusNotFound:
+		// there are no keys under prefix
+	default:
+		return nil, fmt.Errorf("Consul responded with status %s", resp.Status)
+	}
+
+	values := map[string]string{}
+	for _, p := range pairs {
+		if name := settingName(c.prefix, p.Key); len(name) > 0 && !strings.HasSuffix(p.Key, "/") {
+			values[name] = string(p.Value)
+		}
+	}
+
+	// index is reset if it goes backwards, e.g. after Consul is restored from snapshot
+	c.index, _ = strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if c.index < index {
+		c.index = 0
+	}
+	c.values = values
+	return values, nil
+}
+
+// etcd reads settings from etcd v3 over its JSON gateway and polls them every interval
+type etcd struct {
+	address  string
+	prefix   string
+	interval time.Duration
+
+	values map[string]string
+}
+
+// Get returns current values of settings
+func (e *etcd) Get(ctx context.Context) (map[string]string, error) {
+	values, err := e.rangePrefix(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.values = values
+	return values, nil
+}
+
+// Watch polls settings until they change
+func (e *etcd) Watch(ctx context.Context) (map[string]string, error) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+
+		values, err := e.rangePrefix(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(values, e.values) {
+			e.values = values
+			return values, nil
+		}
+	}
+}
+
+// rangePrefix reads all keys under prefix
+func (e *etcd) rangePrefix(ctx context.Context) (map[string]string, error) {
+	key := []byte(e.prefix + "/")
+	// range end is prefix with last byte incremented, so all keys starting with prefix are read
+	end := append([]byte{}, key...)
+	end[len(end)-1]++
+
+	body, err := json.Marshal(map[string]string{
+		"key":       base64.StdEncoding.EncodeToString(key),
+		"range_end": base64.StdEncoding.EncodeToString(end),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.address+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("etcd responded with status %s", resp.Status)
+	}
+
+	var result struct {
+		Kvs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid response of etcd: %v", err)
+	}
+
+	values := map[string]string{}
+	for _, kv := range result.Kvs {
+		if name := settingName(e.prefix, string(kv.Key)); len(name) > 0 {
+			values[name] = string(kv.Value)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,81 @@
+package errorrate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/webhook"
+	"go.uber.org/zap"
+)
+
+// webhookTimeout is maximum duration of alert webhook request
+const webhookTimeout = 10 * time.Second
+
+// WebhookHook returns hook that posts events as JSON to url, requests are signed with secret if it is not empty.
+// Requests are sent in background, failures are logged to log.
+func WebhookHook(url, secret string, log *zap.Logger) Hook {
+	log = logger.OrNop(log)
+	client := &http.Client{Timeout: webhookTimeout}
+
+	return func(e Event) {
+		go func() {
+			if err := postEvent(client, url, secret, e); err != nil {
+				log.Error("Failed to send alert webhook",
+					zap.String("dependency", e.Dependency),
+					zap.String("reason", err.Error()),
+				)
+			}
+		}()
+	}
+}
+
+// postEvent sends event to url
+func postEvent(client *http.Client, url, secret string, e Event) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(secret) > 0 {
+		webhook.SignRequest(req, secret, time.Now(), payload)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// LogHook returns hook that logs events
+func LogHook(log *zap.Logger) Hook {
+	log = logger.OrNop(log)
+
+	return func(e Event) {
+		fields := []zap.Field{
+			zap.String("dependency", e.Dependency),
+			zap.Float64("error-rate", e.Rate),
+			zap.Int("requests", e.Requests),
+		}
+		if e.Tripped {
+			log.Error("Dependency error rate exceeded threshold", fields...)
+			return
+		}
+		log.Info("Dependency error rate recovered", fields...)
+	}
+}
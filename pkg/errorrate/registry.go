@@ -0,0 +1,102 @@
+package errorrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is change of dependency state
+type Event struct {
+	// Dependency is name of the dependency
+	Dependency string `json:"dependency"`
+	// Rate is error rate in the window at the time of the change
+	Rate float64 `json:"rate"`
+	// Requests is number of requests in the window at the time of the change
+	Requests int `json:"requests"`
+	// Tripped is true if error rate exceeded threshold, false if dependency recovered
+	Tripped bool `json:"tripped"`
+	// Time is time of the change
+	Time time.Time `json:"time"`
+}
+
+// Hook is called when dependency is tripped or recovers, it must not block for long
+type Hook func(Event)
+
+// Registry keeps trackers of all dependencies of the server and hooks notified about their state
+type Registry struct {
+	mu       sync.RWMutex
+	trackers map[string]*Tracker
+	hooks    []Hook
+}
+
+// NewRegistry creates empty Registry
+func NewRegistry() *Registry {
+	return &Registry{trackers: map[string]*Tracker{}}
+}
+
+// defaultWindow is rolling window used if Options.Window is not set
+const defaultWindow = time.Minute
+
+// Register adds tracker of dependency, existing tracker is returned if name is already registered
+func (r *Registry) Register(name string, o Options) *Tracker {
+	if o.Window < bucketCount {
+		o.Window = defaultWindow
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.trackers[name]; ok {
+		return t
+	}
+
+	t := &Tracker{name: name, o: o, notify: r.notify}
+	r.trackers[name] = t
+	return t
+}
+
+// OnChange adds hook called when any dependency is tripped or recovers
+func (r *Registry) OnChange(h Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, h)
+}
+
+// notify calls all hooks with event
+func (r *Registry) notify(e Event) {
+	r.mu.RLock()
+	hooks := r.hooks
+	r.mu.RUnlock()
+
+	for _, h := range hooks {
+		h(e)
+	}
+}
+
+// Check returns error naming tripped dependencies, it can be used as readiness check
+func (r *Registry) Check(context.Context) error {
+	r.mu.RLock()
+	trackers := make([]*Tracker, 0, len(r.trackers))
+	for _, t := range r.trackers {
+		trackers = append(trackers, t)
+	}
+	r.mu.RUnlock()
+
+	var tripped []string
+	for _, t := range trackers {
+		if t.Tripped() {
+			tripped = append(tripped, t.Name())
+		}
+	}
+	if len(tripped) == 0 {
+		return nil
+	}
+
+	sort.Strings(tripped)
+	return fmt.Errorf("error rate threshold exceeded by: %s", strings.Join(tripped, ", "))
+}
@@ -0,0 +1,135 @@
+package errorrate
+
+import (
+	"sync"
+	"time"
+)
+
+// bucketCount is number of buckets rolling window is split into
+const bucketCount = 10
+
+// Options configures when dependency is considered failing
+type Options struct {
+	// Window is duration of rolling window error rate is computed over
+	Window time.Duration
+	// Threshold is error rate (0..1) dependency is tripped at
+	Threshold float64
+	// MinRequests is number of requests in the window required before dependency can be tripped
+	MinRequests int
+}
+
+// bucket counts requests of one slice of the window
+type bucket struct {
+	start  time.Time
+	total  int
+	failed int
+}
+
+// Tracker tracks rolling error rate of requests to one dependency, e.g. database
+type Tracker struct {
+	name   string
+	o      Options
+	notify func(Event)
+
+	mu      sync.Mutex
+	buckets [bucketCount]bucket
+	tripped bool
+}
+
+// Name returns name of the dependency
+func (t *Tracker) Name() string {
+	return t.name
+}
+
+// Record records result of request to the dependency, nil err means success
+func (t *Tracker) Record(err error) {
+	now := time.Now()
+
+	t.mu.Lock()
+	b := t.bucket(now)
+	b.total++
+	if err != nil {
+		b.failed++
+	}
+	e, changed := t.evaluate(now)
+	t.mu.Unlock()
+
+	if changed {
+		t.notify(e)
+	}
+}
+
+// Tripped reports whether error rate of the dependency exceeds threshold.
+// Dependency recovers once enough failed requests leave the window.
+func (t *Tracker) Tripped() bool {
+	now := time.Now()
+
+	t.mu.Lock()
+	e, changed := t.evaluate(now)
+	tripped := t.tripped
+	t.mu.Unlock()
+
+	if changed {
+		t.notify(e)
+	}
+	return tripped
+}
+
+// Rate returns error rate and number of requests in the current window
+func (t *Tracker) Rate() (float64, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.rate(time.Now())
+}
+
+// bucketDuration returns duration of single bucket
+func (t *Tracker) bucketDuration() time.Duration {
+	return t.o.Window / bucketCount
+}
+
+// bucket returns bucket of time now, the bucket is reset if it holds older slice of time
+func (t *Tracker) bucket(now time.Time) *bucket {
+	d := t.bucketDuration()
+	start := now.Truncate(d)
+
+	b := &t.buckets[(now.UnixNano()/int64(d))%bucketCount]
+	if !b.start.Equal(start) {
+		*b = bucket{start: start}
+	}
+	return b
+}
+
+// rate returns error rate and number of requests of buckets inside the window
+func (t *Tracker) rate(now time.Time) (float64, int) {
+	var total, failed int
+	for _, b := range t.buckets {
+		if now.Sub(b.start) < t.o.Window {
+			total += b.total
+			failed += b.failed
+		}
+	}
+
+	if total == 0 {
+		return 0, 0
+	}
+	return float64(failed) / float64(total), total
+}
+
+// evaluate updates tripped state, it returns event to notify if the state is changed
+func (t *Tracker) evaluate(now time.Time) (Event, bool) {
+	rate, total := t.rate(now)
+	tripped := total >= t.o.MinRequests && rate >= t.o.Threshold
+	if tripped == t.tripped {
+		return Event{}, false
+	}
+
+	t.tripped = tripped
+	return Event{
+		Dependency: t.name,
+		Rate:       rate,
+		Requests:   total,
+		Tripped:    tripped,
+		Time:       now,
+	}, true
+}
@@ -0,0 +1,27 @@
+package errorreport
+
+import (
+	"context"
+)
+
+// Event is failure reported to error tracking service
+type Event struct {
+	// Err is error returned by handler, it is nil for panics
+	Err error
+	// Panic is value handler panicked with, it is nil for errors
+	Panic interface{}
+	// Protocol is "grpc" or "http"
+	Protocol string
+	// Method is gRPC full method name or HTTP method and path
+	Method string
+	// RequestID is id of the request assigned by HTTP gateway
+	RequestID string
+	// Context is sanitized request context, sensitive values must be removed by caller
+	Context map[string]interface{}
+}
+
+// Reporter sends panics and server side errors to error tracking service
+type Reporter interface {
+	// Report sends event, it must not block the request for long
+	Report(ctx context.Context, e Event)
+}
@@ -0,0 +1,56 @@
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+// Sentry reports events to Sentry
+type Sentry struct {
+	hub *sentry.Hub
+}
+
+// NewSentry creates Sentry reporter for project identified by DSN
+func NewSentry(dsn, environment string) (*Sentry, error) {
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:              dsn,
+		Environment:      environment,
+		AttachStacktrace: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sentry{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// Report sends event to Sentry, events are delivered in background
+func (s *Sentry) Report(ctx context.Context, e Event) {
+	hub := s.hub.Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("protocol", e.Protocol)
+		scope.SetTag("method", e.Method)
+		if len(e.RequestID) > 0 {
+			scope.SetTag("request_id", e.RequestID)
+		}
+		scope.SetExtras(e.Context)
+
+		if e.Panic != nil {
+			hub.RecoverWithContext(ctx, e.Panic)
+			return
+		}
+		if e.Err != nil {
+			hub.CaptureException(e.Err)
+			return
+		}
+		hub.CaptureMessage(fmt.Sprintf("%s %s failed", e.Protocol, e.Method))
+	})
+}
+
+// Flush waits until buffered events are sent or timeout expires
+func (s *Sentry) Flush(timeout time.Duration) bool {
+	return s.hub.Flush(timeout)
+}
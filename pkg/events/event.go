@@ -0,0 +1,118 @@
+package events
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Type is type of domain event
+type Type string
+
+const (
+	// TodoCreated is published when todo is created
+	TodoCreated Type = "TodoCreated"
+	// TodoUpdated is published when todo is updated
+	TodoUpdated Type = "TodoUpdated"
+	// TodoDeleted is published when todo is deleted, only ID of the todo is sent
+	TodoDeleted Type = "TodoDeleted"
+)
+
+const (
+	// EncodingJSON encodes whole event as JSON
+	EncodingJSON = "json"
+	// EncodingProtobuf encodes todo as v1.Todo protobuf message, other fields of event are sent as headers
+	EncodingProtobuf = "protobuf"
+)
+
+// Header names of event fields sent next to payload, e.g. as Kafka headers
+const (
+	HeaderID          = "event-id"
+	HeaderType        = "event-type"
+	HeaderTime        = "event-time"
+	HeaderContentType = "content-type"
+)
+
+// Event is change of todo other systems can react to
+type Event struct {
+	// ID is unique ID of the event, consumers can use it to skip duplicates
+	ID string `json:"id"`
+	// Type is type of the change
+	Type Type `json:"type"`
+	// Time is time the change was made
+	Time time.Time `json:"time"`
+	// Todo is todo after the change, only ID is set for TodoDeleted
+	Todo *v1.Todo `json:"-"`
+}
+
+// NewEvent returns event of type t of change of todo made now
+func NewEvent(t Type, todo *v1.Todo) (Event, error) {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return Event{}, fmt.Errorf("failed to generate event ID: %v", err)
+	}
+	return Event{ID: hex.EncodeToString(id[:]), Type: t, Time: time.Now().UTC(), Todo: todo}, nil
+}
+
+// jsonTodo is todo of JSON encoded event
+type jsonTodo struct {
+	ID          int64      `json:"id"`
+	Title       string     `json:"title,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Reminder    *time.Time `json:"reminder,omitempty"`
+}
+
+// MarshalJSON encodes event with todo
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	v := struct {
+		event
+		Todo *jsonTodo `json:"todo,omitempty"`
+	}{event: event(e)}
+	if e.Todo != nil {
+		v.Todo = &jsonTodo{ID: e.Todo.Id, Title: e.Todo.Title, Description: e.Todo.Description}
+		if e.Todo.Reminder != nil {
+			reminder, err := ptypes.Timestamp(e.Todo.Reminder)
+			if err != nil {
+				return nil, err
+			}
+			v.Todo.Reminder = &reminder
+		}
+	}
+	return json.Marshal(v)
+}
+
+// Headers returns event fields sent next to payload of encoding
+func (e Event) Headers(encoding string) map[string]string {
+	contentType := "application/json"
+	if encoding == EncodingProtobuf {
+		contentType = "application/x-protobuf"
+	}
+	return map[string]string{
+		HeaderID:          e.ID,
+		HeaderType:        string(e.Type),
+		HeaderTime:        e.Time.Format(time.RFC3339Nano),
+		HeaderContentType: contentType,
+	}
+}
+
+// Encode returns payload of event in encoding: EncodingJSON or EncodingProtobuf
+func (e Event) Encode(encoding string) ([]byte, error) {
+	switch encoding {
+	case EncodingJSON:
+		return json.Marshal(e)
+	case EncodingProtobuf:
+		todo := e.Todo
+		if todo == nil {
+			todo = &v1.Todo{}
+		}
+		return proto.Marshal(todo)
+	}
+	return nil, fmt.Errorf("invalid event encoding '%s', %s or %s expected", encoding, EncodingJSON, EncodingProtobuf)
+}
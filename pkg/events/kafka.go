@@ -0,0 +1,89 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// kafkaBatchTimeout is how long events are collected into batch before it is sent
+const kafkaBatchTimeout = 10 * time.Millisecond
+
+// KafkaConfig configures publishing of events to Kafka
+type KafkaConfig struct {
+	// Brokers are host:port addresses of Kafka brokers the cluster is discovered from
+	Brokers []string
+	// Topic is topic events are published to
+	Topic string
+	// Encoding is encoding of message values: EncodingJSON or EncodingProtobuf
+	Encoding string
+}
+
+// KafkaPublisher publishes events to Kafka topic, messages are keyed by todo ID,
+// so events of the same todo are kept in order in one partition
+type KafkaPublisher struct {
+	w        *kafka.Writer
+	encoding string
+}
+
+// NewKafkaPublisher returns publisher sending events to Kafka in background, failures are logged to log.
+// It must be closed to send buffered events.
+func NewKafkaPublisher(cfg KafkaConfig, log *zap.Logger) (*KafkaPublisher, error) {
+	// encoding is checked before anything is published
+	if _, err := (Event{}).Encode(cfg.Encoding); err != nil {
+		return nil, err
+	}
+
+	log = logger.OrNop(log)
+	return &KafkaPublisher{
+		w: &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.Topic,
+			Balancer:     &kafka.Hash{},
+			RequiredAcks: kafka.RequireAll,
+			BatchTimeout: kafkaBatchTimeout,
+			Async:        true,
+			Completion: func(messages []kafka.Message, err error) {
+				if err == nil {
+					return
+				}
+				for _, m := range messages {
+					log.Error("Failed to publish event to Kafka",
+						zap.String("topic", cfg.Topic),
+						zap.ByteString("key", m.Key),
+						zap.String("reason", err.Error()),
+					)
+				}
+			},
+		},
+		encoding: cfg.Encoding,
+	}, nil
+}
+
+// Publish queues event to be sent to Kafka
+func (p *KafkaPublisher) Publish(ctx context.Context, e Event) error {
+	value, err := e.Encode(p.encoding)
+	if err != nil {
+		return err
+	}
+
+	var headers []kafka.Header
+	for k, v := range e.Headers(p.encoding) {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+	var key []byte
+	if e.Todo != nil {
+		key = []byte(strconv.FormatInt(e.Todo.Id, 10))
+	}
+
+	return p.w.WriteMessages(ctx, kafka.Message{Key: key, Value: value, Headers: headers, Time: e.Time})
+}
+
+// Close sends buffered events and closes connections to brokers
+func (p *KafkaPublisher) Close() error {
+	return p.w.Close()
+}
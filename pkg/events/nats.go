@@ -0,0 +1,105 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+)
+
+// natsCloseTimeout is how long Close waits for acks of published events
+const natsCloseTimeout = 10 * time.Second
+
+// NATSConfig configures publishing of events to NATS JetStream
+type NATSConfig struct {
+	// URL is comma separated list of NATS server URLs, e.g. nats://localhost:4222
+	URL string
+	// Stream is JetStream stream events are stored in, it is created for Subject if it does not exist
+	Stream string
+	// Subject is subject events are published to
+	Subject string
+	// Encoding is encoding of message data: EncodingJSON or EncodingProtobuf
+	Encoding string
+}
+
+// NATSPublisher publishes events to NATS JetStream, event ID is sent as message ID,
+// so JetStream drops duplicates
+type NATSPublisher struct {
+	nc       *nats.Conn
+	js       nats.JetStreamContext
+	subject  string
+	encoding string
+}
+
+// NewNATSPublisher connects to NATS and returns publisher sending events in background, failures are logged to log.
+// It must be closed to wait for acks of published events.
+func NewNATSPublisher(cfg NATSConfig, log *zap.Logger) (*NATSPublisher, error) {
+	// encoding is checked before anything is published
+	if _, err := (Event{}).Encode(cfg.Encoding); err != nil {
+		return nil, err
+	}
+
+	log = logger.OrNop(log)
+	// connection is re-established without limit when it is lost
+	nc, err := nats.Connect(cfg.URL, nats.Name("todo-service"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %v", err)
+	}
+
+	js, err := nc.JetStream(nats.PublishAsyncErrHandler(func(_ nats.JetStream, m *nats.Msg, err error) {
+		log.Error("Failed to publish event to NATS",
+			zap.String("subject", m.Subject),
+			zap.String("event", m.Header.Get(nats.MsgIdHdr)),
+			zap.String("reason", err.Error()),
+		)
+	}))
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to get JetStream context: %v", err)
+	}
+
+	if _, err := js.StreamInfo(cfg.Stream); errors.Is(err, nats.ErrStreamNotFound) {
+		_, err = js.AddStream(&nats.StreamConfig{Name: cfg.Stream, Subjects: []string{cfg.Subject}})
+		if err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("failed to create JetStream stream '%s': %v", cfg.Stream, err)
+		}
+	} else if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to get JetStream stream '%s': %v", cfg.Stream, err)
+	}
+
+	return &NATSPublisher{nc: nc, js: js, subject: cfg.Subject, encoding: cfg.Encoding}, nil
+}
+
+// Publish sends event to JetStream, ack is not waited for
+func (p *NATSPublisher) Publish(ctx context.Context, e Event) error {
+	data, err := e.Encode(p.encoding)
+	if err != nil {
+		return err
+	}
+
+	m := nats.NewMsg(p.subject)
+	m.Data = data
+	for k, v := range e.Headers(p.encoding) {
+		m.Header.Set(k, v)
+	}
+	m.Header.Set(nats.MsgIdHdr, e.ID)
+
+	_, err = p.js.PublishMsgAsync(m)
+	return err
+}
+
+// Close waits for acks of published events and closes connection to NATS
+func (p *NATSPublisher) Close() error {
+	select {
+	case <-p.js.PublishAsyncComplete():
+	case <-time.After(natsCloseTimeout):
+	}
+	p.nc.Close()
+	return nil
+}
@@ -0,0 +1,280 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// outbox statuses stored in event_outbox table, published events are deleted
+	outboxPending = "PENDING"
+	outboxFailed  = "FAILED"
+
+	// maxOutboxErrorLen is maximum length of stored error of failed attempt
+	maxOutboxErrorLen = 1024
+
+	// maxOutboxBackoff is maximum delay between attempts
+	maxOutboxBackoff = 10 * time.Minute
+)
+
+// OutboxOptions configures relaying of events stored in outbox
+type OutboxOptions struct {
+	// Timeout is how long publishing of single event may take, event is leased for twice as long
+	Timeout time.Duration
+	// MaxAttempts is number of attempts after which event is kept in outbox as failed and not published
+	MaxAttempts int
+	// RetryBackoff is delay before second attempt, it is doubled for every next attempt up to ten minutes
+	RetryBackoff time.Duration
+	// BatchSize is maximum number of events relayed by single run of Relay
+	BatchSize int
+}
+
+// DefaultOutboxOptions are options used for zero fields of OutboxOptions
+var DefaultOutboxOptions = OutboxOptions{
+	Timeout:      10 * time.Second,
+	MaxAttempts:  10,
+	RetryBackoff: time.Second,
+	BatchSize:    100,
+}
+
+// Outbox stores events in event_outbox table in transaction of the todo change and relays them to publishers,
+// so events are not lost when publisher is unreachable or server stops right after the change.
+// Event is stored once for every publisher, so publishers are retried independently, and it is leased while
+// it is published, so servers relaying at the same time skip it. Event is published again only if server
+// stops or lease expires before it is deleted, consumers skip such duplicates by event ID.
+type Outbox struct {
+	db         *sql.DB
+	publishers map[string]Publisher
+	sinks      []string
+	o          OutboxOptions
+	log        *zap.Logger
+}
+
+// NewOutbox returns outbox of events stored in db and relayed to publishers, publishers maps name stored
+// with the event to publisher, so the name must stay the same between restarts
+func NewOutbox(db *sql.DB, publishers map[string]Publisher, o OutboxOptions, log *zap.Logger) *Outbox {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultOutboxOptions.Timeout
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultOutboxOptions.MaxAttempts
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = DefaultOutboxOptions.RetryBackoff
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultOutboxOptions.BatchSize
+	}
+
+	sinks := make([]string, 0, len(publishers))
+	for name := range publishers {
+		sinks = append(sinks, name)
+	}
+	sort.Strings(sinks)
+
+	return &Outbox{db: db, publishers: publishers, sinks: sinks, o: o, log: logger.OrNop(log)}
+}
+
+// Record stores event of change of todo td in transaction tx, it is v1.ChangeRecorder of SQL store
+func (o *Outbox) Record(ctx context.Context, tx *sql.Tx, kind v1.ChangeKind, td *v1.Todo) error {
+	var t Type
+	switch kind {
+	case v1.ChangeCreated:
+		t = TodoCreated
+	case v1.ChangeUpdated:
+		t = TodoUpdated
+	case v1.ChangeDeleted:
+		t = TodoDeleted
+	default:
+		return fmt.Errorf("unknown change kind %d", kind)
+	}
+
+	e, err := NewEvent(t, td)
+	if err != nil {
+		return err
+	}
+	todo, err := proto.Marshal(td)
+	if err != nil {
+		return fmt.Errorf("failed to encode todo: %v", err)
+	}
+
+	// event is due right away, next_attempt_at has no fractional seconds, so they are cut instead of rounded up
+	due := e.Time.Truncate(time.Second)
+	query := `INSERT INTO event_outbox(sink, event_id, event_type, event_time, todo, next_attempt_at) VALUES (?, ?, ?, ?, ?, ?)`
+	for _, sink := range o.sinks {
+		if _, err := tx.ExecContext(ctx, query, sink, e.ID, string(e.Type), e.Time, todo, due); err != nil {
+			return fmt.Errorf("failed to insert into event_outbox: %v", err)
+		}
+	}
+	return nil
+}
+
+// stored is event stored in outbox for publisher
+type stored struct {
+	id       int64
+	sink     string
+	attempts int
+	event    Event
+}
+
+// Relay publishes batch of due events in order they were recorded and deletes them, it is run by jobs.Runner
+// to poll for them. Event that fails to publish is retried with backoff, events recorded after it do not wait for it.
+func (o *Outbox) Relay(ctx context.Context) error {
+	if len(o.sinks) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	pending, err := o.due(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	var relayed int
+	for _, s := range pending {
+		// event is leased, so other servers skip it while it is published
+		claimed, err := o.claim(ctx, s.id, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		if o.publish(ctx, s) {
+			relayed++
+		}
+	}
+	if relayed > 0 {
+		o.log.Debug("Events relayed", zap.Int("count", relayed))
+	}
+
+	return nil
+}
+
+// due returns batch of events of known publishers due at now
+func (o *Outbox) due(ctx context.Context, now time.Time) ([]stored, error) {
+	args := []interface{}{outboxPending, now}
+	for _, sink := range o.sinks {
+		args = append(args, sink)
+	}
+	args = append(args, o.o.BatchSize)
+
+	query := `SELECT id, sink, attempts, event_id, event_type, event_time, todo FROM event_outbox
+		WHERE status = ? AND next_attempt_at <= ? AND sink IN (?` + strings.Repeat(", ?", len(o.sinks)-1) + `)
+		ORDER BY id LIMIT ?`
+	rows, err := o.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from event_outbox: %v", err)
+	}
+	defer rows.Close()
+
+	var pending []stored
+	for rows.Next() {
+		var (
+			s    stored
+			t    string
+			at   time.Time
+			todo []byte
+		)
+		if err := rows.Scan(&s.id, &s.sink, &s.attempts, &s.event.ID, &t, &at, &todo); err != nil {
+			return nil, fmt.Errorf("failed to retrieve field values from event_outbox: %v", err)
+		}
+		s.event.Type = Type(t)
+		s.event.Time = at.UTC()
+		s.event.Todo = &v1.Todo{}
+		if err := proto.Unmarshal(todo, s.event.Todo); err != nil {
+			return nil, fmt.Errorf("stored todo of event %s has invalid format: %v", s.event.ID, err)
+		}
+		pending = append(pending, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve data from event_outbox: %v", err)
+	}
+
+	return pending, nil
+}
+
+// claim leases due event until publishing times out, it reports whether event was leased
+func (o *Outbox) claim(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `UPDATE event_outbox SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?`
+	res, err := o.db.ExecContext(ctx, query, now.Add(2*o.o.Timeout), id, outboxPending, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim event_outbox: %v", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve rows affected value: %v", err)
+	}
+	return rows == 1, nil
+}
+
+// publish sends leased event to its publisher and deletes it, or stores failed attempt,
+// it reports whether event was published
+func (o *Outbox) publish(ctx context.Context, s stored) bool {
+	pctx, cancel := context.WithTimeout(ctx, o.o.Timeout)
+	err := o.publishers[s.sink].Publish(pctx, s.event)
+	cancel()
+
+	var (
+		query string
+		args  []interface{}
+	)
+	attempts := s.attempts + 1
+	switch {
+	case err == nil:
+		query = `DELETE FROM event_outbox WHERE id = ?`
+		args = []interface{}{s.id}
+	case attempts >= o.o.MaxAttempts:
+		o.log.Warn("Event relay failed",
+			zap.String("event", s.event.ID),
+			zap.String("sink", s.sink),
+			zap.Int("attempts", attempts),
+			zap.String("reason", err.Error()),
+		)
+		query = `UPDATE event_outbox SET status = ?, attempts = ?, last_error = ? WHERE id = ?`
+		args = []interface{}{outboxFailed, attempts, truncateError(err.Error()), s.id}
+	default:
+		next := time.Now().UTC().Add(o.backoff(attempts))
+		query = `UPDATE event_outbox SET attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?`
+		args = []interface{}{attempts, truncateError(err.Error()), next, s.id}
+	}
+
+	// result is stored even if server is stopping, so published event is not published again
+	if _, err := o.db.ExecContext(context.Background(), query, args...); err != nil {
+		o.log.Error("Failed to update event_outbox",
+			zap.String("event", s.event.ID),
+			zap.String("sink", s.sink),
+			zap.String("reason", err.Error()),
+		)
+	}
+	return err == nil
+}
+
+// backoff returns delay before next attempt after attempts failed ones
+func (o *Outbox) backoff(attempts int) time.Duration {
+	b := o.o.RetryBackoff
+	for i := 1; i < attempts && b < maxOutboxBackoff; i++ {
+		b *= 2
+	}
+	if b > maxOutboxBackoff {
+		b = maxOutboxBackoff
+	}
+	return b
+}
+
+// truncateError returns s cut to length stored in event_outbox
+func truncateError(s string) string {
+	if len(s) > maxOutboxErrorLen {
+		return s[:maxOutboxErrorLen]
+	}
+	return s
+}
@@ -0,0 +1,98 @@
+package events
+
+import (
+	"context"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+// Publisher sends events to other systems, e.g. message broker
+type Publisher interface {
+	// Publish sends event, it may return before event is delivered
+	Publish(ctx context.Context, e Event) error
+}
+
+// multiPublisher publishes events to several publishers
+type multiPublisher []Publisher
+
+// Multi returns publisher sending events to all of publishers, event is sent to all of them even if some fail
+func Multi(publishers ...Publisher) Publisher {
+	return multiPublisher(publishers)
+}
+
+// Publish sends event to all publishers, error of the first failed one is returned
+func (m multiPublisher) Publish(ctx context.Context, e Event) error {
+	var first error
+	for _, p := range m {
+		if err := p.Publish(ctx, e); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// publishingStore is v1.TodoStore publishing event of every change
+type publishingStore struct {
+	v1.TodoStore
+	publisher Publisher
+	log       *zap.Logger
+}
+
+// NewStore returns store publishing event to publisher after every successful change of todo in store.
+// Changes are not undone if publishing fails, failures are logged to log, so events are delivered at most once.
+// Outbox delivers them at least once for SQL store.
+func NewStore(store v1.TodoStore, publisher Publisher, log *zap.Logger) v1.TodoStore {
+	return &publishingStore{TodoStore: store, publisher: publisher, log: logger.OrNop(log)}
+}
+
+// Create creates todo and publishes TodoCreated
+func (s *publishingStore) Create(ctx context.Context, td *v1.Todo) (int64, error) {
+	id, err := s.TodoStore.Create(ctx, td)
+	if err != nil {
+		return id, err
+	}
+
+	created := proto.Clone(td).(*v1.Todo)
+	created.Id = id
+	s.publish(ctx, TodoCreated, created)
+	return id, nil
+}
+
+// Update updates todo and publishes TodoUpdated if it exists
+func (s *publishingStore) Update(ctx context.Context, td *v1.Todo) (int64, error) {
+	rows, err := s.TodoStore.Update(ctx, td)
+	if err != nil || rows == 0 {
+		return rows, err
+	}
+
+	s.publish(ctx, TodoUpdated, proto.Clone(td).(*v1.Todo))
+	return rows, nil
+}
+
+// Delete deletes todo and publishes TodoDeleted
+func (s *publishingStore) Delete(ctx context.Context, id int64) error {
+	if err := s.TodoStore.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.publish(ctx, TodoDeleted, &v1.Todo{Id: id})
+	return nil
+}
+
+// publish publishes event of type t of change of todo, failure is logged
+func (s *publishingStore) publish(ctx context.Context, t Type, todo *v1.Todo) {
+	e, err := NewEvent(t, todo)
+	if err == nil {
+		err = s.publisher.Publish(ctx, e)
+	}
+	if err != nil {
+		s.log.Error("Failed to publish event",
+			zap.String("type", string(t)),
+			zap.Int64("id", todo.Id),
+			zap.String("reason", err.Error()),
+		)
+	}
+}
@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/protocol/metrics"
+	"go.uber.org/zap"
+)
+
+// Func is single run of job, it should return soon after ctx is done
+type Func func(ctx context.Context) error
+
+// job is registered job
+type job struct {
+	name     string
+	schedule Schedule
+	run      Func
+}
+
+// Runner runs registered jobs on their schedules. Runs of the same job never overlap,
+// failures and panics of a run are logged and counted, they do not stop other jobs or next runs.
+type Runner struct {
+	mu      sync.Mutex
+	jobs    []job
+	started bool
+	log     *zap.Logger
+}
+
+// NewRunner returns runner logging failures of jobs to log
+func NewRunner(log *zap.Logger) *Runner {
+	return &Runner{log: logger.OrNop(log)}
+}
+
+// Register adds job run on schedule. Jobs of Every schedule first run right after Run is called,
+// others at the first time of their schedule. Jobs must be registered before Run.
+func (r *Runner) Register(name string, schedule Schedule, run Func) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.started {
+		panic(fmt.Sprintf("job %s is registered after runner is started", name))
+	}
+	r.jobs = append(r.jobs, job{name: name, schedule: schedule, run: run})
+}
+
+// Run runs jobs until ctx is done and then waits for running jobs to return
+func (r *Runner) Run(ctx context.Context) error {
+	r.mu.Lock()
+	r.started = true
+	jobs := r.jobs
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			r.loop(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// loop runs job on its schedule until ctx is done
+func (r *Runner) loop(ctx context.Context, j job) {
+	next := time.Now()
+	if _, ok := j.schedule.(every); !ok {
+		next = j.schedule.Next(next)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		start := time.Now()
+		r.runOnce(ctx, j)
+		next = j.schedule.Next(start)
+	}
+}
+
+// runOnce runs job once, panic is recovered, so it does not take the server down
+func (r *Runner) runOnce(ctx context.Context, j job) {
+	metrics.JobRunning.WithLabelValues(j.name).Set(1)
+	start := time.Now()
+
+	result := "success"
+	defer func() {
+		if p := recover(); p != nil {
+			result = "panic"
+			r.log.Error("Job panicked",
+				zap.String("job", j.name),
+				zap.Any("panic", p),
+				zap.ByteString("stack", debug.Stack()),
+			)
+		}
+
+		metrics.JobRunning.WithLabelValues(j.name).Set(0)
+		metrics.JobDuration.WithLabelValues(j.name).Observe(time.Since(start).Seconds())
+		metrics.JobRunsTotal.WithLabelValues(j.name, result).Inc()
+		if result == "success" {
+			metrics.JobLastSuccess.WithLabelValues(j.name).SetToCurrentTime()
+		}
+	}()
+
+	if err := j.run(ctx); err != nil {
+		result = "failure"
+		// run interrupted by shutdown is not failure worth logging
+		if ctx.Err() == nil {
+			r.log.Error("Job failed", zap.String("job", j.name), zap.String("reason", err.Error()))
+		}
+	}
+}
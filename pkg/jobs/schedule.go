@@ -0,0 +1,34 @@
+package jobs
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule returns time of the next run of job started at t
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// every is schedule of runs started at fixed interval
+type every time.Duration
+
+// Every returns schedule running job every d, runs taking longer than d are followed by next run immediately
+func Every(d time.Duration) Schedule {
+	return every(d)
+}
+
+func (e every) Next(t time.Time) time.Time {
+	return t.Add(time.Duration(e))
+}
+
+// Cron parses standard cron spec with five fields, e.g. "30 3 * * *", or descriptor, e.g. "@daily" or "@every 1h"
+func Cron(spec string) (Schedule, error) {
+	s, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron spec '%s': %v", spec, err)
+	}
+	return s, nil
+}
@@ -0,0 +1,60 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type ctxKeyLogger struct{}
+
+type ctxKeyRequestID struct{}
+
+// NewContext returns context carrying logger scoped to the request, it is returned by FromContext as is
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKeyLogger{}, l)
+}
+
+// WithRequestID returns context carrying request id, it is added to loggers returned by FromContext
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestID{}, id)
+}
+
+// FromContext returns logger scoped to the request injected by NewContext. If there is none,
+// it returns child of global logger with request id, trace id and span id of the request,
+// so all entries logged while handling one request can be correlated
+func FromContext(ctx context.Context) *zap.Logger {
+	if ctx == nil {
+		return OrNop(Log)
+	}
+
+	if l, ok := ctx.Value(ctxKeyLogger{}).(*zap.Logger); ok && l != nil {
+		return l
+	}
+	l := OrNop(Log)
+
+	var fields []zap.Field
+	if id, ok := ctx.Value(ctxKeyRequestID{}).(string); ok && len(id) > 0 {
+		fields = append(fields, zap.String("request-id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace-id", sc.TraceID().String()),
+			zap.String("span-id", sc.SpanID().String()),
+		)
+	}
+	if len(fields) == 0 {
+		return l
+	}
+
+	return l.With(fields...)
+}
+
+// OrNop returns l, or logger discarding all entries if l is nil
+func OrNop(l *zap.Logger) *zap.Logger {
+	if l == nil {
+		return zap.NewNop()
+	}
+	return l
+}
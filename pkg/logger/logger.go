@@ -7,61 +7,160 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 var (
-	// Log is global logger
+	// Log is global logger, it is set by Init. Servers and services get their logger
+	// injected and use Log only if it is not provided.
 	Log *zap.Logger
 
-	// timeFormat is custom Time format
-	customTimeFormat string
+	// Level is level of Log, it can be changed at runtime
+	Level = zap.NewAtomicLevel()
 
 	// onceInit guarantee initialize logger only once
 	onceInit sync.Once
 )
 
-func customTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-	enc.AppendString(t.Format(customTimeFormat))
+// Log formats
+const (
+	// FormatJSON writes every entry as JSON object
+	FormatJSON = "json"
+	// FormatConsole writes human readable entries with colored levels, it is meant for local development
+	FormatConsole = "console"
+)
+
+// Config is configuration for logger
+type Config struct {
+	// Level is global log level: Debug(-1), Info(0), Warn(1), Error(2), DPanic(3), Panic(4), Fatal(5)
+	Level int
+	// TimeFormat is custom time format, zap default is used if empty
+	TimeFormat string
+	// Format is format of console output: FormatJSON (default) or FormatConsole,
+	// files are always written as JSON
+	Format string
+
+	// SamplingInitial is number of log entries with the same level and message logged every second,
+	// sampling is disabled if it is 0
+	SamplingInitial int
+	// SamplingThereafter is how often entries are logged after SamplingInitial is exceeded,
+	// e.g. 100 logs every 100th entry
+	SamplingThereafter int
+
+	// File is path of file entries below Error level are also written to, not used if empty
+	File string
+	// ErrorFile is path of file entries of Error level and above are also written to, not used if empty
+	ErrorFile string
+	// FileMaxSize is size in megabytes log file is rotated at
+	FileMaxSize int
+	// FileMaxAge is number of days rotated log files are kept, they are not removed by age if 0
+	FileMaxAge int
+	// FileMaxBackups is number of rotated log files kept, all are kept if 0
+	FileMaxBackups int
+	// FileCompress turns on gzip compression of rotated log files
+	FileCompress bool
 }
 
-func Init(lvl int, timeFormat string) error {
+// customTimeEncoder returns encoder writing time in custom format
+func customTimeEncoder(format string) zapcore.TimeEncoder {
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.Format(format))
+	}
+}
+
+// fileSink returns log file output rotated by size and age
+func fileSink(cfg Config, path string) zapcore.WriteSyncer {
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    cfg.FileMaxSize,
+		MaxAge:     cfg.FileMaxAge,
+		MaxBackups: cfg.FileMaxBackups,
+		Compress:   cfg.FileCompress,
+	})
+}
+
+// New creates logger writing to console and configured files
+func New(cfg Config) (*zap.Logger, error) {
+	return newLogger(cfg, zap.NewAtomicLevelAt(zapcore.Level(cfg.Level)))
+}
+
+// newLogger creates logger logging entries enabled by globalLevel
+func newLogger(cfg Config, globalLevel zap.AtomicLevel) (*zap.Logger, error) {
+	// Define level handling logic
+	highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return globalLevel.Enabled(lvl) && lvl >= zapcore.ErrorLevel
+	})
+
+	lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return globalLevel.Enabled(lvl) && lvl < zapcore.ErrorLevel
+	})
+
+	consoleInfos := zapcore.Lock(os.Stdout)
+	consoleErrors := zapcore.Lock(os.Stderr)
+
+	// Configure JSON output
+	var useCustomTimeFormat bool
+	ecfg := zap.NewProductionEncoderConfig()
+	if len(cfg.TimeFormat) > 0 {
+		ecfg.EncodeTime = customTimeEncoder(cfg.TimeFormat)
+		useCustomTimeFormat = true
+	}
+	jsonEncoder := zapcore.NewJSONEncoder(ecfg)
+
+	// Configure console output
+	consoleEncoder := jsonEncoder
+	if cfg.Format == FormatConsole {
+		dcfg := zap.NewDevelopmentEncoderConfig()
+		dcfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+		if useCustomTimeFormat {
+			dcfg.EncodeTime = ecfg.EncodeTime
+		}
+		consoleEncoder = zapcore.NewConsoleEncoder(dcfg)
+		// development config has human readable timestamps
+		useCustomTimeFormat = true
+	}
+
+	// Join the outputs, encoders, and level handling functions into zapcore
+	cores := []zapcore.Core{
+		zapcore.NewCore(consoleEncoder, consoleErrors, highPriority),
+		zapcore.NewCore(consoleEncoder, consoleInfos, lowPriority),
+	}
+
+	// Configure file output
+	if len(cfg.ErrorFile) > 0 {
+		cores = append(cores, zapcore.NewCore(jsonEncoder, fileSink(cfg, cfg.ErrorFile), highPriority))
+	}
+	if len(cfg.File) > 0 {
+		cores = append(cores, zapcore.NewCore(jsonEncoder, fileSink(cfg, cfg.File), lowPriority))
+	}
+	core := zapcore.NewTee(cores...)
+
+	// Drop repeated entries, so high request rates don't flood the log pipeline
+	if cfg.SamplingInitial > 0 {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, cfg.SamplingInitial, cfg.SamplingThereafter)
+	}
+
+	l := zap.New(core)
+
+	if !useCustomTimeFormat {
+		l.Warn("Time format for logger is not provided - use zap default")
+	}
+
+	return l, nil
+}
+
+// Init creates global logger Log, standard library log is redirected to it as well.
+// Its level is changed by Level.
+func Init(cfg Config) error {
 	var err error
 	onceInit.Do(func() {
-		// Define level handling logic
-		globalLevel := zapcore.Level(lvl)
-
-		highPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			return lvl >= globalLevel && lvl < zapcore.ErrorLevel
-		})
-
-		lowPriority := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
-			return lvl >= globalLevel && lvl < zapcore.ErrorLevel
-		})
-
-		consoleInfos := zapcore.Lock(os.Stdout)
-		consoleErrors := zapcore.Lock(os.Stderr)
-
-		// Configure console output
-		var useCustomTimeFormat bool
-		ecfg := zap.NewProductionEncoderConfig()
-		if len(timeFormat) > 0 {
-			customTimeFormat = timeFormat
-			ecfg.EncodeTime = customTimeEncoder
-			useCustomTimeFormat = true
+		Level.SetLevel(zapcore.Level(cfg.Level))
+		Log, err = newLogger(cfg, Level)
+		if err != nil {
+			return
 		}
-		consoleEncoder := zapcore.NewJSONEncoder(ecfg)
-		// Join the outputs, encoders, and level handling functions into zapcore
-		core := zapcore.NewTee(
-			zapcore.NewCore(consoleEncoder, consoleErrors, highPriority),
-			zapcore.NewCore(consoleEncoder, consoleInfos, lowPriority),
-		)
-
-		Log = zap.New(core)
-		zap.RedirectStdLog(Log)
 
-		if !useCustomTimeFormat {
-			Log.Warn("Time format for logger is not provided - use zap default")
-		}
+		zap.RedirectStdLog(Log)
 	})
 
 	return err
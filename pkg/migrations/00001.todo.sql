@@ -1,4 +1,4 @@
-CREATE TABLE `todo` (
+CREATE TABLE IF NOT EXISTS `todo` (
   `id` bigint(20) NOT NULL AUTO_INCREMENT,
   `title` varchar(200) DEFAULT NULL,
   `description` varchar(1024) DEFAULT NULL,
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `api_key` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `name` varchar(200) DEFAULT NULL,
+  `prefix` varchar(16) NOT NULL,
+  `hash` char(64) NOT NULL,
+  `scopes` varchar(1024) DEFAULT NULL,
+  `expires_at` timestamp NULL DEFAULT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `revoked_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `HASH_UNIQUE` (`hash`)
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `webhook` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `url` varchar(2048) NOT NULL,
+  `events` varchar(1024) NOT NULL DEFAULT '',
+  `secret` varchar(64) NOT NULL,
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS `webhook_delivery` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `webhook_id` bigint(20) NOT NULL,
+  `event_id` char(32) NOT NULL,
+  `event_type` varchar(32) NOT NULL,
+  `payload` mediumtext NOT NULL,
+  `status` varchar(16) NOT NULL DEFAULT 'PENDING',
+  `attempts` int NOT NULL DEFAULT 0,
+  `response_code` int NOT NULL DEFAULT 0,
+  `last_error` varchar(1024) NOT NULL DEFAULT '',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `next_attempt_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `delivered_at` timestamp NULL DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `WEBHOOK_EVENT` (`webhook_id`, `event_id`),
+  KEY `STATUS_NEXT_ATTEMPT` (`status`, `next_attempt_at`),
+  KEY `WEBHOOK_ID` (`webhook_id`)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `device` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `platform` varchar(16) NOT NULL,
+  `token` varchar(4096) NOT NULL,
+  `token_hash` char(64) NOT NULL,
+  `p256dh` varchar(128) NOT NULL DEFAULT '',
+  `auth` varchar(64) NOT NULL DEFAULT '',
+  `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `TOKEN_HASH_UNIQUE` (`token_hash`)
+);
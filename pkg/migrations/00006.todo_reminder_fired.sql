@@ -0,0 +1,3 @@
+ALTER TABLE `todo`
+  ADD COLUMN `reminder_fired_at` timestamp NULL DEFAULT NULL,
+  ADD KEY `REMINDER` (`reminder`);
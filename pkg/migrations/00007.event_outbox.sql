@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `event_outbox` (
+  `id` bigint(20) NOT NULL AUTO_INCREMENT,
+  `sink` varchar(32) NOT NULL,
+  `event_id` char(32) NOT NULL,
+  `event_type` varchar(32) NOT NULL,
+  `event_time` timestamp(6) NOT NULL,
+  `todo` blob NOT NULL,
+  `status` varchar(16) NOT NULL DEFAULT 'PENDING',
+  `attempts` int NOT NULL DEFAULT 0,
+  `last_error` varchar(1024) NOT NULL DEFAULT '',
+  `next_attempt_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `SINK_EVENT` (`sink`, `event_id`),
+  KEY `STATUS_NEXT_ATTEMPT` (`status`, `next_attempt_at`)
+);
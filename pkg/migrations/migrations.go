@@ -0,0 +1,114 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// files are migrations named "<version>.<name>.sql", tables are created only if they do not exist,
+// so databases set up by hand before migrations were tracked can be migrated.
+// Migrations altering existing tables are not guarded and fail if they were applied by hand.
+//
+//go:embed *.sql
+var files embed.FS
+
+// Migration is schema change applied once, migrations are applied in order of versions
+type Migration struct {
+	// Version is zero padded number migration is ordered by
+	Version string
+	// Name describes the change
+	Name string
+	// SQL is single statement applying the change
+	SQL string
+}
+
+// All returns all migrations ordered by version
+func All() ([]Migration, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Migration
+	for _, e := range entries {
+		parts := strings.SplitN(strings.TrimSuffix(e.Name(), ".sql"), ".", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid migration file name '%s', <version>.<name>.sql expected", e.Name())
+		}
+		b, err := files.ReadFile(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, Migration{Version: parts[0], Name: parts[1], SQL: string(b)})
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Version < all[j].Version
+	})
+
+	return all, nil
+}
+
+// Pending returns migrations not applied to db yet, table tracking applied migrations is created if needed
+func Pending(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS `schema_migrations` ("+
+		"`version` varchar(16) NOT NULL, "+
+		"`applied_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP, "+
+		"PRIMARY KEY (`version`))"); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from schema_migrations: %v", err)
+	}
+	defer rows.Close()
+	applied := map[string]bool{}
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to retrieve data from schema_migrations: %v", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve data from schema_migrations: %v", err)
+	}
+
+	all, err := All()
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range all {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies pending migrations in order, migrations applied before failure are returned
+func Up(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	pending, err := Pending(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range pending {
+		if _, err := db.ExecContext(ctx, m.SQL); err != nil {
+			return done, fmt.Errorf("failed to apply migration %s.%s: %v", m.Version, m.Name, err)
+		}
+		if _, err := db.ExecContext(ctx, "INSERT INTO schema_migrations(version) VALUES (?)", m.Version); err != nil {
+			return done, fmt.Errorf("failed to record migration %s.%s: %v", m.Version, m.Name, err)
+		}
+		done = append(done, m)
+	}
+
+	return done, nil
+}
@@ -0,0 +1,41 @@
+package notify
+
+import (
+	"context"
+	"time"
+)
+
+// Reminder is reminder of todo that is due
+type Reminder struct {
+	// TodoID is ID of reminded todo
+	TodoID int64
+	// Title is title of reminded todo
+	Title string
+	// Description is description of reminded todo
+	Description string
+	// At is time reminder was set for
+	At time.Time
+}
+
+// Digest is list of reminders due in period
+type Digest struct {
+	// From and To are bounds of the period
+	From, To time.Time
+	// Reminders are reminders due in the period, ordered by time they are due
+	Reminders []Reminder
+}
+
+// Channel sends reminders to people, e.g. by email
+type Channel interface {
+	// Name is short name of channel used in logs, e.g. "email"
+	Name() string
+	// Send sends reminder, it returns when reminder is handed over to delivery service
+	Send(ctx context.Context, r Reminder) error
+}
+
+// DigestChannel is Channel also sending digests of upcoming reminders
+type DigestChannel interface {
+	Channel
+	// SendDigest sends digest, empty digest may be skipped
+	SendDigest(ctx context.Context, d Digest) error
+}
@@ -0,0 +1,208 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Chat formats
+const (
+	// ChatSlack posts to Slack incoming webhook
+	ChatSlack = "slack"
+	// ChatTeams posts to Microsoft Teams incoming webhook
+	ChatTeams = "teams"
+)
+
+// chatTemplates are default templates of chat formats
+var chatTemplates = map[string]struct{ reminder, digest string }{
+	ChatSlack: {
+		reminder: `*Reminder:* {{.Title}}{{if .Description}}
+{{.Description}}{{end}}`,
+		digest: `*Upcoming reminders until {{.To.Format "Mon, 02 Jan 15:04 MST"}}*{{range .Reminders}}
+• {{.At.Format "Mon 15:04"}} {{.Title}}{{end}}`,
+	},
+	ChatTeams: {
+		reminder: `**Reminder:** {{.Title}}{{if .Description}}
+
+{{.Description}}{{end}}`,
+		digest: `**Upcoming reminders until {{.To.Format "Mon, 02 Jan 15:04 MST"}}**
+{{range .Reminders}}
+- {{.At.Format "Mon 15:04"}} {{.Title}}{{end}}`,
+	},
+}
+
+const (
+	// chatTimeout is how long single post to chat webhook may take
+	chatTimeout = 10 * time.Second
+
+	// chatMaxRetries is number of retries of post rejected by rate limit of chat service
+	chatMaxRetries = 3
+)
+
+// ChatConfig configures posting of reminders to Slack or Microsoft Teams channel
+type ChatConfig struct {
+	// WebhookURL is URL of incoming webhook of the channel
+	WebhookURL string
+	// Format is ChatSlack or ChatTeams
+	Format string
+	// Template and DigestTemplate are text/template templates of messages executed with Reminder and Digest,
+	// defaults of Format are used if they are empty
+	Template       string
+	DigestTemplate string
+	// RateLimit is maximum number of messages posted per second
+	RateLimit float64
+}
+
+// Chat posts reminders and digests to chat channel through its incoming webhook
+type Chat struct {
+	cfg      ChatConfig
+	reminder *template.Template
+	digest   *template.Template
+	limiter  *rate.Limiter
+	client   *http.Client
+}
+
+// NewChat returns channel posting to chat webhook
+func NewChat(cfg ChatConfig) (*Chat, error) {
+	defaults, ok := chatTemplates[cfg.Format]
+	if !ok {
+		return nil, fmt.Errorf("invalid chat format '%s', %s or %s expected", cfg.Format, ChatSlack, ChatTeams)
+	}
+	if len(cfg.Template) == 0 {
+		cfg.Template = defaults.reminder
+	}
+	if len(cfg.DigestTemplate) == 0 {
+		cfg.DigestTemplate = defaults.digest
+	}
+	if cfg.RateLimit <= 0 {
+		return nil, fmt.Errorf("chat rate limit must be positive")
+	}
+
+	reminder, err := template.New("reminder").Parse(cfg.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat template: %v", err)
+	}
+	digest, err := template.New("digest").Parse(cfg.DigestTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid chat digest template: %v", err)
+	}
+
+	return &Chat{
+		cfg:      cfg,
+		reminder: reminder,
+		digest:   digest,
+		limiter:  rate.NewLimiter(rate.Limit(cfg.RateLimit), 1),
+		client:   &http.Client{Timeout: chatTimeout},
+	}, nil
+}
+
+// Name returns chat format, e.g. "slack"
+func (c *Chat) Name() string {
+	return c.cfg.Format
+}
+
+// Send posts reminder
+func (c *Chat) Send(ctx context.Context, r Reminder) error {
+	var text bytes.Buffer
+	if err := c.reminder.Execute(&text, c.escape(r)); err != nil {
+		return fmt.Errorf("failed to execute chat template: %v", err)
+	}
+	return c.post(ctx, text.String())
+}
+
+// SendDigest posts digest of reminders, empty digest is not posted
+func (c *Chat) SendDigest(ctx context.Context, d Digest) error {
+	if len(d.Reminders) == 0 {
+		return nil
+	}
+
+	escaped := d
+	escaped.Reminders = make([]Reminder, len(d.Reminders))
+	for i, r := range d.Reminders {
+		escaped.Reminders[i] = c.escape(r)
+	}
+
+	var text bytes.Buffer
+	if err := c.digest.Execute(&text, escaped); err != nil {
+		return fmt.Errorf("failed to execute chat digest template: %v", err)
+	}
+	return c.post(ctx, text.String())
+}
+
+// slackEscaper escapes characters Slack uses for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escape returns reminder with text fields escaped for chat format
+func (c *Chat) escape(r Reminder) Reminder {
+	if c.cfg.Format == ChatSlack {
+		r.Title = slackEscaper.Replace(r.Title)
+		r.Description = slackEscaper.Replace(r.Description)
+	}
+	return r
+}
+
+// post sends message with text to webhook, posts rejected by rate limit of chat service are retried after delay it asks for
+func (c *Chat) post(ctx context.Context, text string) error {
+	var msg interface{}
+	switch c.cfg.Format {
+	case ChatTeams:
+		msg = map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  "Todo reminder",
+			"text":     text,
+		}
+	default:
+		msg = map[string]string{"text": text}
+	}
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	for retry := 0; ; retry++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.WebhookURL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return err
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			return nil
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || retry >= chatMaxRetries {
+			return fmt.Errorf("%s webhook returned %s", c.cfg.Format, resp.Status)
+		}
+
+		delay := time.Second
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && s > 0 {
+			delay = time.Duration(s) * time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// DefaultEmailSubject is template of subject of reminder emails
+	DefaultEmailSubject = `Reminder: {{.Title}}`
+
+	// DefaultEmailBody is template of body of reminder emails
+	DefaultEmailBody = `{{.Title}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+Reminder set for {{.At.Format "Mon, 02 Jan 2006 15:04 MST"}}.
+`
+)
+
+// EmailConfig configures sending of reminders by email
+type EmailConfig struct {
+	// Host is host name of SMTP server
+	Host string
+	// Port is port of SMTP server, STARTTLS is used if server supports it
+	Port int
+	// Username and Password authenticate to SMTP server, authentication is skipped if Username is empty
+	Username string
+	Password string
+	// From is sender address
+	From string
+	// To are addresses reminders are sent to
+	To []string
+	// Subject and Body are text/template templates executed with Reminder,
+	// DefaultEmailSubject and DefaultEmailBody are used if they are empty
+	Subject string
+	Body    string
+}
+
+// Email sends reminders by email
+type Email struct {
+	cfg     EmailConfig
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewEmail returns channel sending reminders to SMTP server
+func NewEmail(cfg EmailConfig) (*Email, error) {
+	if len(cfg.Subject) == 0 {
+		cfg.Subject = DefaultEmailSubject
+	}
+	if len(cfg.Body) == 0 {
+		cfg.Body = DefaultEmailBody
+	}
+
+	subject, err := template.New("subject").Parse(cfg.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email subject template: %v", err)
+	}
+	body, err := template.New("body").Parse(cfg.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email body template: %v", err)
+	}
+
+	return &Email{cfg: cfg, subject: subject, body: body}, nil
+}
+
+// Name returns "email"
+func (e *Email) Name() string {
+	return "email"
+}
+
+// Send emails reminder to all recipients
+func (e *Email) Send(ctx context.Context, r Reminder) error {
+	msg, err := e.message(r)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %v", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP session: %v", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: e.cfg.Host}); err != nil {
+			return fmt.Errorf("failed to start TLS: %v", err)
+		}
+	}
+	if len(e.cfg.Username) > 0 {
+		// PlainAuth refuses to send password without TLS, except to localhost
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate to SMTP server: %v", err)
+		}
+	}
+
+	if err := c.Mail(e.cfg.From); err != nil {
+		return fmt.Errorf("failed to set sender: %v", err)
+	}
+	for _, to := range e.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to set recipient '%s': %v", to, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %v", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %v", err)
+	}
+	return c.Quit()
+}
+
+// message returns email of reminder with headers
+func (e *Email) message(r Reminder) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := e.subject.Execute(&subject, r); err != nil {
+		return nil, fmt.Errorf("failed to execute email subject template: %v", err)
+	}
+	if err := e.body.Execute(&body, r); err != nil {
+		return nil, fmt.Errorf("failed to execute email body template: %v", err)
+	}
+
+	// line breaks in subject would start new headers
+	s := strings.Join(strings.Fields(subject.String()), " ")
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", e.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(e.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", s))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	msg.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&msg)
+	if _, err := qp.Write(bytes.ReplaceAll(body.Bytes(), []byte("\n"), []byte("\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return msg.Bytes(), nil
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	// fcmScope is OAuth2 scope of FCM HTTP v1 API
+	fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+	// fcmEndpoint is URL of send method of FCM HTTP v1 API, %s is project ID
+	fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// fcm pushes reminders through Firebase Cloud Messaging
+type fcm struct {
+	client   *http.Client
+	endpoint string
+}
+
+// newFCM returns FCM pusher authenticated with service account key from cfg.FCMCredentialsFile
+func newFCM(ctx context.Context, cfg PushConfig) (*fcm, error) {
+	data, err := os.ReadFile(cfg.FCMCredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FCM credentials: %v", err)
+	}
+	creds, err := google.CredentialsFromJSON(ctx, data, fcmScope)
+	if err != nil {
+		return nil, fmt.Errorf("invalid FCM credentials: %v", err)
+	}
+
+	project := cfg.FCMProjectID
+	if len(project) == 0 {
+		project = creds.ProjectID
+	}
+	if len(project) == 0 {
+		return nil, errors.New("FCM project ID is not set and it is not in credentials")
+	}
+
+	// token source outlives ctx, tokens are refreshed in background of requests
+	client := oauth2.NewClient(context.Background(), creds.TokenSource)
+	return &fcm{client: client, endpoint: fmt.Sprintf(fcmEndpoint, project)}, nil
+}
+
+// fcmMessage is request body of FCM send method
+type fcmMessage struct {
+	Message struct {
+		Token        string            `json:"token"`
+		Notification fcmNotification   `json:"notification"`
+		Data         map[string]string `json:"data"`
+	} `json:"message"`
+}
+
+// fcmNotification is notification displayed by device
+type fcmNotification struct {
+	Title string `json:"title"`
+	Body  string `json:"body,omitempty"`
+}
+
+// fcmError is error response of FCM
+type fcmError struct {
+	Error struct {
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// push sends reminder to device
+func (f *fcm) push(ctx context.Context, d device, r Reminder) error {
+	var m fcmMessage
+	m.Message.Token = d.token
+	m.Message.Notification = fcmNotification{Title: r.Title, Body: r.Description}
+	m.Message.Data = map[string]string{
+		"todo_id":  strconv.FormatInt(r.TodoID, 10),
+		"reminder": r.At.UTC().Format(time.RFC3339),
+	}
+	body, err := json.Marshal(&m)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return &pushError{err: err, temporary: true}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	var e fcmError
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e)
+	pe := &pushError{
+		err:       fmt.Errorf("FCM returned %s", resp.Status),
+		temporary: temporaryStatus(resp.StatusCode),
+	}
+	if len(e.Error.Status) > 0 {
+		pe.err = fmt.Errorf("FCM returned %s: %s: %s", resp.Status, e.Error.Status, e.Error.Message)
+	}
+	for _, detail := range e.Error.Details {
+		// token expired, app was uninstalled or token belongs to other project
+		if detail.ErrorCode == "UNREGISTERED" || detail.ErrorCode == "SENDER_ID_MISMATCH" {
+			pe.invalidToken = true
+		}
+	}
+	return pe
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Platforms of devices stored in device table, they match v1.Device_Platform names
+const (
+	platformFCM     = "FCM"
+	platformWebPush = "WEB_PUSH"
+)
+
+// PushConfig configures pushing of reminders to registered devices
+type PushConfig struct {
+	// FCMCredentialsFile is JSON file with Google service account key, FCM is used if it is set
+	FCMCredentialsFile string
+	// FCMProjectID is Firebase project ID, it is read from credentials if empty
+	FCMProjectID string
+	// VAPIDPublicKey and VAPIDPrivateKey identify server to Web Push services, Web Push is used if they are set
+	VAPIDPublicKey  string
+	VAPIDPrivateKey string
+	// VAPIDSubscriber is contact email sent to Web Push services
+	VAPIDSubscriber string
+	// Timeout is how long single push may take
+	Timeout time.Duration
+	// MaxAttempts is number of attempts of push failing with temporary error
+	MaxAttempts int
+	// RetryBackoff is delay before second attempt, it is doubled for every next attempt
+	RetryBackoff time.Duration
+}
+
+// DefaultPushConfig has values used for zero Timeout, MaxAttempts and RetryBackoff of PushConfig
+var DefaultPushConfig = PushConfig{
+	Timeout:      10 * time.Second,
+	MaxAttempts:  3,
+	RetryBackoff: time.Second,
+}
+
+// device is device stored in device table
+type device struct {
+	id       int64
+	platform string
+	token    string
+	p256dh   string
+	auth     string
+}
+
+// pushError is failure of single push
+type pushError struct {
+	err error
+	// temporary errors are retried
+	temporary bool
+	// invalidToken means device is not reachable any more and is unregistered
+	invalidToken bool
+}
+
+func (e *pushError) Error() string {
+	return e.err.Error()
+}
+
+// pusher sends reminders to devices of single platform
+type pusher interface {
+	push(ctx context.Context, d device, r Reminder) error
+}
+
+// Push pushes reminders to all devices registered by Admin Service
+type Push struct {
+	db      *sql.DB
+	cfg     PushConfig
+	pushers map[string]pusher
+	log     *zap.Logger
+}
+
+// NewPush returns channel pushing reminders to devices stored in db, platforms that are not configured are skipped
+func NewPush(ctx context.Context, db *sql.DB, cfg PushConfig, log *zap.Logger) (*Push, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultPushConfig.Timeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultPushConfig.MaxAttempts
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = DefaultPushConfig.RetryBackoff
+	}
+
+	p := &Push{db: db, cfg: cfg, pushers: map[string]pusher{}, log: logger.OrNop(log)}
+	if len(cfg.FCMCredentialsFile) > 0 {
+		fcm, err := newFCM(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		p.pushers[platformFCM] = fcm
+	}
+	if len(cfg.VAPIDPublicKey) > 0 {
+		p.pushers[platformWebPush] = newWebPush(cfg)
+	}
+	if len(p.pushers) == 0 {
+		return nil, errors.New("neither FCM nor Web Push is configured")
+	}
+	return p, nil
+}
+
+// Name returns "push"
+func (p *Push) Name() string {
+	return "push"
+}
+
+// Send pushes reminder to all devices of configured platforms, devices with invalid tokens are unregistered.
+// It fails if reminder is not pushed to some device.
+func (p *Push) Send(ctx context.Context, r Reminder) error {
+	devices, err := p.devices(ctx)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	var last error
+	for _, d := range devices {
+		err := p.pushWithRetry(ctx, d, r)
+		var pe *pushError
+		switch {
+		case err == nil:
+		case errors.As(err, &pe) && pe.invalidToken:
+			p.unregister(d, err)
+		default:
+			failed++
+			last = err
+			p.log.Warn("Failed to push reminder",
+				zap.Int64("device", d.id),
+				zap.Int64("todo", r.TodoID),
+				zap.String("reason", err.Error()),
+			)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to push reminder to %d of %d devices: %v", failed, len(devices), last)
+	}
+	return nil
+}
+
+// devices returns devices of configured platforms
+func (p *Push) devices(ctx context.Context) ([]device, error) {
+	var platforms []interface{}
+	for platform := range p.pushers {
+		platforms = append(platforms, platform)
+	}
+	query := `SELECT id, platform, token, p256dh, auth FROM device WHERE platform IN (?` +
+		strings.Repeat(", ?", len(platforms)-1) + `)`
+	rows, err := p.db.QueryContext(ctx, query, platforms...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from device: %v", err)
+	}
+	defer rows.Close()
+
+	var list []device
+	for rows.Next() {
+		var d device
+		if err := rows.Scan(&d.id, &d.platform, &d.token, &d.p256dh, &d.auth); err != nil {
+			return nil, fmt.Errorf("failed to retrieve field values from device: %v", err)
+		}
+		list = append(list, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve data from device: %v", err)
+	}
+	return list, nil
+}
+
+// pushWithRetry pushes reminder to device, attempts failing with temporary error are repeated
+func (p *Push) pushWithRetry(ctx context.Context, d device, r Reminder) error {
+	backoff := p.cfg.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		actx, cancel := context.WithTimeout(ctx, p.cfg.Timeout)
+		err := p.pushers[d.platform].push(actx, d, r)
+		cancel()
+
+		var pe *pushError
+		if err == nil || !errors.As(err, &pe) || !pe.temporary || attempt >= p.cfg.MaxAttempts {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// unregister deletes device whose token was rejected by push service
+func (p *Push) unregister(d device, cause error) {
+	// device is deleted even if reminder is cancelled, its token is not valid anyway
+	if _, err := p.db.ExecContext(context.Background(), `DELETE FROM device WHERE id = ?`, d.id); err != nil {
+		p.log.Error("Failed to delete device", zap.Int64("device", d.id), zap.String("reason", err.Error()))
+		return
+	}
+	p.log.Info("Device with invalid token unregistered",
+		zap.Int64("device", d.id),
+		zap.String("platform", d.platform),
+		zap.String("reason", cause.Error()),
+	)
+}
+
+// temporaryStatus reports whether push failed with HTTP status code is worth retrying
+func temporaryStatus(code int) bool {
+	return code == 429 || code >= 500
+}
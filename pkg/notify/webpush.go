@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/SherClockHolmes/webpush-go"
+)
+
+// webPushTTL is how long Web Push service keeps reminder for offline browser
+const webPushTTL = 24 * time.Hour
+
+// webPush pushes reminders to browsers through their Web Push services
+type webPush struct {
+	options webpush.Options
+}
+
+// newWebPush returns Web Push pusher identified by VAPID keys of cfg
+func newWebPush(cfg PushConfig) *webPush {
+	return &webPush{options: webpush.Options{
+		HTTPClient:      http.DefaultClient,
+		Subscriber:      cfg.VAPIDSubscriber,
+		TTL:             int(webPushTTL / time.Second),
+		VAPIDPublicKey:  cfg.VAPIDPublicKey,
+		VAPIDPrivateKey: cfg.VAPIDPrivateKey,
+	}}
+}
+
+// webPushMessage is payload received by service worker
+type webPushMessage struct {
+	TodoID      int64     `json:"todo_id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	Reminder    time.Time `json:"reminder"`
+}
+
+// push sends encrypted reminder to subscription of device
+func (w *webPush) push(ctx context.Context, d device, r Reminder) error {
+	payload, err := json.Marshal(&webPushMessage{TodoID: r.TodoID, Title: r.Title, Description: r.Description, Reminder: r.At.UTC()})
+	if err != nil {
+		return err
+	}
+
+	options := w.options
+	s := &webpush.Subscription{Endpoint: d.token, Keys: webpush.Keys{P256dh: d.p256dh, Auth: d.auth}}
+	resp, err := webpush.SendNotificationWithContext(ctx, payload, s, &options)
+	if err != nil {
+		return &pushError{err: err, temporary: true}
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+	return &pushError{
+		err:       fmt.Errorf("Web Push service returned %s", resp.Status),
+		temporary: temporaryStatus(resp.StatusCode),
+		// subscription expired or browser unsubscribed
+		invalidToken: resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone,
+	}
+}
@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"context"
+	"net"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	channelz "google.golang.org/grpc/channelz/service"
+)
+
+// RunChannelzServer runs gRPC server publishing channelz service, so operators can inspect live channels,
+// subchannels and sockets of the process with grpcdebug. It listens on loopback interface only if localhostOnly is true.
+func RunChannelzServer(ctx context.Context, port string, localhostOnly bool, log *zap.Logger) error {
+	host := ""
+	if localhostOnly {
+		host = "localhost"
+	}
+
+	listen, err := net.Listen("tcp", host+":"+port)
+	if err != nil {
+		return err
+	}
+
+	server := grpc.NewServer()
+	channelz.RegisterChannelzServiceToServer(server)
+
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+
+	logger.OrNop(log).Info("Starting channelz server...")
+	return server.Serve(listen)
+}
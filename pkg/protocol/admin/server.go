@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RunServer runs HTTP server for operators to capture CPU/heap profiles and runtime traces,
+// it listens on loopback interface only if localhostOnly is true
+func RunServer(ctx context.Context, port string, localhostOnly bool, log *zap.Logger) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	host := ""
+	if localhostOnly {
+		host = "localhost"
+	}
+
+	srv := &http.Server{
+		Addr:    host + ":" + port,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(ctx)
+	}()
+
+	logger.OrNop(log).Info("Starting admin server...")
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
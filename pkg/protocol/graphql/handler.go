@@ -0,0 +1,16 @@
+package graphql
+
+import (
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+)
+
+// NewHandler returns HTTP handler of GraphQL endpoint, queries and mutations are resolved by calling client,
+// so they pass the same middleware as gRPC and REST calls
+func NewHandler(client v1.TodoServiceClient) http.Handler {
+	s := graphql.MustParseSchema(schema, &resolver{client: client})
+	return &relay.Handler{Schema: s}
+}
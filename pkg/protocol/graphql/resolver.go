@@ -0,0 +1,178 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	graphql "github.com/graph-gophers/graphql-go"
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+)
+
+// apiVersion is version of API calls are made with
+const apiVersion = "v1"
+
+// resolver resolves queries and mutations by calling Todo Service
+type resolver struct {
+	client v1.TodoServiceClient
+}
+
+// todoResolver resolves fields of Todo
+type todoResolver struct {
+	todo *v1.Todo
+}
+
+func (r *todoResolver) ID() graphql.ID {
+	return graphql.ID(strconv.FormatInt(r.todo.Id, 10))
+}
+
+func (r *todoResolver) Title() string {
+	return r.todo.Title
+}
+
+func (r *todoResolver) Description() string {
+	return r.todo.Description
+}
+
+func (r *todoResolver) Reminder() *string {
+	if r.todo.Reminder == nil {
+		return nil
+	}
+	reminder, err := ptypes.Timestamp(r.todo.Reminder)
+	if err != nil {
+		return nil
+	}
+	s := reminder.Format(time.RFC3339)
+	return &s
+}
+
+// todoInput is TodoInput argument
+type todoInput struct {
+	Title       string
+	Description *string
+	Reminder    string
+}
+
+// toTodo converts input to todo task with id
+func (in todoInput) toTodo(id int64) (*v1.Todo, error) {
+	reminder, err := time.Parse(time.RFC3339, in.Reminder)
+	if err != nil {
+		return nil, fmt.Errorf("reminder must be RFC 3339 date-time: %v", err)
+	}
+	ts, err := ptypes.TimestampProto(reminder)
+	if err != nil {
+		return nil, fmt.Errorf("reminder is out of range: %v", err)
+	}
+
+	td := &v1.Todo{Id: id, Title: in.Title, Reminder: ts}
+	if in.Description != nil {
+		td.Description = *in.Description
+	}
+	return td, nil
+}
+
+// parseID converts ID argument to id of todo task
+func parseID(id graphql.ID) (int64, error) {
+	n, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id '%s'", id)
+	}
+	return n, nil
+}
+
+func (r *resolver) Todo(ctx context.Context, args struct{ ID graphql.ID }) (*todoResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.client.Read(ctx, &v1.ReadRequest{Api: apiVersion, Id: id})
+	if err != nil {
+		return nil, err
+	}
+	return &todoResolver{todo: res.Todo}, nil
+}
+
+func (r *resolver) Todos(ctx context.Context, args struct {
+	Title  *string
+	First  *int32
+	Offset *int32
+}) ([]*todoResolver, error) {
+	res, err := r.client.ReadAll(ctx, &v1.ReadAllRequest{Api: apiVersion})
+	if err != nil {
+		return nil, err
+	}
+
+	// ReadAll has no filters, so todo tasks are filtered and paginated here
+	var todos []*todoResolver
+	for _, td := range res.Todos {
+		if args.Title != nil && !strings.Contains(strings.ToLower(td.Title), strings.ToLower(*args.Title)) {
+			continue
+		}
+		todos = append(todos, &todoResolver{todo: td})
+	}
+
+	if args.Offset != nil && *args.Offset > 0 {
+		if int(*args.Offset) >= len(todos) {
+			return []*todoResolver{}, nil
+		}
+		todos = todos[*args.Offset:]
+	}
+	if args.First != nil && *args.First >= 0 && int(*args.First) < len(todos) {
+		todos = todos[:*args.First]
+	}
+
+	if todos == nil {
+		todos = []*todoResolver{}
+	}
+	return todos, nil
+}
+
+func (r *resolver) CreateTodo(ctx context.Context, args struct{ Input todoInput }) (*todoResolver, error) {
+	td, err := args.Input.toTodo(0)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := r.client.Create(ctx, &v1.CreateRequest{Api: apiVersion, Todo: td})
+	if err != nil {
+		return nil, err
+	}
+	td.Id = res.Id
+	return &todoResolver{todo: td}, nil
+}
+
+func (r *resolver) UpdateTodo(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input todoInput
+}) (*todoResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	td, err := args.Input.toTodo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := r.client.Update(ctx, &v1.UpdateRequest{Api: apiVersion, Todo: td}); err != nil {
+		return nil, err
+	}
+	return &todoResolver{todo: td}, nil
+}
+
+func (r *resolver) DeleteTodo(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := r.client.Delete(ctx, &v1.DeleteRequest{Api: apiVersion, Id: id})
+	if err != nil {
+		return false, err
+	}
+	return res.Deleted > 0, nil
+}
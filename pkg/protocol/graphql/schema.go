@@ -0,0 +1,40 @@
+package graphql
+
+// schema is GraphQL schema of Todo Service, reminders are RFC 3339 date-time strings
+const schema = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+type Query {
+	# todo returns todo task by id
+	todo(id: ID!): Todo!
+	# todos returns todo tasks with title containing title, offset tasks are skipped and first tasks are returned
+	todos(title: String, first: Int, offset: Int): [Todo!]!
+}
+
+type Mutation {
+	# createTodo creates todo task and returns it
+	createTodo(input: TodoInput!): Todo!
+	# updateTodo updates todo task and returns it
+	updateTodo(id: ID!, input: TodoInput!): Todo!
+	# deleteTodo deletes todo task, it returns true if it was deleted
+	deleteTodo(id: ID!): Boolean!
+}
+
+# Todo is task we have to do
+type Todo {
+	id: ID!
+	title: String!
+	description: String!
+	reminder: String
+}
+
+# TodoInput is content of todo task to create or update
+input TodoInput {
+	title: String!
+	description: String
+	reminder: String!
+}
+`
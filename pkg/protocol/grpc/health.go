@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// healthCheckTimeout is maximum duration of single health check
+const healthCheckTimeout = 2 * time.Second
+
+// defaultHealthCheckInterval is how often health check is run if interval is not set
+const defaultHealthCheckInterval = 5 * time.Second
+
+// setServingStatus sets status of the whole server and all its services
+func setServingStatus(hs *health.Server, server server, st healthpb.HealthCheckResponse_ServingStatus) {
+	hs.SetServingStatus("", st)
+	for name := range server.GetServiceInfo() {
+		hs.SetServingStatus(name, st)
+	}
+}
+
+// watchHealth runs check every interval and reports the server SERVING only while it succeeds
+func watchHealth(ctx context.Context, log *zap.Logger, hs *health.Server, server server, check func(context.Context) error, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	serving := true
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+		err := check(checkCtx)
+		cancel()
+
+		switch {
+		case err != nil && serving:
+			log.Warn("Health check failed, server is not serving", zap.String("reason", err.Error()))
+			setServingStatus(hs, server, healthpb.HealthCheckResponse_NOT_SERVING)
+			serving = false
+		case err == nil && !serving:
+			log.Info("Health check succeeded, server is serving")
+			setServingStatus(hs, server, healthpb.HealthCheckResponse_SERVING)
+			serving = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
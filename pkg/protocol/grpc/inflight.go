@@ -0,0 +1,41 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// inFlight counts calls being handled, it tells how many calls are cut off by forced stop
+type inFlight struct {
+	n int64
+}
+
+// count returns number of calls being handled
+func (f *inFlight) count() int64 {
+	return atomic.LoadInt64(&f.n)
+}
+
+// addInterceptors returns grpc.Server config option that counts calls being handled
+func (f *inFlight) addInterceptors(opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			atomic.AddInt64(&f.n, 1)
+			defer atomic.AddInt64(&f.n, -1)
+
+			return handler(ctx, req)
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			atomic.AddInt64(&f.n, 1)
+			defer atomic.AddInt64(&f.n, -1)
+
+			return handler(srv, ss)
+		},
+	))
+
+	return opts
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// APIKeyKey is metadata key API key of the caller is sent in
+const APIKeyKey = "x-api-key"
+
+// APIKeyLookup returns scopes granted to API key, it returns error if the key is unknown, revoked or expired
+type APIKeyLookup func(ctx context.Context, key string) ([]string, error)
+
+// verifyAPIKey checks API key stored in incoming metadata is granted one of accepted scopes
+func verifyAPIKey(ctx context.Context, lookup APIKeyLookup, accepted []string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var key string
+	if v := md.Get(APIKeyKey); len(v) > 0 {
+		key = v[0]
+	}
+	if len(key) == 0 {
+		return status.Error(codes.Unauthenticated, "API key is missing")
+	}
+
+	scopes, err := lookup(ctx, key)
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	for _, s := range scopes {
+		for _, a := range accepted {
+			if s == a {
+				return nil
+			}
+		}
+	}
+
+	return status.Errorf(codes.PermissionDenied, "API key is not granted scope '%s'", strings.Join(accepted, "' or '"))
+}
+
+// AddAPIKeyAuth returns grpc.Server config option that requires calls of methods in scopes to send API key
+// granted one of scopes accepted for the method, scopes maps full method name to accepted scopes.
+// Calls of other methods are not checked.
+func AddAPIKeyAuth(lookup APIKeyLookup, scopes map[string][]string, opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if accepted, ok := scopes[info.FullMethod]; ok {
+				if err := verifyAPIKey(ctx, lookup, accepted); err != nil {
+					return nil, err
+				}
+			}
+			return handler(ctx, req)
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if accepted, ok := scopes[info.FullMethod]; ok {
+				if err := verifyAPIKey(ss.Context(), lookup, accepted); err != nil {
+					return err
+				}
+			}
+			return handler(srv, ss)
+		},
+	))
+
+	return opts
+}
@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// signatureTolerance is maximum allowed clock difference between caller and server
+const signatureTolerance = 5 * time.Minute
+
+// unsignedServicePrefix is prefix of methods of health service, they are not signed by probes and
+// by health checking of client side load balancing
+const unsignedServicePrefix = "/grpc.health.v1.Health/"
+
+// verifySignature checks signature of the call stored in incoming metadata
+func verifySignature(ctx context.Context, secret, method string, req interface{}) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var signature, timestamp string
+	if v := md.Get(strings.ToLower(auth.SignatureHeader)); len(v) > 0 {
+		signature = v[0]
+	}
+	if v := md.Get(strings.ToLower(auth.TimestampHeader)); len(v) > 0 {
+		timestamp = v[0]
+	}
+
+	body, err := auth.MessageBody(req)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, "Failed to serialize request -> "+err.Error())
+	}
+
+	if err := auth.Verify(secret, signature, timestamp, method, body, signatureTolerance, time.Now()); err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return nil
+}
+
+// AddSignatureAuth returns grpc.Server config option that requires every call except health checks
+// to be signed with shared secret.
+// Streams are signed with empty body, since messages are not known when stream is opened.
+func AddSignatureAuth(secret string, opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if strings.HasPrefix(info.FullMethod, unsignedServicePrefix) {
+				return handler(ctx, req)
+			}
+			if err := verifySignature(ctx, secret, info.FullMethod, req); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if strings.HasPrefix(info.FullMethod, unsignedServicePrefix) {
+				return handler(srv, ss)
+			}
+			if err := verifySignature(ss.Context(), secret, info.FullMethod, nil); err != nil {
+				return err
+			}
+			return handler(srv, ss)
+		},
+	))
+
+	return opts
+}
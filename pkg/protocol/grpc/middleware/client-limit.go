@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// clientKey returns identity of the client concurrent calls are counted for. Calls forwarded by
+// HTTP gateway over loopback are counted for the original caller, x-forwarded-for of other
+// peers is ignored, so clients cannot avoid the limit by setting it.
+func clientKey(ctx context.Context) string {
+	if addr := forwardedFor(ctx); len(addr) > 0 {
+		return addr
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	return host
+}
+
+// clientLimiter counts calls being handled per client
+type clientLimiter struct {
+	limit int
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// acquire reserves call slot of client, it returns false if client has limit calls being handled
+func (l *clientLimiter) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.calls[key] >= l.limit {
+		return false
+	}
+	l.calls[key]++
+	return true
+}
+
+// release frees call slot of client
+func (l *clientLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls[key]--
+	if l.calls[key] <= 0 {
+		delete(l.calls, key)
+	}
+}
+
+// errClientLimit is returned for calls exceeding client limit
+var errClientLimit = status.Error(codes.ResourceExhausted, "Too many concurrent calls of the client")
+
+// AddClientConcurrencyLimit returns grpc.Server config option that limits number of calls and streams
+// handled for single client at the same time, calls over the limit fail with codes.ResourceExhausted
+func AddClientConcurrencyLimit(limit int, opts []grpc.ServerOption) []grpc.ServerOption {
+	l := &clientLimiter{limit: limit, calls: map[string]int{}}
+
+	// Add unary interceptor
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			key := clientKey(ctx)
+			if !l.acquire(key) {
+				return nil, errClientLimit
+			}
+			defer l.release(key)
+
+			return handler(ctx, req)
+		},
+	))
+
+	// Add stream interceptor
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			key := clientKey(ss.Context())
+			if !l.acquire(key) {
+				return errClientLimit
+			}
+			defer l.release(key)
+
+			return handler(srv, ss)
+		},
+	))
+
+	return opts
+}
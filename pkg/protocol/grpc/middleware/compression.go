@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/proto"
+)
+
+// acceptsGzip reports whether client of the call accepts gzip compressed messages
+func acceptsGzip(ctx context.Context) bool {
+	names, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return false
+	}
+	for _, name := range names {
+		if name == gzip.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddCompression returns grpc.Server config option that gzip compresses unary responses of at least threshold bytes
+// for clients accepting gzip. Responses of clients sending gzip compressed requests are always compressed.
+func AddCompression(threshold int, opts []grpc.ServerOption) []grpc.ServerOption {
+	return append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			resp, err := handler(ctx, req)
+			if err != nil {
+				return resp, err
+			}
+
+			if msg, ok := resp.(proto.Message); ok && proto.Size(msg) >= threshold && acceptsGzip(ctx) {
+				// headers are not sent yet, so compressor of the response can still be set
+				_ = grpc.SetSendCompressor(ctx, gzip.Name)
+			}
+			return resp, nil
+		},
+	))
+}
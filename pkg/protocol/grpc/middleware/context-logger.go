@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// forwardedForKey is gRPC metadata key HTTP gateway puts address of the original caller to
+const forwardedForKey = "x-forwarded-for"
+
+// forwardedFor returns address of the original caller of call forwarded by HTTP gateway over loopback,
+// it is empty for other calls, so x-forwarded-for set by other peers is ignored
+func forwardedFor(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return ""
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(forwardedForKey); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// callerAddress returns address of the original caller, calls forwarded by HTTP gateway
+// are attributed to the client of the gateway rather than to the gateway itself
+func callerAddress(ctx context.Context) string {
+	if addr := forwardedFor(ctx); len(addr) > 0 {
+		return addr
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		return p.Addr.String()
+	}
+
+	return ""
+}
+
+// scopedLogger returns child of l with method, request id, caller and trace of the call
+func scopedLogger(ctx context.Context, l *zap.Logger, method string) *zap.Logger {
+	fields := []zap.Field{zap.String("grpc.method", method)}
+	if id := requestID(ctx); len(id) > 0 {
+		fields = append(fields, zap.String(requestIDTag, id))
+	}
+	if addr := callerAddress(ctx); len(addr) > 0 {
+		fields = append(fields, zap.String("caller.address", addr))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String("trace-id", sc.TraceID().String()),
+			zap.String("span-id", sc.SpanID().String()),
+		)
+	}
+
+	return l.With(fields...)
+}
+
+// AddContextLogger returns grpc.Server config option that injects logger scoped to the call into its context,
+// services retrieve it with logger.FromContext
+func AddContextLogger(l *zap.Logger, opts []grpc.ServerOption) []grpc.ServerOption {
+	// Add unary interceptor
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(logger.NewContext(ctx, scopedLogger(ctx, l, info.FullMethod)), req)
+		},
+	))
+
+	// Add stream interceptor
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			wrapped := grpc_middleware.WrapServerStream(ss)
+			wrapped.WrappedContext = logger.NewContext(ss.Context(), scopedLogger(ss.Context(), l, info.FullMethod))
+			return handler(srv, wrapped)
+		},
+	))
+
+	return opts
+}
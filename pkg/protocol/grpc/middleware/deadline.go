@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddDefaultDeadline returns grpc.Server config option that applies timeout to unary calls without deadline,
+// errors of calls that run past their deadline are replaced by DeadlineExceeded.
+// Streams are left without deadline, they are meant to be long-lived.
+func AddDefaultDeadline(timeout time.Duration, opts []grpc.ServerOption) []grpc.ServerOption {
+	return append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if _, ok := ctx.Deadline(); !ok {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, timeout)
+				defer cancel()
+			}
+
+			resp, err := handler(ctx, req)
+			if err != nil && ctx.Err() == context.DeadlineExceeded {
+				return nil, status.Error(codes.DeadlineExceeded, "Deadline exceeded")
+			}
+			return resp, err
+		},
+	))
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/errorrate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DatabaseTracking configures tracking of database error rate
+type DatabaseTracking struct {
+	// Tracker records result of every call using database
+	Tracker *errorrate.Tracker
+	// Circuit turns on rejecting calls using database while Tracker is tripped
+	Circuit bool
+	// Methods are full names of methods using database, other calls are not tracked
+	Methods []string
+}
+
+// databaseFailure reports whether err is caused by database
+func databaseFailure(err error) bool {
+	var e *v1.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+
+	switch e.Reason() {
+	case v1.ReasonDatabaseUnavailable, v1.ReasonDatabaseError:
+		return true
+	}
+	return false
+}
+
+// AddDatabaseTracking returns grpc.Server config option that records results of calls of o.Methods.
+// Calls failing with database errors count as failures, all other results count as successes.
+// If circuit is on, calls are rejected with codes.Unavailable while error rate exceeds threshold,
+// rejected calls are not recorded, so the circuit closes once failures leave the rolling window.
+func AddDatabaseTracking(o DatabaseTracking, opts []grpc.ServerOption) []grpc.ServerOption {
+	tracked := map[string]bool{}
+	for _, m := range o.Methods {
+		tracked[m] = true
+	}
+
+	record := func(err error) {
+		if databaseFailure(err) {
+			o.Tracker.Record(err)
+			return
+		}
+		o.Tracker.Record(nil)
+	}
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if !tracked[info.FullMethod] {
+				return handler(ctx, req)
+			}
+			if o.Circuit && o.Tracker.Tripped() {
+				return nil, status.Error(codes.Unavailable, "Database is temporarily unavailable, try again later")
+			}
+
+			resp, err := handler(ctx, req)
+			record(err)
+			return resp, err
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if !tracked[info.FullMethod] {
+				return handler(srv, ss)
+			}
+			if o.Circuit && o.Tracker.Tripped() {
+				return status.Error(codes.Unavailable, "Database is temporarily unavailable, try again later")
+			}
+
+			err := handler(srv, ss)
+			record(err)
+			return err
+		},
+	))
+
+	return opts
+}
@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/maslow123/go-grpc/pkg/errorreport"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// reportContext returns sanitized context of the call
+func reportContext(ctx context.Context, redactor *Redactor, req interface{}) map[string]interface{} {
+	c := map[string]interface{}{}
+	if p, ok := peer.FromContext(ctx); ok {
+		c["peer.address"] = p.Addr.String()
+	}
+
+	if redactor == nil {
+		return c
+	}
+
+	if m, ok := redactor.Redact(req).(proto.Message); ok {
+		if b, err := protojson.Marshal(m); err == nil {
+			c["grpc.request.content"] = string(b)
+		}
+	}
+
+	return c
+}
+
+// AddErrorReporting returns grpc.Server config option that reports server side errors to reporter,
+// request content is redacted before it is reported. Panics are reported by AddRecovery.
+func AddErrorReporting(reporter errorreport.Reporter, redactor *Redactor, opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			resp, err := handler(ctx, req)
+			if err != nil && serverSide(status.Code(err)) {
+				reporter.Report(ctx, errorreport.Event{
+					Err:       err,
+					Protocol:  "grpc",
+					Method:    info.FullMethod,
+					RequestID: requestID(ctx),
+					Context:   reportContext(ctx, redactor, req),
+				})
+			}
+			return resp, err
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			err := handler(srv, ss)
+			if err != nil && serverSide(status.Code(err)) {
+				reporter.Report(ss.Context(), errorreport.Event{
+					Err:       err,
+					Protocol:  "grpc",
+					Method:    info.FullMethod,
+					RequestID: requestID(ss.Context()),
+					Context:   reportContext(ss.Context(), redactor, nil),
+				})
+			}
+			return err
+		},
+	))
+
+	return opts
+}
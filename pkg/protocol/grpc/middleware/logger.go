@@ -1,15 +1,29 @@
 package middleware
 
 import (
-	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
 	grpc_zap "github.com/grpc-ecosystem/go-grpc-middleware/logging/zap"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
+// replaceGrpcLogger makes sure global gRPC logger is replaced only once, it is not safe to replace while gRPC is in use,
+// e.g. by servers of tests running in the same process
+var replaceGrpcLogger sync.Once
+
 // codeToLevel redirects OK to DEBUG level logging instead of INFO
 func codeToLevel(code codes.Code) zapcore.Level {
 	if code == codes.OK {
@@ -19,25 +33,150 @@ func codeToLevel(code codes.Code) zapcore.Level {
 	return grpc_zap.DefaultCodeToLevel(code)
 }
 
-// AddLogging returns grpc.Server config option that turn on logging.
-func AddLogging(logger *zap.Logger, opts []grpc.ServerOption) []grpc.ServerOption {
-	// Shared options for the logger, with a custom gRPC code to log level function.
-	o := []grpc_zap.Option{
-		grpc_zap.WithLevels(codeToLevel),
+// callFields returns log fields common for all log lines of the call
+func callFields(ctx context.Context, method string) []zapcore.Field {
+	fields := []zapcore.Field{zap.String("grpc.method", method)}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, zap.String("peer.address", p.Addr.String()))
+	}
+
+	// tags added by other interceptors, e.g. request id
+	return append(fields, ctxzap.TagsToFields(ctx)...)
+}
+
+// LoggingOptions configures what is logged about every call
+type LoggingOptions struct {
+	// Payloads turns on logging of request and response messages
+	Payloads bool
+	// Redactor removes sensitive fields from logged messages
+	Redactor *Redactor
+	// ErrorStacks turns on logging of stack traces of server side errors
+	ErrorStacks bool
+}
+
+// stackTracer is implemented by errors that record where they were created
+type stackTracer interface {
+	StackTrace() string
+}
+
+// serverSide reports whether code means failure of the server rather than of the client
+func serverSide(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss:
+		return true
+	}
+	return false
+}
+
+// logCall logs finished call with its status code and duration
+func logCall(ctx context.Context, logger *zap.Logger, o LoggingOptions, method string, start time.Time, err error, payloads ...zapcore.Field) {
+	code := status.Code(err)
+
+	fields := append(callFields(ctx, method),
+		zap.String("grpc.code", code.String()),
+		zap.Float64("grpc.time_ms", float64(time.Since(start).Nanoseconds())/1000000.0),
+	)
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+
+		var st stackTracer
+		if o.ErrorStacks && serverSide(code) && errors.As(err, &st) {
+			fields = append(fields, zap.String("stacktrace", st.StackTrace()))
+		}
+	}
+	fields = append(fields, payloads...)
+
+	logger.Check(codeToLevel(code), "finished call with code "+code.String()).Write(fields...)
+}
+
+// payloadField returns log field with message content, sensitive fields are redacted
+func payloadField(key string, redactor *Redactor, msg interface{}) zapcore.Field {
+	m, ok := redactor.Redact(msg).(proto.Message)
+	if !ok {
+		return zap.Skip()
 	}
+
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		return zap.String(key, "failed to marshal payload -> "+err.Error())
+	}
+
+	return zap.Reflect(key, json.RawMessage(b))
+}
+
+// loggingServerStream logs messages sent and received over the stream
+type loggingServerStream struct {
+	grpc.ServerStream
+	logger   *zap.Logger
+	method   string
+	redactor *Redactor
+}
+
+// SendMsg logs sent message
+func (s *loggingServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.logger.Debug("sent stream message",
+			append(callFields(s.Context(), s.method), payloadField("grpc.response.content", s.redactor, m))...,
+		)
+	}
+	return err
+}
+
+// RecvMsg logs received message
+func (s *loggingServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.logger.Debug("received stream message",
+			append(callFields(s.Context(), s.method), payloadField("grpc.request.content", s.redactor, m))...,
+		)
+	}
+	return err
+}
+
+// AddLogging returns grpc.Server config option that turn on logging.
+// Every call is logged with method, peer, status code and duration,
+// request and response payloads are logged as well if enabled by o.
+func AddLogging(logger *zap.Logger, o LoggingOptions, opts []grpc.ServerOption) []grpc.ServerOption {
 	// Make sure that log statements internal to gRPC library are logged using the zapLogger as well.
-	grpc_zap.ReplaceGrpcLogger(logger)
+	replaceGrpcLogger.Do(func() { grpc_zap.ReplaceGrpcLogger(logger) })
 
 	// Add unary interceptor
-	opts = append(opts, grpc_middleware.WithUnaryServerChain(
+	opts = append(opts, grpc.ChainUnaryInterceptor(
 		grpc_ctxtags.UnaryServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
-		grpc_zap.UnaryServerInterceptor(logger, o...),
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			start := time.Now()
+			resp, err := handler(ctx, req)
+
+			if !o.Payloads {
+				logCall(ctx, logger, o, info.FullMethod, start, err)
+				return resp, err
+			}
+
+			payloads := []zapcore.Field{payloadField("grpc.request.content", o.Redactor, req)}
+			if err == nil {
+				payloads = append(payloads, payloadField("grpc.response.content", o.Redactor, resp))
+			}
+			logCall(ctx, logger, o, info.FullMethod, start, err, payloads...)
+
+			return resp, err
+		},
 	))
 
-	// Add stream interceptor (added as an example here)
-	opts = append(opts, grpc_middleware.WithStreamServerChain(
+	// Add stream interceptor
+	opts = append(opts, grpc.ChainStreamInterceptor(
 		grpc_ctxtags.StreamServerInterceptor(grpc_ctxtags.WithFieldExtractor(grpc_ctxtags.CodeGenRequestFieldExtractor)),
-		grpc_zap.StreamServerInterceptor(logger, o...),
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			start := time.Now()
+
+			if o.Payloads {
+				ss = &loggingServerStream{ServerStream: ss, logger: logger, method: info.FullMethod, redactor: o.Redactor}
+			}
+			err := handler(srv, ss)
+
+			logCall(ss.Context(), logger, o, info.FullMethod, start, err)
+			return err
+		},
 	))
 
 	return opts
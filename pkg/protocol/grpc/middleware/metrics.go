@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+
+	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/maslow123/go-grpc/pkg/protocol/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// AddMetrics returns grpc.Server config option that exports per-method request counts,
+// status codes, latency and message size histograms to Prometheus.
+func AddMetrics(opts []grpc.ServerOption) []grpc.ServerOption {
+	grpc_prometheus.EnableHandlingTimeHistogram()
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		grpc_prometheus.UnaryServerInterceptor,
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			observeSize(metrics.GRPCRequestSize, info.FullMethod, req)
+			resp, err := handler(ctx, req)
+			if err == nil {
+				observeSize(metrics.GRPCResponseSize, info.FullMethod, resp)
+			}
+			return resp, err
+		},
+	))
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		grpc_prometheus.StreamServerInterceptor,
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &sizeServerStream{ServerStream: ss, method: info.FullMethod})
+		},
+	))
+
+	return opts
+}
+
+// InitializeMetrics registers all methods of the server, so their metrics are exported before the first call
+func InitializeMetrics(server *grpc.Server) {
+	grpc_prometheus.Register(server)
+}
+
+// observeSize records wire size of message, non proto messages are skipped
+func observeSize(h *prometheus.HistogramVec, method string, m interface{}) {
+	if msg, ok := m.(proto.Message); ok {
+		h.WithLabelValues(method).Observe(float64(proto.Size(msg)))
+	}
+}
+
+// sizeServerStream records sizes of stream messages
+type sizeServerStream struct {
+	grpc.ServerStream
+	method string
+}
+
+// SendMsg records size of sent message
+func (s *sizeServerStream) SendMsg(m interface{}) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		observeSize(metrics.GRPCResponseSize, s.method, m)
+	}
+	return err
+}
+
+// RecvMsg records size of received message
+func (s *sizeServerStream) RecvMsg(m interface{}) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		observeSize(metrics.GRPCRequestSize, s.method, m)
+	}
+	return err
+}
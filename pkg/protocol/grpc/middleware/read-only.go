@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ReadOnly is switch of read-only mode, it can be flipped while server is running
+type ReadOnly struct {
+	on int32
+}
+
+// Set turns read-only mode on or off
+func (r *ReadOnly) Set(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	atomic.StoreInt32(&r.on, v)
+}
+
+// On reports whether read-only mode is on
+func (r *ReadOnly) On() bool {
+	return atomic.LoadInt32(&r.on) == 1
+}
+
+// errReadOnly is returned to calls of mutations in read-only mode
+var errReadOnly = status.Error(codes.FailedPrecondition,
+	"Service is in read-only mode for maintenance, changes are rejected until it is finished")
+
+// AddReadOnly returns grpc.Server config option that rejects calls of mutations with codes.FailedPrecondition
+// while mode is on, mutations are full names of methods changing data
+func AddReadOnly(mode *ReadOnly, mutations []string, opts []grpc.ServerOption) []grpc.ServerOption {
+	rejected := map[string]bool{}
+	for _, m := range mutations {
+		rejected[m] = true
+	}
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			if rejected[info.FullMethod] && mode.On() {
+				return nil, errReadOnly
+			}
+			return handler(ctx, req)
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if rejected[info.FullMethod] && mode.On() {
+				return errReadOnly
+			}
+			return handler(srv, ss)
+		},
+	))
+
+	return opts
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/maslow123/go-grpc/pkg/errorreport"
+	"github.com/maslow123/go-grpc/pkg/protocol/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AddRecovery returns grpc.Server config option that converts panics in handlers to codes.Internal errors,
+// so a single failing call does not kill the whole server. Panics are sent to reporter if it is not nil.
+func AddRecovery(logger *zap.Logger, reporter errorreport.Reporter, opts []grpc.ServerOption) []grpc.ServerOption {
+	o := []grpc_recovery.Option{
+		grpc_recovery.WithRecoveryHandlerContext(func(ctx context.Context, p interface{}) error {
+			metrics.PanicsTotal.WithLabelValues("grpc").Inc()
+
+			if reporter != nil {
+				method, _ := grpc.Method(ctx)
+				reporter.Report(ctx, errorreport.Event{
+					Panic:     p,
+					Protocol:  "grpc",
+					Method:    method,
+					RequestID: requestID(ctx),
+					Context:   reportContext(ctx, nil, nil),
+				})
+			}
+
+			logger.Error("recovered from panic",
+				append(ctxzap.TagsToFields(ctx),
+					zap.String("panic", fmt.Sprint(p)),
+					zap.Stack("stack"),
+				)...,
+			)
+
+			return status.Error(codes.Internal, "Internal server error")
+		}),
+	}
+
+	opts = append(opts, grpc.ChainUnaryInterceptor(grpc_recovery.UnaryServerInterceptor(o...)))
+	opts = append(opts, grpc.ChainStreamInterceptor(grpc_recovery.StreamServerInterceptor(o...)))
+
+	return opts
+}
@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// redactedValue replaces value of sensitive string fields
+const redactedValue = "[REDACTED]"
+
+// DefaultSensitiveFields is list of fields redacted if nothing else is configured:
+// free form todo text, API key and webhook secrets and push credentials of devices
+var DefaultSensitiveFields = []string{"description", "key", "secret", "token", "p256dh", "auth"}
+
+// Redactor removes sensitive fields from proto messages before they are logged
+type Redactor struct {
+	fields map[string]bool
+}
+
+// NewRedactor creates Redactor for fields given either by name (e.g. "description")
+// or by full name of the field (e.g. "Todo.description")
+func NewRedactor(fields []string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, f := range fields {
+		if len(f) > 0 {
+			r.fields[f] = true
+		}
+	}
+
+	return r
+}
+
+// Redact returns copy of message with sensitive fields redacted, message itself is not modified.
+// Values that are not proto messages are returned as is.
+func (r *Redactor) Redact(v interface{}) interface{} {
+	m, ok := v.(proto.Message)
+	if !ok || len(r.fields) == 0 {
+		return v
+	}
+
+	m = proto.Clone(m)
+	r.redact(m.ProtoReflect())
+
+	return m
+}
+
+// sensitive reports whether field must be redacted
+func (r *Redactor) sensitive(fd protoreflect.FieldDescriptor) bool {
+	return r.fields[string(fd.Name())] || r.fields[string(fd.FullName())]
+}
+
+// redact redacts sensitive fields of message in place
+func (r *Redactor) redact(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case r.sensitive(fd):
+			r.redactField(m, fd, v)
+		case fd.IsMap():
+			if fd.MapValue().Message() != nil {
+				v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+					r.redact(mv.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if fd.Message() != nil {
+				l := v.List()
+				for i := 0; i < l.Len(); i++ {
+					r.redact(l.Get(i).Message())
+				}
+			}
+		case fd.Message() != nil:
+			r.redact(v.Message())
+		}
+		return true
+	})
+}
+
+// redactField replaces strings with placeholder and clears any other value
+func (r *Redactor) redactField(m protoreflect.Message, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	if fd.Kind() != protoreflect.StringKind || fd.IsMap() {
+		m.Clear(fd)
+		return
+	}
+
+	if fd.IsList() {
+		l := v.List()
+		for i := 0; i < l.Len(); i++ {
+			l.Set(i, protoreflect.ValueOfString(redactedValue))
+		}
+		return
+	}
+
+	m.Set(fd, protoreflect.ValueOfString(redactedValue))
+}
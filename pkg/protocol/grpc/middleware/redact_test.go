@@ -0,0 +1,101 @@
+package middleware_test
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+)
+
+func TestRedact(t *testing.T) {
+	reminder := timestamppb.Now()
+
+	tests := []struct {
+		name   string
+		fields []string
+		msg    proto.Message
+		want   proto.Message
+	}{
+		{
+			name:   "nested message",
+			fields: middleware.DefaultSensitiveFields,
+			msg:    &v1.CreateRequest{Api: "v1", Todo: &v1.Todo{Title: "title", Description: "description"}},
+			want:   &v1.CreateRequest{Api: "v1", Todo: &v1.Todo{Title: "title", Description: "[REDACTED]"}},
+		},
+		{
+			name:   "list of messages",
+			fields: middleware.DefaultSensitiveFields,
+			msg:    &v1.ReadAllResponse{Api: "v1", Todos: []*v1.Todo{{Id: 1, Description: "a"}, {Id: 2, Description: "b"}}},
+			want:   &v1.ReadAllResponse{Api: "v1", Todos: []*v1.Todo{{Id: 1, Description: "[REDACTED]"}, {Id: 2, Description: "[REDACTED]"}}},
+		},
+		{
+			name:   "API key",
+			fields: middleware.DefaultSensitiveFields,
+			msg:    &v1.CreateApiKeyResponse{Api: "v1", ApiKey: &v1.ApiKey{Name: "name", Prefix: "abc"}, Key: "abcdef"},
+			want:   &v1.CreateApiKeyResponse{Api: "v1", ApiKey: &v1.ApiKey{Name: "name", Prefix: "abc"}, Key: "[REDACTED]"},
+		},
+		{
+			name:   "list of strings",
+			fields: []string{"scopes"},
+			msg:    &v1.CreateApiKeyRequest{Api: "v1", Name: "name", Scopes: []string{"admin", "todo:read"}},
+			want:   &v1.CreateApiKeyRequest{Api: "v1", Name: "name", Scopes: []string{"[REDACTED]", "[REDACTED]"}},
+		},
+		{
+			name:   "field that is not string is cleared",
+			fields: []string{"reminder"},
+			msg:    &v1.Todo{Title: "title", Reminder: reminder},
+			want:   &v1.Todo{Title: "title"},
+		},
+		{
+			name:   "full name of field of other message",
+			fields: []string{"ApiKey.name"},
+			msg:    &v1.CreateApiKeyRequest{Api: "v1", Name: "name"},
+			want:   &v1.CreateApiKeyRequest{Api: "v1", Name: "name"},
+		},
+		{
+			name:   "full name of nested field",
+			fields: []string{"Todo.title"},
+			msg:    &v1.UpdateRequest{Api: "v1", Todo: &v1.Todo{Title: "title", Description: "description"}},
+			want:   &v1.UpdateRequest{Api: "v1", Todo: &v1.Todo{Title: "[REDACTED]", Description: "description"}},
+		},
+		{
+			name:   "empty field is kept empty",
+			fields: middleware.DefaultSensitiveFields,
+			msg:    &v1.Todo{Title: "title"},
+			want:   &v1.Todo{Title: "title"},
+		},
+		{
+			name:   "no fields",
+			fields: nil,
+			msg:    &v1.Todo{Title: "title", Description: "description"},
+			want:   &v1.Todo{Title: "title", Description: "description"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := proto.Clone(tt.msg)
+
+			got, ok := middleware.NewRedactor(tt.fields).Redact(tt.msg).(proto.Message)
+			if !ok {
+				t.Fatalf("Redact returned %T, proto message expected", got)
+			}
+			if !proto.Equal(got, tt.want) {
+				t.Fatalf("Redact returned %v, %v expected", got, tt.want)
+			}
+			if !proto.Equal(tt.msg, original) {
+				t.Fatalf("Redact modified message to %v", tt.msg)
+			}
+		})
+	}
+}
+
+func TestRedactNotProtoMessage(t *testing.T) {
+	v := map[string]string{"description": "description"}
+	got, ok := middleware.NewRedactor(middleware.DefaultSensitiveFields).Redact(v).(map[string]string)
+	if !ok || got["description"] != "description" {
+		t.Fatalf("Redact returned %v, value as is expected", got)
+	}
+}
@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RequestIDKey is gRPC metadata key that contains request id assigned by HTTP gateway
+const RequestIDKey = "x-request-id"
+
+// requestIDTag is log field name of request id, it matches the HTTP gateway logs
+const requestIDTag = "request-id"
+
+// requestID returns request id from incoming metadata
+func requestID(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(RequestIDKey); len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
+
+// detailedError is error with extra details added to its gRPC status,
+// original error is kept for interceptors that inspect it
+type detailedError struct {
+	cause error
+	st    *status.Status
+}
+
+// Error returns message of the original error
+func (e *detailedError) Error() string {
+	return e.cause.Error()
+}
+
+// GRPCStatus returns gRPC status sent to client
+func (e *detailedError) GRPCStatus() *status.Status {
+	return e.st
+}
+
+// Unwrap returns original error
+func (e *detailedError) Unwrap() error {
+	return e.cause
+}
+
+// withRequestID adds request id to error details
+func withRequestID(err error, id string) error {
+	s := status.Convert(err)
+	if s.Code() == codes.OK {
+		return err
+	}
+
+	ds, dErr := s.WithDetails(&errdetails.RequestInfo{RequestId: id})
+	if dErr != nil {
+		return err
+	}
+
+	return &detailedError{cause: err, st: ds}
+}
+
+// AddRequestID returns grpc.Server config option that adds request id received from HTTP gateway
+// to call logs and to details of returned errors.
+func AddRequestID(opts []grpc.ServerOption) []grpc.ServerOption {
+	opts = append(opts, grpc.ChainUnaryInterceptor(
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			id := requestID(ctx)
+			if len(id) == 0 {
+				return handler(ctx, req)
+			}
+
+			grpc_ctxtags.Extract(ctx).Set(requestIDTag, id)
+			ctx = logger.WithRequestID(ctx, id)
+
+			resp, err := handler(ctx, req)
+			if err != nil {
+				return nil, withRequestID(err, id)
+			}
+			return resp, nil
+		},
+	))
+
+	opts = append(opts, grpc.ChainStreamInterceptor(
+		func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			id := requestID(ss.Context())
+			if len(id) == 0 {
+				return handler(srv, ss)
+			}
+
+			grpc_ctxtags.Extract(ss.Context()).Set(requestIDTag, id)
+			wrapped := grpc_middleware.WrapServerStream(ss)
+			wrapped.WrappedContext = logger.WithRequestID(ss.Context(), id)
+
+			if err := handler(srv, wrapped); err != nil {
+				return withRequestID(err, id)
+			}
+			return nil
+		},
+	))
+
+	return opts
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+)
+
+// AddTracing returns grpc.Server config option that starts span for every call,
+// trace context sent by client in metadata is continued
+func AddTracing(opts []grpc.ServerOption) []grpc.ServerOption {
+	// Add unary interceptor
+	opts = append(opts, grpc.ChainUnaryInterceptor(otelgrpc.UnaryServerInterceptor()))
+
+	// Add stream interceptor
+	opts = append(opts, grpc.ChainStreamInterceptor(otelgrpc.StreamServerInterceptor()))
+
+	return opts
+}
@@ -2,47 +2,330 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
-	"os"
-	"os/signal"
+	"time"
 
 	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/errorrate"
+	"github.com/maslow123/go-grpc/pkg/errorreport"
 	"github.com/maslow123/go-grpc/pkg/logger"
 	"github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+	"go.uber.org/zap"
+	"golang.org/x/net/netutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	// register gzip compressor, so requests of clients compressing them are accepted
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
-// RunServer runs gRPC service to publish Todo Service
-func RunServer(ctx context.Context, v1API v1.TodoServiceServer, port string) error {
-	listen, err := net.Listen("tcp", ":"+port)
-	if err != nil {
-		return err
+// Config is configuration for gRPC server
+type Config struct {
+	// Logger is logger of the server, it is also injected into context of calls. Nothing is logged if nil.
+	Logger *zap.Logger
+	// Port is TCP port to listen on all interfaces
+	Port string
+	// Address is TCP address "host:port" to listen instead of Port, e.g. to bind to loopback interface only
+	Address string
+	// Listeners are served instead of listening on Port if they are not empty,
+	// e.g. to share port with HTTP gateway or to listen on several addresses
+	Listeners []net.Listener
+	// TLSConfig is TLS configuration, connections are served over TLS if it is not nil.
+	// It must be nil if Listeners terminate TLS themselves.
+	TLSConfig *tls.Config
+	// Keepalive configures pinging of idle connections and closing of idle and old connections,
+	// gRPC defaults are used for zero fields
+	Keepalive keepalive.ServerParameters
+	// KeepaliveEnforcement configures how often clients are allowed to ping, gRPC defaults are used for zero fields
+	KeepaliveEnforcement keepalive.EnforcementPolicy
+	// MaxConnections is maximum number of connections served at the same time, it is not limited if 0
+	MaxConnections int
+	// MaxConcurrentStreams is maximum number of calls of single connection handled at the same time,
+	// gRPC default is used if 0
+	MaxConcurrentStreams uint32
+	// MaxClientCalls is maximum number of calls of single client handled at the same time, it is not limited if 0
+	MaxClientCalls int
+	// MaxRecvMsgSize is maximum size of received message in bytes, gRPC default (4MB) is used if 0
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is maximum size of sent message in bytes, gRPC default is used if 0
+	MaxSendMsgSize int
+	// DefaultTimeout is timeout applied to unary calls without deadline, they are not limited if 0
+	DefaultTimeout time.Duration
+	// CompressionThreshold is size in bytes unary responses are gzip compressed from for clients accepting gzip,
+	// responses are compressed only if request is compressed when it is 0
+	CompressionThreshold int
+	// XDS makes server managed by xDS control plane of service mesh (e.g. Traffic Director),
+	// it is bootstrapped from file set by GRPC_XDS_BOOTSTRAP environment variable
+	XDS bool
+	// Reflection turns on reflection service, so tools like grpcurl can discover services without proto files
+	Reflection bool
+	// HMACSecret is shared secret calls must be signed with, calls are not authenticated if empty
+	HMACSecret string
+	// APIKeys looks up scopes of API keys sent by callers in x-api-key metadata. Admin Service calls require
+	// key with "admin" scope, they are all rejected if it is nil.
+	APIKeys middleware.APIKeyLookup
+	// APIKeyAuth requires Todo Service calls to send API key with "todo:write" scope too,
+	// "todo:read" scope is enough for methods free of side effects
+	APIKeyAuth bool
+	// LogPayloads turns on logging of request and response messages
+	LogPayloads bool
+	// RedactFields is list of message fields removed from logged payloads
+	RedactFields []string
+	// LogErrorStacks turns on logging of stack traces of server side errors
+	LogErrorStacks bool
+	// ErrorReporter receives panics and server side errors, they are not reported if nil
+	ErrorReporter errorreport.Reporter
+	// HealthCheck reports whether dependencies of the server (e.g. database) are reachable
+	HealthCheck func(context.Context) error
+	// HealthCheckInterval is how often HealthCheck is run, every 5 seconds if it is not positive
+	HealthCheckInterval time.Duration
+	// ShutdownTimeout is how long in-flight calls are waited for on shutdown before they are cut off,
+	// they are waited for without limit if 0
+	ShutdownTimeout time.Duration
+	// ReadOnly rejects calls of Todo Service methods changing todos while it is on, they are always allowed if nil
+	ReadOnly *middleware.ReadOnly
+	// DatabaseErrors records results of calls using database, they are not recorded if nil
+	DatabaseErrors *errorrate.Tracker
+	// DatabaseCircuit turns on rejecting calls using database while DatabaseErrors is tripped
+	DatabaseCircuit bool
+}
+
+// databaseMethods returns full names of all methods of v1 services except those not using database
+func databaseMethods() []string {
+	var methods []string
+	services := v1.File_todo_service_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		sd := services.Get(i)
+		for j := 0; j < sd.Methods().Len(); j++ {
+			name := sd.Methods().Get(j).Name()
+			if name == "GetVersion" {
+				continue
+			}
+			methods = append(methods, "/"+string(sd.FullName())+"/"+string(name))
+		}
+	}
+
+	return methods
+}
+
+// mutationMethods returns full names of Todo Service methods that are not marked free of side effects,
+// Admin Service methods are left out, so operators can e.g. revoke API keys in read-only mode
+func mutationMethods() []string {
+	var methods []string
+	services := v1.File_todo_service_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		sd := services.Get(i)
+		if sd.Name() != "TodoService" {
+			continue
+		}
+		for j := 0; j < sd.Methods().Len(); j++ {
+			md := sd.Methods().Get(j)
+			if opts, ok := md.Options().(*descriptorpb.MethodOptions); ok &&
+				opts.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS {
+				continue
+			}
+			methods = append(methods, "/"+string(sd.FullName())+"/"+string(md.Name()))
+		}
+	}
+
+	return methods
+}
+
+// apiKeyScopes returns scopes accepted for methods of v1 services requiring API key, Todo Service methods
+// except GetVersion require it only if todo is true
+func apiKeyScopes(todo bool) map[string][]string {
+	scopes := map[string][]string{}
+	services := v1.File_todo_service_proto.Services()
+	for i := 0; i < services.Len(); i++ {
+		sd := services.Get(i)
+		admin := sd.Name() == "AdminService"
+		if !admin && !todo {
+			continue
+		}
+		for j := 0; j < sd.Methods().Len(); j++ {
+			md := sd.Methods().Get(j)
+			name := "/" + string(sd.FullName()) + "/" + string(md.Name())
+			options, _ := md.Options().(*descriptorpb.MethodOptions)
+			switch {
+			case admin:
+				scopes[name] = []string{"admin"}
+			case md.Name() == "GetVersion":
+			case options.GetIdempotencyLevel() == descriptorpb.MethodOptions_NO_SIDE_EFFECTS:
+				scopes[name] = []string{"todo:read", "todo:write"}
+			default:
+				scopes[name] = []string{"todo:write"}
+			}
+		}
+	}
+
+	return scopes
+}
+
+// RunServer runs gRPC service to publish Todo Service and Admin Service until ctx is done,
+// then it waits for in-flight calls to finish
+func RunServer(ctx context.Context, v1API v1.TodoServiceServer, v1AdminAPI v1.AdminServiceServer, cfg Config) error {
+	log := logger.OrNop(cfg.Logger)
+
+	listeners := cfg.Listeners
+	if len(listeners) == 0 {
+		address := cfg.Address
+		if len(address) == 0 {
+			address = ":" + cfg.Port
+		}
+		listen, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		listeners = []net.Listener{listen}
+	}
+	if cfg.MaxConnections > 0 {
+		for i, listen := range listeners {
+			listeners[i] = netutil.LimitListener(listen, cfg.MaxConnections)
+		}
 	}
 
 	// gRPC server startup options
-	opts := []grpc.ServerOption{}
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(cfg.Keepalive),
+		grpc.KeepaliveEnforcementPolicy(cfg.KeepaliveEnforcement),
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams))
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	var creds credentials.TransportCredentials
+	if cfg.TLSConfig != nil {
+		creds = credentials.NewTLS(cfg.TLSConfig)
+	}
 
 	// add middleware
-	opts = middleware.AddLogging(logger.Log, opts)
+	calls := &inFlight{}
+	opts = calls.addInterceptors(opts)
+	redactor := middleware.NewRedactor(cfg.RedactFields)
+	opts = middleware.AddTracing(opts)
+	opts = middleware.AddLogging(log, middleware.LoggingOptions{
+		Payloads:    cfg.LogPayloads,
+		Redactor:    redactor,
+		ErrorStacks: cfg.LogErrorStacks,
+	}, opts)
+	opts = middleware.AddRequestID(opts)
+	opts = middleware.AddContextLogger(log, opts)
+	if cfg.MaxClientCalls > 0 {
+		opts = middleware.AddClientConcurrencyLimit(cfg.MaxClientCalls, opts)
+	}
+	if cfg.DefaultTimeout > 0 {
+		opts = middleware.AddDefaultDeadline(cfg.DefaultTimeout, opts)
+	}
+	opts = middleware.AddMetrics(opts)
+	if cfg.CompressionThreshold > 0 {
+		opts = middleware.AddCompression(cfg.CompressionThreshold, opts)
+	}
+	if len(cfg.HMACSecret) > 0 {
+		opts = middleware.AddSignatureAuth(cfg.HMACSecret, opts)
+	}
+	apiKeys := cfg.APIKeys
+	if apiKeys == nil {
+		apiKeys = func(context.Context, string) ([]string, error) {
+			return nil, status.Error(codes.Unauthenticated, "API keys are not configured")
+		}
+	}
+	opts = middleware.AddAPIKeyAuth(apiKeys, apiKeyScopes(cfg.APIKeyAuth), opts)
+	if cfg.ReadOnly != nil {
+		opts = middleware.AddReadOnly(cfg.ReadOnly, mutationMethods(), opts)
+	}
+	if cfg.DatabaseErrors != nil {
+		opts = middleware.AddDatabaseTracking(middleware.DatabaseTracking{
+			Tracker: cfg.DatabaseErrors,
+			Circuit: cfg.DatabaseCircuit,
+			Methods: databaseMethods(),
+		}, opts)
+	}
+	opts = middleware.AddRecovery(log, cfg.ErrorReporter, opts)
+	if cfg.ErrorReporter != nil {
+		opts = middleware.AddErrorReporting(cfg.ErrorReporter, redactor, opts)
+	}
 
 	// register service
-	server := grpc.NewServer(opts...)
-	v1.RegisterTodoServiceServer(server, v1API)
+	server := newServer(cfg.XDS, creds, opts)
+	v1.RegisterServices(server, v1API, v1AdminAPI)
+	if s, ok := server.(*grpc.Server); ok {
+		middleware.InitializeMetrics(s)
+	}
+	if cfg.Reflection {
+		reflection.Register(server)
+	}
 
-	// graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	// register health service, status is flipped by health checks and shutdown
+	hs := health.NewServer()
+	healthpb.RegisterHealthServer(server, hs)
+	setServingStatus(hs, server, healthpb.HealthCheckResponse_SERVING)
+	if cfg.HealthCheck != nil {
+		go watchHealth(ctx, log, hs, server, cfg.HealthCheck, cfg.HealthCheckInterval)
+	}
 
+	// graceful shutdown
+	stopped := make(chan struct{})
 	go func() {
-		for range c {
-			// sig is a ^c, handle it
-			logger.Log.Warn("Shutting down gRPC server...")
-			server.GracefulStop()
-			<-ctx.Done()
-		}
+		<-ctx.Done()
+
+		log.Warn("Shutting down gRPC server...")
+		hs.Shutdown()
+		gracefulStop(log, server, calls, cfg.ShutdownTimeout)
+		close(stopped)
 	}()
 
 	// start gRPC server
-	logger.Log.Info("Starting gRPC server...")
-	return server.Serve(listen)
+	log.Info("Starting gRPC server...")
+	errs := make(chan error, len(listeners))
+	for _, listen := range listeners {
+		go func(listen net.Listener) {
+			errs <- server.Serve(listen)
+		}(listen)
+	}
+	for range listeners {
+		if err := <-errs; err != nil {
+			server.Stop()
+			return err
+		}
+	}
+
+	// listeners are closed, wait for in-flight calls
+	<-stopped
+	return nil
+}
+
+// gracefulStop stops server waiting for in-flight calls, they are cut off after timeout if it is not 0
+func gracefulStop(log *zap.Logger, server server, calls *inFlight, timeout time.Duration) {
+	if timeout <= 0 {
+		server.GracefulStop()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Warn("Shutdown timeout exceeded, stopping gRPC server forcibly",
+			zap.Int64("cut-off-calls", calls.count()),
+		)
+		server.Stop()
+		<-done
+	}
 }
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	xdscreds "google.golang.org/grpc/credentials/xds"
+	"google.golang.org/grpc/xds"
+)
+
+// server is gRPC server created by grpc.NewServer or by xds.NewGRPCServer
+type server interface {
+	grpc.ServiceRegistrar
+	GetServiceInfo() map[string]grpc.ServiceInfo
+	Serve(net.Listener) error
+	Stop()
+	GracefulStop()
+}
+
+// newServer creates gRPC server, xDS server gets its listener configuration, security and routing
+// from xDS control plane of the mesh, it is bootstrapped from file set by GRPC_XDS_BOOTSTRAP variable.
+// Connections of xDS server fall back to creds (plain text if nil) until mesh security is configured.
+func newServer(useXDS bool, creds credentials.TransportCredentials, opts []grpc.ServerOption) server {
+	if !useXDS {
+		if creds != nil {
+			opts = append(opts, grpc.Creds(creds))
+		}
+		return grpc.NewServer(opts...)
+	}
+
+	if creds == nil {
+		creds = insecure.NewCredentials()
+	}
+	xc, err := xdscreds.NewServerCredentials(xdscreds.ServerOptions{FallbackCreds: creds})
+	if err != nil {
+		// it fails only if fallback credentials are not set
+		panic(err)
+	}
+	return xds.NewGRPCServer(append(opts, grpc.Creds(xc))...)
+}
@@ -0,0 +1,98 @@
+package listen
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/pires/go-proxyproto"
+)
+
+// unixScheme is prefix of Unix domain socket addresses
+const unixScheme = "unix://"
+
+// socketMode is permission of socket files, clients must be owner or in group of the server user
+const socketMode = 0660
+
+// IsUnix reports whether address is Unix domain socket address, e.g. "unix:///var/run/todo.sock"
+func IsUnix(address string) bool {
+	return strings.HasPrefix(address, unixScheme)
+}
+
+// Listen listens on Unix domain socket address "unix:///path/to.sock" or TCP address "host:port".
+// Socket file left by previous run is removed, the file is removed again when listener is closed.
+func Listen(address string) (net.Listener, error) {
+	if !IsUnix(address) {
+		return net.Listen("tcp", address)
+	}
+
+	path := strings.TrimPrefix(address, unixScheme)
+	if fi, err := os.Stat(path); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// TLS terminates TLS of connections accepted by l, both HTTP/2 and HTTP/1.1 are negotiated
+func TLS(l net.Listener, tlsConfig *tls.Config) net.Listener {
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = []string{"h2", "http/1.1"}
+	return tls.NewListener(l, tlsConfig)
+}
+
+// decrypted is connection TLS was terminated on before it was passed to server as plain text connection
+type decrypted struct {
+	net.Conn
+}
+
+// Decrypted marks connection c TLS was terminated on, e.g. before shared port was split between servers
+func Decrypted(c net.Conn) net.Conn {
+	return decrypted{Conn: c}
+}
+
+// IsTLS reports whether c is TLS connection or it is marked by Decrypted
+func IsTLS(c net.Conn) bool {
+	switch c.(type) {
+	case *tls.Conn, decrypted:
+		return true
+	}
+	return false
+}
+
+// ProxyProtocol makes l read PROXY protocol v1/v2 headers sent by load balancers in TCP mode,
+// so remote addresses of connections are addresses of clients instead of load balancers.
+// Headers are read only from connections of trusted CIDRs or IP addresses, they are ignored from other peers,
+// so clients cannot spoof their addresses. Trusted must not be empty.
+// Connections without header are served as they are, e.g. calls forwarded by HTTP gateway.
+func ProxyProtocol(l net.Listener, trusted []string) (net.Listener, error) {
+	var cidrs []string
+	for _, c := range trusted {
+		if c = strings.TrimSpace(c); len(c) > 0 {
+			cidrs = append(cidrs, c)
+		}
+	}
+
+	if len(cidrs) == 0 {
+		return nil, errors.New("trusted CIDRs of load balancers are required")
+	}
+	policy, err := proxyproto.LaxWhiteListPolicy(cidrs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxyproto.Listener{Listener: l, Policy: policy}, nil
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// SizeBuckets are histogram buckets of payload sizes from 64 bytes to 4 megabytes
+var SizeBuckets = prometheus.ExponentialBuckets(64, 4, 9)
+
+// GRPCRequestSize is size histogram of received gRPC messages by full method name
+var GRPCRequestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_server_msg_received_size_bytes",
+	Help:    "Size of messages received by the gRPC server.",
+	Buckets: SizeBuckets,
+}, []string{"grpc_method"})
+
+// GRPCResponseSize is size histogram of sent gRPC messages by full method name
+var GRPCResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "grpc_server_msg_sent_size_bytes",
+	Help:    "Size of messages sent by the gRPC server.",
+	Buckets: SizeBuckets,
+}, []string{"grpc_method"})
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// HTTPRequestsTotal counts HTTP gateway requests by method, route template and status class (e.g. 2xx)
+var HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "http_server_requests_total",
+	Help: "Total number of HTTP requests completed by the gateway.",
+}, []string{"method", "route", "status_class"})
+
+// HTTPRequestDuration is latency histogram of HTTP gateway requests by method and route template
+var HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_server_request_duration_seconds",
+	Help:    "Latency of HTTP requests handled by the gateway.",
+	Buckets: prometheus.DefBuckets,
+}, []string{"method", "route"})
+
+// HTTPRequestSize is body size histogram of HTTP gateway requests by method and route template
+var HTTPRequestSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_server_request_size_bytes",
+	Help:    "Size of HTTP request bodies received by the gateway.",
+	Buckets: SizeBuckets,
+}, []string{"method", "route"})
+
+// HTTPResponseSize is body size histogram of HTTP gateway responses by method and route template
+var HTTPResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "http_server_response_size_bytes",
+	Help:    "Size of HTTP response bodies sent by the gateway.",
+	Buckets: SizeBuckets,
+}, []string{"method", "route"})
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// JobRunsTotal counts runs of background jobs by job name and result (success, failure or panic)
+var JobRunsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "todo_job_runs_total",
+	Help: "Total number of completed runs of background jobs.",
+}, []string{"job", "result"})
+
+// JobDuration is duration histogram of background job runs by job name
+var JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "todo_job_duration_seconds",
+	Help:    "Duration of runs of background jobs.",
+	Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+}, []string{"job"})
+
+// JobLastSuccess is Unix time of the last successful run of background job by job name
+var JobLastSuccess = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "todo_job_last_success_timestamp_seconds",
+	Help: "Unix time of the last successful run of background job.",
+}, []string{"job"})
+
+// JobRunning is 1 while background job is running, by job name
+var JobRunning = promauto.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "todo_job_running",
+	Help: "Whether background job is running.",
+}, []string{"job"})
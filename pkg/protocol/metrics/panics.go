@@ -0,0 +1,12 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// PanicsTotal counts panics recovered in request handlers by protocol (grpc or http)
+var PanicsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "todo_panics_recovered_total",
+	Help: "Total number of panics recovered in request handlers.",
+}, []string{"protocol"})
@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/prometheus/procfs"
+	"go.uber.org/zap"
+)
+
+// LogRuntimeStats logs goroutine count, heap usage, GC pause and open file descriptors
+// to log every interval until ctx is done
+func LogRuntimeStats(ctx context.Context, log *zap.Logger, interval time.Duration) {
+	log = logger.OrNop(log)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			log.Info("Runtime stats", runtimeStats()...)
+		}
+	}
+}
+
+// runtimeStats returns log fields of current runtime stats
+func runtimeStats() []zap.Field {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	fields := []zap.Field{
+		zap.Int("goroutines", runtime.NumGoroutine()),
+		zap.Uint64("heap-alloc-bytes", m.HeapAlloc),
+		zap.Uint64("heap-sys-bytes", m.HeapSys),
+		zap.Uint64("heap-objects", m.HeapObjects),
+		zap.Uint32("gc-count", m.NumGC),
+		zap.Duration("gc-last-pause", time.Duration(m.PauseNs[(m.NumGC+255)%256])),
+		zap.Duration("gc-pause-total", time.Duration(m.PauseTotalNs)),
+	}
+
+	// file descriptors are only available on Linux
+	if p, err := procfs.Self(); err == nil {
+		if n, err := p.FileDescriptorsLen(); err == nil {
+			fields = append(fields, zap.Int("open-fds", n))
+		}
+		if l, err := p.Limits(); err == nil {
+			fields = append(fields, zap.Uint64("max-fds", l.OpenFiles))
+		}
+	}
+
+	return fields
+}
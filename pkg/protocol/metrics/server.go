@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+// RunServer runs HTTP server to publish Prometheus metrics at /metrics
+func RunServer(ctx context.Context, port string, log *zap.Logger) error {
+	mux := http.NewServeMux()
+	// default registry also exports Go runtime metrics (goroutines, GC pauses, heap usage)
+	// and process metrics (open file descriptors, resident memory)
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:    ":" + port,
+		Handler: mux,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(ctx)
+	}()
+
+	logger.OrNop(log).Info("Starting metrics server...")
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/maslow123/go-grpc/api/swagger"
+)
+
+// swaggerJSON serves OpenAPI spec of the API
+func swaggerJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(swagger.Spec)
+}
+
+// docs serves Swagger UI page exploring the API
+func docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(swagger.UI)
+}
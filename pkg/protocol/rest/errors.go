@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorBody is JSON envelope of error responses
+type errorBody struct {
+	Error errorStatus `json:"error"`
+}
+
+// errorStatus is gRPC status of failed request
+type errorStatus struct {
+	// Code is canonical name of gRPC status code, e.g. "NOT_FOUND"
+	Code string `json:"code"`
+	// Message is error message for developers
+	Message string `json:"message"`
+	// Details are status details, e.g. google.rpc.ErrorInfo with machine-readable reason
+	Details []json.RawMessage `json:"details"`
+	// RequestID is id of the request to correlate it with server logs
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// fallbackError is sent if error envelope cannot be marshaled
+const fallbackError = `{"error": {"code": "INTERNAL", "message": "failed to marshal error", "details": []}}`
+
+// newErrorHandler returns handler writing gRPC status of failed request as JSON error envelope
+// with matching HTTP status code, response metadata is sent as headers named by outgoing
+func newErrorHandler(outgoing runtime.HeaderMatcherFunc) runtime.ProtoErrorHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+		writeError(ctx, outgoing, marshaler, w, r, err)
+	}
+}
+
+// writeError writes error envelope of err
+func writeError(ctx context.Context, outgoing runtime.HeaderMatcherFunc, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	s, ok := status.FromError(err)
+	if !ok {
+		s = status.New(codes.Unknown, err.Error())
+	}
+
+	body := errorBody{Error: errorStatus{
+		Code:      code.Code_name[int32(s.Code())],
+		Message:   s.Message(),
+		Details:   []json.RawMessage{},
+		RequestID: middleware.GetReqID(r.Context()),
+	}}
+	for _, d := range s.Proto().GetDetails() {
+		b, err := marshaler.Marshal(d)
+		if err != nil {
+			logger.FromContext(r.Context()).Warn("Failed to marshal error detail", zap.String("type", d.GetTypeUrl()), zap.Error(err))
+			continue
+		}
+		body.Error.Details = append(body.Error.Details, b)
+	}
+
+	// headers sent by gRPC server are forwarded like in successful responses
+	if md, ok := runtime.ServerMetadataFromContext(ctx); ok {
+		for k, vs := range md.HeaderMD {
+			h, ok := outgoing(k)
+			if !ok {
+				continue
+			}
+			for _, v := range vs {
+				w.Header().Add(h, v)
+			}
+		}
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Set("Content-Type", "application/json")
+
+	buf, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(fallbackError))
+		return
+	}
+
+	w.WriteHeader(runtime.HTTPStatusFromCode(s.Code()))
+	_, _ = w.Write(buf)
+}
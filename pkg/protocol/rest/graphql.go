@@ -0,0 +1,17 @@
+package rest
+
+import (
+	"net/http"
+
+	grpcmiddleware "github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+	"github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+	"google.golang.org/grpc/metadata"
+)
+
+// forwardRequestID forwards request id to gRPC server in calls made by h, so both sides log it
+func forwardRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := metadata.AppendToOutgoingContext(r.Context(), grpcmiddleware.RequestIDKey, middleware.GetReqID(r.Context()))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,51 @@
+package rest
+
+import (
+	"net/textproto"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	grpcmiddleware "github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+)
+
+// incomingHeaderMatcher forwards request headers to gRPC metadata with lower case names, e.g. X-Tenant-ID
+// becomes x-tenant-id. Other headers are forwarded like by the default matcher.
+// X-Api-Key header is always forwarded, so Admin Service is reachable through the gateway.
+// Request id is always assigned by the gateway, so X-Request-ID header of clients is not forwarded.
+func incomingHeaderMatcher(headers []string) runtime.HeaderMatcherFunc {
+	forward := map[string]bool{}
+	for _, h := range headers {
+		if h = strings.TrimSpace(h); len(h) > 0 {
+			forward[textproto.CanonicalMIMEHeaderKey(h)] = true
+		}
+	}
+
+	return func(key string) (string, bool) {
+		name := strings.ToLower(key)
+		if name == grpcmiddleware.RequestIDKey {
+			return "", false
+		}
+		if name == grpcmiddleware.APIKeyKey || forward[textproto.CanonicalMIMEHeaderKey(key)] {
+			return name, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}
+}
+
+// outgoingHeaderMatcher sends gRPC response metadata keys as response headers of the same name,
+// other keys are sent with Grpc-Metadata- prefix like by the default matcher
+func outgoingHeaderMatcher(keys []string) runtime.HeaderMatcherFunc {
+	send := map[string]bool{}
+	for _, k := range keys {
+		if k = strings.TrimSpace(k); len(k) > 0 {
+			send[strings.ToLower(k)] = true
+		}
+	}
+
+	return func(key string) (string, bool) {
+		if send[strings.ToLower(key)] {
+			return textproto.CanonicalMIMEHeaderKey(key), true
+		}
+		return runtime.MetadataHeaderPrefix + key, true
+	}
+}
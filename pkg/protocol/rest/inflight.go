@@ -0,0 +1,26 @@
+package rest
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// inFlight counts requests being handled, it tells how many requests are cut off by forced shutdown
+type inFlight struct {
+	n int64
+}
+
+// count returns number of requests being handled
+func (f *inFlight) count() int64 {
+	return atomic.LoadInt64(&f.n)
+}
+
+// handler returns h counting requests being handled
+func (f *inFlight) handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&f.n, 1)
+		defer atomic.AddInt64(&f.n, -1)
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,28 @@
+package rest
+
+import (
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+)
+
+// MarshalOptions configures JSON shape of gateway responses
+type MarshalOptions struct {
+	// EmitUnpopulated turns on sending fields with zero values, they are omitted otherwise
+	EmitUnpopulated bool
+	// CamelCase makes field names lowerCamelCase JSON names, proto field names are used otherwise
+	CamelCase bool
+	// EnumsAsInts makes enum values numbers, they are names otherwise
+	EnumsAsInts bool
+	// Indent is indentation of nested values, e.g. to make responses readable during development.
+	// Responses are compact if it is empty.
+	Indent string
+}
+
+// marshaler returns JSON marshaler of requests and responses, both naming styles are accepted in requests
+func (o MarshalOptions) marshaler() runtime.Marshaler {
+	return &runtime.JSONPb{
+		OrigName:     !o.CamelCase,
+		EmitDefaults: o.EmitUnpopulated,
+		EnumsAsInts:  o.EnumsAsInts,
+		Indent:       o.Indent,
+	}
+}
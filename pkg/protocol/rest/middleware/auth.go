@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/auth"
+)
+
+// signatureTolerance is maximum allowed clock difference between caller and server
+const signatureTolerance = 5 * time.Minute
+
+// AddSignatureAuth requires every HTTP request to be signed with shared secret.
+// Signed target is HTTP method and request URI, e.g. "POST /v1/todo".
+// Body is read before signature is verified, requests with body longer than maxBodySize bytes are rejected.
+func AddSignatureAuth(secret string, maxBodySize int64, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > maxBodySize {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		r.Body.Close()
+		if err != nil {
+			// limited reader fails once it read maxBodySize bytes of longer body
+			if int64(len(body)) == maxBodySize {
+				http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+				return
+			}
+			http.Error(w, "failed to read request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if err := auth.Verify(
+			secret,
+			r.Header.Get(auth.SignatureHeader),
+			r.Header.Get(auth.TimestampHeader),
+			r.Method+" "+r.RequestURI,
+			body,
+			signatureTolerance,
+			time.Now(),
+		); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
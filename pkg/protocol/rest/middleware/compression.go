@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CompressionOptions configures response compression
+type CompressionOptions struct {
+	// MinSize is size in bytes responses are compressed from
+	MinSize int
+	// ContentTypes is list of media types compressed responses may have, e.g. "application/json"
+	ContentTypes []string
+}
+
+// compressors are reused, they allocate large buffers
+var (
+	gzipWriters = sync.Pool{New: func() interface{} {
+		return gzip.NewWriter(io.Discard)
+	}}
+	flateWriters = sync.Pool{New: func() interface{} {
+		w, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return w
+	}}
+)
+
+// compressor is gzip or flate writer
+type compressor interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// AddCompression compresses responses with gzip or deflate according to Accept-Encoding of the request,
+// responses smaller than MinSize or of other media types than ContentTypes are sent as they are
+func AddCompression(o CompressionOptions, h http.Handler) http.Handler {
+	types := make(map[string]bool, len(o.ContentTypes))
+	for _, t := range o.ContentTypes {
+		types[strings.ToLower(strings.TrimSpace(t))] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if len(encoding) == 0 || r.Method == http.MethodHead {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: o.MinSize, types: types}
+		defer cw.Close()
+
+		h.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding returns "gzip" or "deflate" if client accepts it, gzip is preferred
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.SplitN(part, ";", 2)
+		if len(fields) == 2 {
+			q := strings.TrimSpace(fields[1])
+			if v, err := strconv.ParseFloat(strings.TrimPrefix(q, "q="), 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[strings.ToLower(strings.TrimSpace(fields[0]))] = true
+	}
+
+	switch {
+	case accepted["gzip"] || accepted["*"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressWriter buffers response until MinSize bytes are written, then it decides whether it is compressed
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+	types    map[string]bool
+
+	status  int
+	buf     []byte
+	decided bool
+	c       compressor
+}
+
+// WriteHeader defers sending of headers until it is decided whether response is compressed
+func (w *compressWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers b until response is MinSize bytes long
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.minSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+
+	if w.c != nil {
+		return w.c.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide starts compression if compress is true and response may be compressed,
+// then it sends headers and buffered bytes
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	hdr := w.Header()
+	if compress && w.compressible() {
+		hdr.Set("Content-Encoding", w.encoding)
+		hdr.Del("Content-Length")
+
+		if w.encoding == "gzip" {
+			w.c = gzipWriters.Get().(*gzip.Writer)
+		} else {
+			w.c = flateWriters.Get().(*flate.Writer)
+		}
+		w.c.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if w.c != nil {
+		_, err := w.c.Write(buf)
+		return err
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// compressible reports whether response has status, media type and encoding allowing compression
+func (w *compressWriter) compressible() bool {
+	if w.status < http.StatusOK || w.status == http.StatusNoContent || w.status == http.StatusNotModified {
+		return false
+	}
+
+	hdr := w.Header()
+	if len(hdr.Get("Content-Encoding")) > 0 {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(hdr.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return w.types[strings.ToLower(mediaType)]
+}
+
+// Flush sends buffered data to client, streaming responses are compressed regardless of their size
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+	if w.c != nil {
+		_ = w.c.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close sends rest of the response, small responses are sent uncompressed
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		return w.decide(false)
+	}
+	if w.c == nil {
+		return nil
+	}
+
+	err := w.c.Close()
+	switch c := w.c.(type) {
+	case *gzip.Writer:
+		gzipWriters.Put(c)
+	case *flate.Writer:
+		flateWriters.Put(c)
+	}
+	w.c = nil
+	return err
+}
+
+// Unwrap returns original ResponseWriter
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// tlsKey is context key of connections TLS was terminated on before they reached HTTP server
+type tlsKey struct{}
+
+// WithTLS marks ctx of connection TLS was terminated on, e.g. by listener splitting shared port,
+// so requests received over it are treated as sent over TLS
+func WithTLS(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tlsKey{}, true)
+}
+
+// isTLS reports whether r was sent over TLS
+func isTLS(r *http.Request) bool {
+	on, _ := r.Context().Value(tlsKey{}).(bool)
+	return r.TLS != nil || on
+}
+
+// AddHSTS sets Strict-Transport-Security header on responses sent over TLS,
+// so browsers use HTTPS only for maxAge
+func AddHSTS(maxAge time.Duration, includeSubdomains bool, h http.Handler) http.Handler {
+	value := fmt.Sprintf("max-age=%d", int64(maxAge/time.Second))
+	if includeSubdomains {
+		value += "; includeSubDomains"
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isTLS(r) {
+			w.Header().Set("Strict-Transport-Security", value)
+		}
+		h.ServeHTTP(w, r)
+	})
+}
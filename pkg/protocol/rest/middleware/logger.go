@@ -1,6 +1,9 @@
 package middleware
 
 import (
+	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -8,7 +11,26 @@ import (
 	"go.uber.org/zap"
 )
 
-func AddLogger(logger *zap.Logger, h http.Handler) http.Handler {
+// Access log formats
+const (
+	// AccessLogJSON logs every request as structured zap entry
+	AccessLogJSON = "json"
+	// AccessLogCombined writes Apache combined log format lines extended with request id and latency
+	AccessLogCombined = "combined"
+)
+
+// AccessLogOptions configures access log
+type AccessLogOptions struct {
+	// Format is one of AccessLogJSON or AccessLogCombined
+	Format string
+	// Routes resolves route templates of requests
+	Routes *Routes
+	// Output is where AccessLogCombined lines are written to, they are logged as messages of the logger if nil
+	Output io.Writer
+}
+
+// AddLogger logs every HTTP request with its response status code, bytes written and latency
+func AddLogger(logger *zap.Logger, o AccessLogOptions, h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
@@ -25,37 +47,76 @@ func AddLogger(logger *zap.Logger, h http.Handler) http.Handler {
 			scheme = "https"
 		}
 
-		proto := r.Proto
-		method := r.Method
-		remoteAddr := r.RemoteAddr
-		userAgent := r.UserAgent()
 		uri := strings.Join([]string{scheme, "://", r.Host, r.RequestURI}, "")
-
-		// Log HTTP request
-		logger.Debug("request started",
-			zap.String("request-id", id),
-			zap.String("htt-scheme", scheme),
-			zap.String("http-proto", proto),
-			zap.String("http-method", method),
-			zap.String("remote-addr", remoteAddr),
-			zap.String("user-agent", userAgent),
-			zap.String("uri", uri),
-		)
+		route := o.Routes.Match(r.Method, r.URL.Path)
 
 		t1 := time.Now()
+		rw := newResponseWriter(w)
+
+		h.ServeHTTP(rw, r)
 
-		h.ServeHTTP(w, r)
+		elapsed := time.Since(t1)
+
+		if o.Format == AccessLogCombined {
+			line := combinedLine(r, rw, id, t1, elapsed)
+			if o.Output == nil {
+				logger.Info(line)
+				return
+			}
+			fmt.Fprintln(o.Output, line)
+			return
+		}
 
 		// Log HTTP response
-		logger.Debug("request completed",
+		logger.Info("request completed",
 			zap.String("request-id", id),
-			zap.String("htt-scheme", scheme),
-			zap.String("http-proto", proto),
-			zap.String("http-method", method),
-			zap.String("remote-addr", remoteAddr),
-			zap.String("user-agent", userAgent),
+			zap.String("http-scheme", scheme),
+			zap.String("http-proto", r.Proto),
+			zap.String("http-method", r.Method),
+			zap.String("http-route", route),
+			zap.Int("http-status", rw.status),
+			zap.Int64("bytes-written", rw.bytes),
+			zap.String("remote-addr", r.RemoteAddr),
+			zap.String("user-agent", r.UserAgent()),
 			zap.String("uri", uri),
-			zap.Float64("elapsed-ms", float64(time.Since(t1).Nanoseconds())/1000000.0),
+			zap.Float64("elapsed-ms", float64(elapsed.Nanoseconds())/1000000.0),
 		)
 	})
 }
+
+// combinedLine returns Apache combined log format line followed by request id and latency in milliseconds
+func combinedLine(r *http.Request, rw *responseWriter, id string, t time.Time, elapsed time.Duration) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	user := "-"
+	if u, _, ok := r.BasicAuth(); ok && len(u) > 0 {
+		user = u
+	}
+
+	if len(id) == 0 {
+		id = "-"
+	}
+
+	size := "-"
+	if rw.bytes > 0 {
+		size = fmt.Sprint(rw.bytes)
+	}
+
+	return fmt.Sprintf("%s - %s [%s] \"%s %s %s\" %d %s %q %q %s %.3f",
+		host,
+		user,
+		t.Format("02/Jan/2006:15:04:05 -0700"),
+		r.Method,
+		r.RequestURI,
+		r.Proto,
+		rw.status,
+		size,
+		r.Referer(),
+		r.UserAgent(),
+		id,
+		float64(elapsed.Nanoseconds())/1000000.0,
+	)
+}
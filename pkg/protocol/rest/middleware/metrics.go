@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/protocol/metrics"
+)
+
+// unmatchedRoute is route label of requests not matching any route, it keeps
+// label cardinality bounded when clients probe random paths
+const unmatchedRoute = "other"
+
+// countingReader counts bytes read from request body
+type countingReader struct {
+	io.ReadCloser
+	bytes int64
+}
+
+// Read records number of bytes read
+func (r *countingReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// AddMetrics exports request counts by status class, latency and body size histograms
+// of every request to Prometheus, grouped by route template
+func AddMetrics(routes *Routes, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := routes.Match(r.Method, r.URL.Path)
+		if len(route) == 0 {
+			route = unmatchedRoute
+		}
+
+		t1 := time.Now()
+		rw := newResponseWriter(w)
+
+		// count body bytes actually read, Content-Length is not sent with chunked bodies
+		body := &countingReader{ReadCloser: r.Body}
+		r.Body = body
+
+		h.ServeHTTP(rw, r)
+
+		class := strconv.Itoa(rw.status/100) + "xx"
+		metrics.HTTPRequestsTotal.WithLabelValues(r.Method, route, class).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(r.Method, route).Observe(time.Since(t1).Seconds())
+		metrics.HTTPRequestSize.WithLabelValues(r.Method, route).Observe(float64(body.bytes))
+		metrics.HTTPResponseSize.WithLabelValues(r.Method, route).Observe(float64(rw.bytes))
+	})
+}
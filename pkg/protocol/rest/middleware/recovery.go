@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/maslow123/go-grpc/pkg/errorreport"
+	"github.com/maslow123/go-grpc/pkg/protocol/metrics"
+	"go.uber.org/zap"
+)
+
+// AddRecovery converts panics in handlers to 500 Internal Server Error responses,
+// panics are sent to reporter if it is not nil
+func AddRecovery(logger *zap.Logger, reporter errorreport.Reporter, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+
+			// client went away, let net/http abort the response silently
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			metrics.PanicsTotal.WithLabelValues("http").Inc()
+
+			if reporter != nil {
+				reporter.Report(r.Context(), errorreport.Event{
+					Panic:     p,
+					Protocol:  "http",
+					Method:    r.Method + " " + r.URL.Path,
+					RequestID: GetReqID(r.Context()),
+					Context: map[string]interface{}{
+						"remote-addr": r.RemoteAddr,
+						"user-agent":  r.UserAgent(),
+					},
+				})
+			}
+
+			logger.Error("recovered from panic",
+				zap.String("request-id", GetReqID(r.Context())),
+				zap.String("panic", fmt.Sprint(p)),
+				zap.Stack("stack"),
+			)
+
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+
+		h.ServeHTTP(w, r)
+	})
+}
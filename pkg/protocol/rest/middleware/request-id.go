@@ -9,6 +9,8 @@ import (
 	"os"
 	"strings"
 	"sync/atomic"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
 )
 
 type ctxKeyRequestID int
@@ -41,7 +43,9 @@ func AddRequestID(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		myid := atomic.AddUint64(&reqID, 1)
 		ctx := r.Context()
-		ctx = context.WithValue(ctx, RequestIDKey, fmt.Sprintf("%s-%06d", prefix, myid))
+		id := fmt.Sprintf("%s-%06d", prefix, myid)
+		ctx = context.WithValue(ctx, RequestIDKey, id)
+		ctx = logger.WithRequestID(ctx, id)
 
 		h.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// route is HTTP route template, e.g. GET /v1/todo/{id}
+type route struct {
+	method   string
+	template string
+	segments []string
+	literals int
+}
+
+// Routes resolves request paths to route templates, so logs and metrics
+// are grouped by route instead of by raw URI
+type Routes struct {
+	routes []route
+}
+
+// NewRoutes creates Routes from google.api.http options of all services in the files
+func NewRoutes(files ...protoreflect.FileDescriptor) *Routes {
+	rs := &Routes{}
+	for _, fd := range files {
+		services := fd.Services()
+		for i := 0; i < services.Len(); i++ {
+			methods := services.Get(i).Methods()
+			for j := 0; j < methods.Len(); j++ {
+				rule, ok := proto.GetExtension(methods.Get(j).Options(), annotations.E_Http).(*annotations.HttpRule)
+				if ok && rule != nil {
+					rs.addRule(rule)
+				}
+			}
+		}
+	}
+
+	return rs
+}
+
+// addRule adds route of HTTP rule and all its additional bindings
+func (rs *Routes) addRule(rule *annotations.HttpRule) {
+	switch p := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		rs.Add(http.MethodGet, p.Get)
+	case *annotations.HttpRule_Put:
+		rs.Add(http.MethodPut, p.Put)
+	case *annotations.HttpRule_Post:
+		rs.Add(http.MethodPost, p.Post)
+	case *annotations.HttpRule_Delete:
+		rs.Add(http.MethodDelete, p.Delete)
+	case *annotations.HttpRule_Patch:
+		rs.Add(http.MethodPatch, p.Patch)
+	case *annotations.HttpRule_Custom:
+		rs.Add(p.Custom.Kind, p.Custom.Path)
+	}
+
+	for _, b := range rule.AdditionalBindings {
+		rs.addRule(b)
+	}
+}
+
+// Add adds route template, variables are written in braces, e.g. /v1/todo/{id}
+func (rs *Routes) Add(method, template string) {
+	r := route{
+		method:   method,
+		template: template,
+		segments: strings.Split(strings.Trim(template, "/"), "/"),
+	}
+	for _, s := range r.segments {
+		if !isVariable(s) {
+			r.literals++
+		}
+	}
+
+	rs.routes = append(rs.routes, r)
+}
+
+// Match returns template of the route matching request, literal segments win over variables,
+// e.g. /v1/todo/all matches "/v1/todo/all" rather than "/v1/todo/{id}".
+// It returns empty string if no route matches.
+func (rs *Routes) Match(method, path string) string {
+	if rs == nil {
+		return ""
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var best *route
+	for i := range rs.routes {
+		r := &rs.routes[i]
+		if r.method != method || !r.match(segments) {
+			continue
+		}
+		if best == nil || r.literals > best.literals {
+			best = r
+		}
+	}
+
+	if best == nil {
+		return ""
+	}
+	return best.template
+}
+
+// match reports whether path segments match the route
+func (r *route) match(segments []string) bool {
+	if len(segments) != len(r.segments) {
+		return false
+	}
+
+	for i, s := range r.segments {
+		if !isVariable(s) && s != segments[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isVariable reports whether template segment is path variable
+func isVariable(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
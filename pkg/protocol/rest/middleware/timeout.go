@@ -0,0 +1,17 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// AddTimeout cancels context of requests after timeout, so gRPC calls forwarded by gateway get deadline
+func AddTimeout(timeout time.Duration, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,64 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// readinessCheckTimeout is maximum duration of single readiness check
+const readinessCheckTimeout = 2 * time.Second
+
+// probeResponse is JSON body of probe responses
+type probeResponse struct {
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// probes implements Kubernetes style liveness and readiness endpoints
+type probes struct {
+	// ready reports whether dependencies of the server (e.g. database) are reachable
+	ready func(context.Context) error
+	// shuttingDown is set to 1 once server started to shutdown
+	shuttingDown int32
+}
+
+// shutdown marks server not ready to accept new requests
+func (p *probes) shutdown() {
+	atomic.StoreInt32(&p.shuttingDown, 1)
+}
+
+// writeProbe writes probe response with status code
+func writeProbe(w http.ResponseWriter, code int, resp probeResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// healthz reports that process is up
+func (p *probes) healthz(w http.ResponseWriter, r *http.Request) {
+	writeProbe(w, http.StatusOK, probeResponse{Status: "ok"})
+}
+
+// readyz reports that server is able to serve requests
+func (p *probes) readyz(w http.ResponseWriter, r *http.Request) {
+	if atomic.LoadInt32(&p.shuttingDown) == 1 {
+		writeProbe(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Reason: "shutting down"})
+		return
+	}
+
+	if p.ready != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+		defer cancel()
+
+		if err := p.ready(ctx); err != nil {
+			writeProbe(w, http.StatusServiceUnavailable, probeResponse{Status: "unavailable", Reason: err.Error()})
+			return
+		}
+	}
+
+	writeProbe(w, http.StatusOK, probeResponse{Status: "ok"})
+}
@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+)
+
+// Router is parent router of the gateway, handlers registered on it are served next to the API
+// and they are logged and measured by their route like API calls.
+// Handlers are not authenticated by the gateway.
+type Router struct {
+	mux     *http.ServeMux
+	routes  *middleware.Routes
+	methods map[string]map[string]http.Handler
+}
+
+// newRouter creates Router registering handlers on mux and their routes to routes
+func newRouter(mux *http.ServeMux, routes *middleware.Routes) *Router {
+	return &Router{mux: mux, routes: routes, methods: map[string]map[string]http.Handler{}}
+}
+
+// Handle registers handler of requests with method to path, GET handlers also handle HEAD requests.
+// Requests to path with other methods get 405 Method Not Allowed.
+func (rt *Router) Handle(method, path string, h http.Handler) {
+	handlers, ok := rt.methods[path]
+	if !ok {
+		handlers = map[string]http.Handler{}
+		rt.methods[path] = handlers
+		rt.mux.Handle(path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if h, ok := handlers[r.Method]; ok {
+				h.ServeHTTP(w, r)
+				return
+			}
+			if h, ok := handlers[http.MethodGet]; ok && r.Method == http.MethodHead {
+				h.ServeHTTP(w, r)
+				return
+			}
+
+			allowed := make([]string, 0, len(handlers))
+			for m := range handlers {
+				allowed = append(allowed, m)
+			}
+			sort.Strings(allowed)
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}))
+	}
+
+	handlers[method] = h
+	rt.routes.Add(method, path)
+}
+
+// HandleFunc registers handler function of requests with method to path
+func (rt *Router) HandleFunc(method, path string, f func(http.ResponseWriter, *http.Request)) {
+	rt.Handle(method, path, http.HandlerFunc(f))
+}
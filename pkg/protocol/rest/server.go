@@ -2,51 +2,372 @@ package rest
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
-	"os"
-	"os/signal"
 	"time"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/auth"
+	"github.com/maslow123/go-grpc/pkg/errorreport"
 	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/protocol/graphql"
+	grpcmiddleware "github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+	"github.com/maslow123/go-grpc/pkg/protocol/listen"
 	"github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
-// RunServer runs HTTP/REST gateway
-func RunServer(ctx context.Context, grpcPort, httpPort string) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+// defaultMaxSendMsgSize is gRPC default maximum size of message received by gRPC server in bytes
+const defaultMaxSendMsgSize = 4 << 20
 
-	mux := runtime.NewServeMux()
+// Config is configuration for HTTP/REST gateway
+type Config struct {
+	// Logger is logger of the gateway, nothing is logged if nil
+	Logger *zap.Logger
+	// GRPCPort is TCP port of gRPC server requests are forwarded to
+	GRPCPort string
+	// GRPCEndpoint is address of gRPC server requests are forwarded to, e.g. "unix:///var/run/todo.sock",
+	// "localhost:"+GRPCPort is used if empty
+	GRPCEndpoint string
+	// HTTPPort is TCP port to listen on all interfaces
+	HTTPPort string
+	// HTTPAddress is TCP address "host:port" to listen instead of HTTPPort, e.g. to bind to loopback interface only
+	HTTPAddress string
+	// GRPCDialOptions are added to options of connection to gRPC server, e.g. dialer of in-process listener
+	GRPCDialOptions []grpc.DialOption
+	// Listeners are served instead of listening on HTTPPort if they are not empty, e.g. to share port
+	// with gRPC server or to listen on several addresses.
+	// TLS must be terminated by Listeners, TLSConfig is then used only to dial gRPC server.
+	Listeners []net.Listener
+	// TLSConfig is TLS configuration, gateway is served over TLS if it is not nil.
+	// gRPC server is dialed over TLS then, its certificate is verified against RootCAs unless it is dialed over loopback.
+	TLSConfig *tls.Config
+	// H2C turns on HTTP/2 over plain text connections (h2c), e.g. for proxies that do not use TLS.
+	// It is always on if Listeners are set.
+	H2C bool
+	// MaxRecvMsgSize is maximum size of gRPC response message forwarded to the client in bytes,
+	// gRPC default (4MB) is used if 0
+	MaxRecvMsgSize int
+	// MaxSendMsgSize is maximum size of gRPC request message sent to gRPC server in bytes, gRPC default is used if 0
+	MaxSendMsgSize int
+	// HMACSecret is shared secret requests must be signed with, requests are not authenticated if empty
+	HMACSecret string
+	// ForwardHeaders are request headers forwarded to gRPC server as metadata, e.g. X-Tenant-ID or Authorization
+	ForwardHeaders []string
+	// ResponseMetadata are gRPC response metadata keys sent as response headers without Grpc-Metadata- prefix
+	ResponseMetadata []string
+	// Marshal configures JSON shape of responses
+	Marshal MarshalOptions
+	// Docs turns on serving of OpenAPI spec at /swagger.json and Swagger UI at /docs
+	Docs bool
+	// GraphQL turns on GraphQL endpoint at /graphql, it calls Todo Service like the gateway
+	GraphQL bool
+	// HSTSMaxAge is max-age of Strict-Transport-Security header set on responses sent over TLS,
+	// header is not set if 0
+	HSTSMaxAge time.Duration
+	// HSTSIncludeSubdomains makes Strict-Transport-Security header apply to subdomains too
+	HSTSIncludeSubdomains bool
+	// Compression configures gzip/deflate compression of responses, they are not compressed if MinSize is 0
+	Compression middleware.CompressionOptions
+	// Routes registers custom handlers (e.g. webhook receivers, static assets) served next to the API
+	Routes func(r *Router)
+	// ReadinessCheck reports whether dependencies of the server (e.g. database) are reachable
+	ReadinessCheck func(context.Context) error
+	// ErrorReporter receives panics, they are not reported if nil
+	ErrorReporter errorreport.Reporter
+	// ReadHeaderTimeout is how long reading of request headers may take, it is not limited if 0
+	ReadHeaderTimeout time.Duration
+	// ReadTimeout is how long reading of whole request may take, it is not limited if 0
+	ReadTimeout time.Duration
+	// WriteTimeout is how long handling of request and writing of response may take, it is not limited if 0
+	WriteTimeout time.Duration
+	// IdleTimeout is how long keep-alive connections wait for next request, ReadTimeout is used if 0
+	IdleTimeout time.Duration
+	// RequestTimeout is how long gRPC calls forwarded for single request may take, it is not limited if 0
+	RequestTimeout time.Duration
+	// ShutdownTimeout is how long in-flight requests are waited for on shutdown before
+	// connections are closed, they are waited for without limit if 0
+	ShutdownTimeout time.Duration
+	// AccessLogFormat is format of access log: middleware.AccessLogJSON or middleware.AccessLogCombined
+	AccessLogFormat string
+	// AccessLogOutput is where middleware.AccessLogCombined lines are written to, they are logged by Logger if nil
+	AccessLogOutput io.Writer
+}
+
+// RunServer runs HTTP/REST gateway until ctx is done, then it waits for in-flight requests to finish
+func RunServer(ctx context.Context, cfg Config) error {
+	log := logger.OrNop(cfg.Logger)
+
+	// connection to gRPC server is closed only after in-flight requests are finished,
+	// so it does not depend on ctx
+	connCtx, closeConn := context.WithCancel(context.Background())
+	defer closeConn()
+
+	outgoing := outgoingHeaderMatcher(cfg.ResponseMetadata)
+	mux := runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, cfg.Marshal.marshaler()),
+		// configured headers are forwarded between HTTP and gRPC metadata
+		runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher(cfg.ForwardHeaders)),
+		runtime.WithOutgoingHeaderMatcher(outgoing),
+		// errors are sent in JSON envelope with request id
+		runtime.WithProtoErrorHandler(newErrorHandler(outgoing)),
+		// forward request id to gRPC server, so both sides log it
+		runtime.WithMetadata(func(ctx context.Context, r *http.Request) metadata.MD {
+			return metadata.Pairs(grpcmiddleware.RequestIDKey, middleware.GetReqID(ctx))
+		}),
+	)
+	endpoint := cfg.GRPCEndpoint
+	if len(endpoint) == 0 {
+		endpoint = "localhost:" + cfg.GRPCPort
+	}
 	opts := []grpc.DialOption{grpc.WithInsecure()}
-	if err := v1.RegisterTodoServiceHandlerFromEndpoint(ctx, mux, "localhost:"+grpcPort, opts); err != nil {
-		logger.Log.Fatal("Failed to start HTTP gateway", zap.String("reason", err.Error()))
+	if cfg.TLSConfig != nil {
+		// gRPC server shares the gateway certificate, it is not verified against "localhost" if gRPC server
+		// is dialed over loopback, certificate of other hosts is verified against RootCAs of TLSConfig
+		opts = []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			RootCAs:            cfg.TLSConfig.RootCAs,
+			InsecureSkipVerify: isLocal(endpoint),
+		}))}
+	}
+	// message size limits match limits of gRPC server
+	var callOpts []grpc.CallOption
+	if cfg.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
 	}
+	// continue trace of the request in gRPC server
+	// forwarded calls of idempotent methods are retried when gRPC server is unavailable
+	opts = append(opts, grpc.WithDefaultServiceConfig(v1.ServiceConfig()))
+	opts = append(opts, grpc.WithChainUnaryInterceptor(otelgrpc.UnaryClientInterceptor()))
+	if len(cfg.HMACSecret) > 0 {
+		// gateway is verified caller, it signs calls it forwards to gRPC server
+		opts = append(opts, grpc.WithChainUnaryInterceptor(auth.UnarySigningClientInterceptor(cfg.HMACSecret)))
+	}
+	opts = append(opts, cfg.GRPCDialOptions...)
+	if err := v1.RegisterTodoServiceHandlerFromEndpoint(connCtx, mux, endpoint, opts); err != nil {
+		return fmt.Errorf("Failed to register Todo Service handler: %v", err)
+	}
+	if err := v1.RegisterAdminServiceHandlerFromEndpoint(connCtx, mux, endpoint, opts); err != nil {
+		return fmt.Errorf("Failed to register Admin Service handler: %v", err)
+	}
+
+	// signed requests are buffered to be verified, they may be as long as request message gRPC server accepts
+	maxBodySize := int64(cfg.MaxSendMsgSize)
+	if maxBodySize <= 0 {
+		maxBodySize = defaultMaxSendMsgSize
+	}
+	var handler http.Handler = mux
+	if len(cfg.HMACSecret) > 0 {
+		handler = middleware.AddSignatureAuth(cfg.HMACSecret, maxBodySize, handler)
+	}
+
+	var gql http.Handler
+	if cfg.GraphQL {
+		conn, err := grpc.DialContext(connCtx, endpoint, opts...)
+		if err != nil {
+			return fmt.Errorf("Failed to dial gRPC server for GraphQL: %v", err)
+		}
+		defer conn.Close()
 
+		gql = forwardRequestID(graphql.NewHandler(v1.NewTodoServiceClient(conn)))
+		if len(cfg.HMACSecret) > 0 {
+			gql = middleware.AddSignatureAuth(cfg.HMACSecret, maxBodySize, gql)
+		}
+	}
+
+	// gateway is mounted under parent router, logs and metrics are grouped by route templates
+	// of the API and handlers of the router
+	root := http.NewServeMux()
+	root.Handle("/", handler)
+	routes := middleware.NewRoutes(v1.File_todo_service_proto)
+	router := newRouter(root, routes)
+
+	// probes and docs are served next to the gateway and do not require authentication
+	p := &probes{ready: cfg.ReadinessCheck}
+	router.HandleFunc(http.MethodGet, "/healthz", p.healthz)
+	router.HandleFunc(http.MethodGet, "/readyz", p.readyz)
+	if cfg.Docs {
+		router.HandleFunc(http.MethodGet, "/swagger.json", swaggerJSON)
+		router.HandleFunc(http.MethodGet, "/docs", docs)
+	}
+	if gql != nil {
+		router.Handle(http.MethodPost, "/graphql", gql)
+	}
+	if cfg.Routes != nil {
+		cfg.Routes(router)
+	}
+
+	address := cfg.HTTPAddress
+	if len(address) == 0 {
+		address = ":" + cfg.HTTPPort
+	}
+
+	var served http.Handler = middleware.AddRequestID(
+		middleware.AddLogger(log, middleware.AccessLogOptions{
+			Format: cfg.AccessLogFormat,
+			Routes: routes,
+			Output: cfg.AccessLogOutput,
+		}, middleware.AddMetrics(routes,
+			compress(cfg.Compression, middleware.AddRecovery(log, cfg.ErrorReporter, withTimeout(cfg.RequestTimeout, root))),
+		)),
+	)
+	if cfg.HSTSMaxAge > 0 {
+		served = middleware.AddHSTS(cfg.HSTSMaxAge, cfg.HSTSIncludeSubdomains, served)
+	}
+
+	requests := &inFlight{}
 	srv := &http.Server{
-		Addr: ":" + httpPort,
-		Handler: middleware.AddRequestID(
-			middleware.AddLogger(logger.Log, mux),
-		),
+		Addr:              address,
+		Handler:           requests.handler(served),
+		TLSConfig:         cfg.TLSConfig,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		// TLS of connections split from shared port is terminated before they are accepted
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			if listen.IsTLS(c) {
+				return middleware.WithTLS(ctx)
+			}
+			return ctx
+		},
 	}
 
 	// graceful shutdown
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	shutdownErr := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
 
 	go func() {
-		for range c {
-			// sig is a ^c, handle it
+		select {
+		case <-ctx.Done():
+		case <-stop:
+			return
 		}
-		_, cancel := context.WithTimeout(ctx, 5*time.Second)
-		defer cancel()
 
-		_ = srv.Shutdown(ctx)
+		log.Warn("Shutting down HTTP/REST gateway...")
+		p.shutdown()
+
+		shutdownErr <- gracefulShutdown(log, srv, requests, cfg.ShutdownTimeout)
 	}()
 
-	logger.Log.Info("Starting HTTP/REST gateway...")
-	return srv.ListenAndServe()
+	// connections of HTTP/2 clients sent to Listeners are already decrypted
+	if len(cfg.Listeners) > 0 || (cfg.H2C && cfg.TLSConfig == nil) {
+		if err := withH2C(srv); err != nil {
+			return fmt.Errorf("Failed to configure h2c: %v", err)
+		}
+	}
+
+	log.Info("Starting HTTP/REST gateway...")
+	var err error
+	if len(cfg.Listeners) > 0 {
+		errs := make(chan error, len(cfg.Listeners))
+		for _, l := range cfg.Listeners {
+			go func(l net.Listener) {
+				errs <- srv.Serve(l)
+			}(l)
+		}
+		for range cfg.Listeners {
+			if err = <-errs; err != http.ErrServerClosed {
+				srv.Close()
+				return err
+			}
+		}
+	} else {
+		if cfg.TLSConfig != nil {
+			// certificate is provided by TLSConfig.GetCertificate
+			err = srv.ListenAndServeTLS("", "")
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
+			return err
+		}
+	}
+
+	// listeners are closed, wait for in-flight requests
+	if err := <-shutdownErr; err != nil {
+		return fmt.Errorf("Failed to shutdown HTTP/REST gateway: %v", err)
+	}
+	return nil
+}
+
+// isLocal reports whether gRPC server dialed at endpoint runs on the same host, i.e. it is dialed over
+// Unix domain socket or loopback
+func isLocal(endpoint string) bool {
+	if listen.IsUnix(endpoint) {
+		return true
+	}
+	host, _, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// gracefulShutdown shuts server down waiting for in-flight requests, connections are closed after timeout if it is not 0
+func gracefulShutdown(log *zap.Logger, srv *http.Server, requests *inFlight, timeout time.Duration) error {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	err := srv.Shutdown(ctx)
+	if err != context.DeadlineExceeded {
+		return err
+	}
+
+	log.Warn("Shutdown timeout exceeded, closing HTTP/REST gateway connections",
+		zap.Int64("cut-off-requests", requests.count()),
+	)
+	return srv.Close()
+}
+
+// withH2C makes server handle HTTP/2 requests over plain text connections
+func withH2C(srv *http.Server) error {
+	h2s := &http2.Server{}
+	// h2c connections are hijacked from srv, so they are only sent GOAWAY on shutdown
+	if err := http2.ConfigureServer(srv, h2s); err != nil {
+		return err
+	}
+
+	srv.Handler = h2c.NewHandler(srv.Handler, h2s)
+	return nil
+}
+
+// compress adds compression of responses to h if it is on
+func compress(o middleware.CompressionOptions, h http.Handler) http.Handler {
+	if o.MinSize <= 0 {
+		return h
+	}
+	return middleware.AddCompression(o, h)
+}
+
+// withTimeout limits requests to timeout, they are not limited if it is 0
+func withTimeout(timeout time.Duration, h http.Handler) http.Handler {
+	if timeout <= 0 {
+		return h
+	}
+	return middleware.AddTimeout(timeout, h)
 }
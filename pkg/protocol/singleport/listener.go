@@ -0,0 +1,120 @@
+package singleport
+
+import (
+	"crypto/tls"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/maslow123/go-grpc/pkg/protocol/listen"
+	"github.com/soheilhy/cmux"
+)
+
+// Listeners splits one port between gRPC server and HTTP gateway.
+// Connections are told apart by their first bytes, gRPC calls are HTTP/2 requests
+// with "application/grpc" content type, everything else goes to HTTP gateway.
+type Listeners struct {
+	// GRPC accepts connections of gRPC clients
+	GRPC net.Listener
+	// HTTP accepts connections of HTTP clients
+	HTTP net.Listener
+
+	root   net.Listener
+	m      cmux.CMux
+	closed int32
+}
+
+// New splits connections accepted by root, TLS is terminated before connections are split if tlsConfig is not nil
+func New(root net.Listener, tlsConfig *tls.Config) *Listeners {
+	if tlsConfig != nil {
+		root = listen.TLS(root, tlsConfig)
+	}
+
+	m := cmux.New(root)
+	// gRPC clients wait for server SETTINGS frame before they send headers
+	grpcL := m.MatchWithWriters(cmux.HTTP2MatchHeaderFieldSendSettings("content-type", "application/grpc"))
+	httpL := m.Match(cmux.Any())
+
+	// servers are told connections are encrypted, they cannot see TLS under connection multiplexer
+	secure := tlsConfig != nil
+	return &Listeners{
+		GRPC: newListener(grpcL, secure),
+		HTTP: newListener(httpL, secure),
+		root: root,
+		m:    m,
+	}
+}
+
+// Serve accepts connections and passes them to GRPC and HTTP listeners until Close is called
+func (l *Listeners) Serve() error {
+	err := l.m.Serve()
+	if atomic.LoadInt32(&l.closed) == 1 {
+		return nil
+	}
+	return err
+}
+
+// Close stops accepting connections, it must be called after servers of both listeners are stopped
+func (l *Listeners) Close() error {
+	atomic.StoreInt32(&l.closed, 1)
+	return l.root.Close()
+}
+
+// listener is listener of one protocol, closing it does not close shared port,
+// so one server can be stopped while the other one still serves
+type listener struct {
+	net.Listener
+	secure bool
+	once   sync.Once
+	done   chan struct{}
+}
+
+// newListener wraps cmux listener, accepted connections are marked as decrypted if secure is true
+func newListener(l net.Listener, secure bool) *listener {
+	return &listener{Listener: l, secure: secure, done: make(chan struct{})}
+}
+
+// acceptResult is result of Accept of cmux listener
+type acceptResult struct {
+	c   net.Conn
+	err error
+}
+
+// Accept waits for next connection, it returns net.ErrClosed once listener is closed
+func (l *listener) Accept() (net.Conn, error) {
+	select {
+	case <-l.done:
+		return nil, net.ErrClosed
+	default:
+	}
+
+	ch := make(chan acceptResult, 1)
+	go func() {
+		c, err := l.Listener.Accept()
+		ch <- acceptResult{c: c, err: err}
+	}()
+
+	select {
+	case r := <-ch:
+		if r.err == nil && l.secure {
+			return listen.Decrypted(r.c), nil
+		}
+		return r.c, r.err
+	case <-l.done:
+		// connection accepted after close is not served by anybody
+		go func() {
+			if r := <-ch; r.c != nil {
+				_ = r.c.Close()
+			}
+		}()
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops Accept, the shared port is kept open
+func (l *listener) Close() error {
+	l.once.Do(func() {
+		close(l.done)
+	})
+	return nil
+}
@@ -0,0 +1,50 @@
+package reminder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/notify"
+	"go.uber.org/zap"
+)
+
+// Digester sends digests of upcoming reminders, it is run by jobs.Runner on cron schedule, e.g. every morning
+type Digester struct {
+	db       *sql.DB
+	window   time.Duration
+	channels []notify.DigestChannel
+	log      *zap.Logger
+}
+
+// NewDigester returns digester of reminders of todos stored in db due within window after it runs
+func NewDigester(db *sql.DB, window time.Duration, channels []notify.DigestChannel, log *zap.Logger) *Digester {
+	return &Digester{db: db, window: window, channels: channels, log: logger.OrNop(log)}
+}
+
+// Send sends digest of reminders due within window through all channels
+func (d *Digester) Send(ctx context.Context) error {
+	now := time.Now()
+	digest := notify.Digest{From: now, To: now.Add(d.window)}
+
+	query := `SELECT id, title, description, reminder FROM todo WHERE reminder > ? AND reminder <= ? ORDER BY reminder`
+	var err error
+	digest.Reminders, err = selectReminders(ctx, d.db, query, digest.From.UTC(), digest.To.UTC())
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, c := range d.channels {
+		if err := c.SendDigest(ctx, digest); err != nil {
+			failed++
+			d.log.Error("Failed to send reminder digest", zap.String("channel", c.Name()), zap.String("reason", err.Error()))
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to send reminder digest through %d of %d channels", failed, len(d.channels))
+	}
+	return nil
+}
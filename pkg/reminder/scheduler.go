@@ -0,0 +1,148 @@
+package reminder
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/notify"
+	"go.uber.org/zap"
+)
+
+// Options configures Scheduler
+type Options struct {
+	// Interval is how far ahead due reminders are looked for, FireDue is meant to run once per interval
+	Interval time.Duration
+	// MaxDelay is how late reminder may fire, e.g. after server was down, older reminders are skipped
+	MaxDelay time.Duration
+	// SendTimeout is how long sending of reminder through single channel may take
+	SendTimeout time.Duration
+}
+
+// DefaultOptions are options used for zero fields of Options
+var DefaultOptions = Options{
+	Interval:    time.Minute,
+	MaxDelay:    time.Hour,
+	SendTimeout: time.Minute,
+}
+
+// Scheduler fires reminders of todos through notification channels when they are due.
+// Fired reminders are marked in todo table, so they fire once even if server restarts
+// or several servers run Scheduler.
+type Scheduler struct {
+	db       *sql.DB
+	o        Options
+	channels []notify.Channel
+	log      *zap.Logger
+}
+
+// NewScheduler returns scheduler of reminders of todos stored in db
+func NewScheduler(db *sql.DB, o Options, channels []notify.Channel, log *zap.Logger) *Scheduler {
+	if o.Interval <= 0 {
+		o.Interval = DefaultOptions.Interval
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = DefaultOptions.MaxDelay
+	}
+	if o.SendTimeout <= 0 {
+		o.SendTimeout = DefaultOptions.SendTimeout
+	}
+
+	return &Scheduler{db: db, o: o, channels: channels, log: logger.OrNop(log)}
+}
+
+// FireDue fires reminders due within interval, each of them when it is due, so it returns after about interval.
+// It is run by jobs.Runner every interval.
+func (s *Scheduler) FireDue(ctx context.Context) error {
+	due, err := s.due(ctx, time.Now().Add(s.o.Interval))
+	if err != nil {
+		return err
+	}
+
+	for _, r := range due {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(time.Until(r.At)):
+		}
+
+		// reminder is claimed before it is sent, so it is not sent twice
+		claimed, err := s.claim(ctx, r)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			s.fire(ctx, r)
+		}
+	}
+	return nil
+}
+
+// due returns reminders that are not fired yet and are due before end, ordered by time they are due
+func (s *Scheduler) due(ctx context.Context, end time.Time) ([]notify.Reminder, error) {
+	query := `SELECT id, title, description, reminder FROM todo
+		WHERE reminder > ? AND reminder <= ? AND reminder_fired_at IS NULL ORDER BY reminder`
+	return selectReminders(ctx, s.db, query, time.Now().Add(-s.o.MaxDelay).UTC(), end.UTC())
+}
+
+// selectReminders returns reminders of todos selected by query with id, title, description and reminder columns
+func selectReminders(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]notify.Reminder, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select from todo: %v", err)
+	}
+	defer rows.Close()
+
+	var list []notify.Reminder
+	for rows.Next() {
+		var (
+			r                  notify.Reminder
+			title, description sql.NullString
+		)
+		if err := rows.Scan(&r.TodoID, &title, &description, &r.At); err != nil {
+			return nil, fmt.Errorf("failed to retrieve field values from todo: %v", err)
+		}
+		r.Title = title.String
+		r.Description = description.String
+		list = append(list, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to retrieve data from todo: %v", err)
+	}
+	return list, nil
+}
+
+// claim marks reminder fired, it reports false if reminder was fired by other server, changed or deleted
+func (s *Scheduler) claim(ctx context.Context, r notify.Reminder) (bool, error) {
+	query := `UPDATE todo SET reminder_fired_at = ? WHERE id = ? AND reminder = ? AND reminder_fired_at IS NULL`
+	res, err := s.db.ExecContext(ctx, query, time.Now().UTC(), r.TodoID, r.At.UTC())
+	if err != nil {
+		return false, fmt.Errorf("failed to mark reminder fired: %v", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve rows affected value: %v", err)
+	}
+	return rows == 1, nil
+}
+
+// fire sends reminder through all channels, failures are logged and reminder is not fired again
+func (s *Scheduler) fire(ctx context.Context, r notify.Reminder) {
+	for _, c := range s.channels {
+		cctx, cancel := context.WithTimeout(ctx, s.o.SendTimeout)
+		err := c.Send(cctx, r)
+		cancel()
+
+		if err != nil {
+			s.log.Error("Failed to send reminder",
+				zap.String("channel", c.Name()),
+				zap.Int64("todo", r.TodoID),
+				zap.String("reason", err.Error()),
+			)
+			continue
+		}
+		s.log.Info("Reminder sent", zap.String("channel", c.Name()), zap.Int64("todo", r.TodoID))
+	}
+}
@@ -0,0 +1,366 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/events"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/notify"
+	grpcmiddleware "github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+	restmiddleware "github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+	"github.com/maslow123/go-grpc/pkg/reminder"
+	"github.com/maslow123/go-grpc/pkg/webhook"
+)
+
+// Config is configuration for Server
+type Config struct {
+	// gRPC server start parameters section
+	// gRPC is TCP port to listen by gRPC server
+	GRPCPort string
+	// GRPCAddr is comma separated list of TCP addresses "host:port" to listen by gRPC server instead of GRPCPort,
+	// e.g. 0.0.0.0:9090,[::]:9090
+	GRPCAddr string
+	// GRPCPlaintextAddr is comma separated list of TCP addresses gRPC server listens on without TLS in addition,
+	// e.g. internal port next to external TLS port
+	GRPCPlaintextAddr string
+	// GRPCListen is Unix domain socket to listen by gRPC server instead of GRPCPort, e.g. unix:///var/run/todo.sock
+	GRPCListen string
+	// XDS makes gRPC server managed by xDS control plane of service mesh
+	XDS bool
+	// GRPCReflection turns on gRPC reflection service
+	GRPCReflection bool
+	// GRPCMaxConnections is maximum number of gRPC connections served at the same time, not limited if 0
+	GRPCMaxConnections int
+	// GRPCMaxConcurrentStreams is maximum number of concurrent calls per gRPC connection, gRPC default if 0
+	GRPCMaxConcurrentStreams uint
+	// GRPCMaxClientCalls is maximum number of concurrent calls per client, not limited if 0
+	GRPCMaxClientCalls int
+	// GRPCMaxRecvMsgSize is maximum size of message received by gRPC server in bytes
+	GRPCMaxRecvMsgSize int
+	// GRPCMaxSendMsgSize is maximum size of message sent by gRPC server in bytes
+	GRPCMaxSendMsgSize int
+	// GRPCDefaultTimeout is timeout of gRPC calls without deadline, 0 leaves them without limit
+	GRPCDefaultTimeout time.Duration
+	// GRPCCompressionThreshold is size in bytes gRPC responses are gzip compressed from, 0 turns it off
+	GRPCCompressionThreshold int
+	// GRPCKeepaliveMaxConnectionIdle is how long idle connection is kept open, forever if 0
+	GRPCKeepaliveMaxConnectionIdle time.Duration
+	// GRPCKeepaliveMaxConnectionAge is how long connection is kept open before it is cycled, forever if 0
+	GRPCKeepaliveMaxConnectionAge time.Duration
+	// GRPCKeepaliveMaxConnectionAgeGrace is how long calls of cycled connection are waited for, forever if 0
+	GRPCKeepaliveMaxConnectionAgeGrace time.Duration
+	// GRPCKeepaliveTime is how long connection is idle before server pings client
+	GRPCKeepaliveTime time.Duration
+	// GRPCKeepaliveTimeout is how long ping ack is waited for before connection is closed
+	GRPCKeepaliveTimeout time.Duration
+	// GRPCKeepaliveMinTime is minimum interval clients are allowed to ping at
+	GRPCKeepaliveMinTime time.Duration
+	// GRPCKeepalivePermitWithoutStream allows clients to ping when there are no active calls
+	GRPCKeepalivePermitWithoutStream bool
+
+	// HTTP/REST gateway start parameters section
+	// HTTPPort is TCP port to listen by HTTP/REST gateway
+	HTTPPort string
+	// HTTPAddr is comma separated list of TCP addresses "host:port" to listen by HTTP/REST gateway instead of HTTPPort
+	HTTPAddr string
+	// HTTPPlaintextAddr is comma separated list of TCP addresses HTTP/REST gateway listens on without TLS in addition
+	HTTPPlaintextAddr string
+	// HTTPListen is Unix domain socket to listen by HTTP/REST gateway instead of HTTPPort
+	HTTPListen string
+	// HTTPH2C turns on HTTP/2 without TLS (h2c) for HTTP/REST gateway
+	HTTPH2C bool
+	// HTTPDocs turns on serving of OpenAPI spec and Swagger UI by HTTP/REST gateway
+	HTTPDocs bool
+	// HTTPGraphQL turns on GraphQL endpoint of HTTP/REST gateway
+	HTTPGraphQL bool
+	// HTTPRedirectAddr is TCP address of plain text server redirecting to HTTPS gateway, e.g. :80,
+	// it is not run if empty
+	HTTPRedirectAddr string
+	// HTTPHSTSMaxAge is max-age of Strict-Transport-Security header of HTTPS responses, it is not set if 0
+	HTTPHSTSMaxAge time.Duration
+	// HTTPHSTSIncludeSubdomains makes Strict-Transport-Security header apply to subdomains
+	HTTPHSTSIncludeSubdomains bool
+	// HTTPReadHeaderTimeout is how long reading of request headers may take
+	HTTPReadHeaderTimeout time.Duration
+	// HTTPReadTimeout is how long reading of whole request may take
+	HTTPReadTimeout time.Duration
+	// HTTPWriteTimeout is how long handling of request and writing of response may take
+	HTTPWriteTimeout time.Duration
+	// HTTPIdleTimeout is how long keep-alive connections wait for next request
+	HTTPIdleTimeout time.Duration
+	// HTTPRequestTimeout is how long gRPC calls forwarded by HTTP/REST gateway for single request may take
+	HTTPRequestTimeout time.Duration
+	// HTTPForwardHeaders is comma separated list of request headers forwarded to gRPC server as metadata
+	HTTPForwardHeaders string
+	// HTTPResponseMetadata is comma separated list of gRPC response metadata keys sent as response headers
+	HTTPResponseMetadata string
+	// HTTPJSONEmitUnpopulated turns on sending fields with zero values in JSON responses
+	HTTPJSONEmitUnpopulated bool
+	// HTTPJSONCamelCase makes JSON field names lowerCamelCase instead of proto field names
+	HTTPJSONCamelCase bool
+	// HTTPJSONEnumsAsInts makes enum values numbers instead of names in JSON responses
+	HTTPJSONEnumsAsInts bool
+	// HTTPJSONIndent turns on indentation of JSON responses
+	HTTPJSONIndent bool
+	// HTTPCompressionMinSize is size in bytes HTTP responses are compressed from, they are not compressed if 0
+	HTTPCompressionMinSize int
+	// HTTPCompressionTypes is comma separated list of media types of compressed HTTP responses
+	HTTPCompressionTypes string
+	// SinglePort makes HTTP/REST gateway share gRPC port, HTTPPort is not used then
+	SinglePort bool
+
+	// Load balancer parameters section
+	// ProxyProtocol turns on reading of PROXY protocol headers sent by load balancers on both servers
+	ProxyProtocol bool
+	// ProxyProtocolTrusted is comma separated list of CIDRs of load balancers PROXY headers are read from,
+	// it is required with ProxyProtocol
+	ProxyProtocolTrusted string
+
+	// Shutdown parameters section
+	// ShutdownTimeout is how long each server waits for in-flight requests on shutdown before they are cut off
+	ShutdownTimeout time.Duration
+
+	// Metrics parameters section
+	// MetricsPort is TCP port to publish Prometheus metrics, metrics are not published if empty
+	MetricsPort string
+	// MetricsLogRuntimeInterval is how often Go runtime stats are logged, they are not logged if 0
+	MetricsLogRuntimeInterval time.Duration
+
+	// Admin parameters section
+	// AdminPort is TCP port to publish pprof endpoints, they are not published if empty
+	AdminPort string
+	// AdminLocalhostOnly makes admin servers listen on loopback interface only
+	AdminLocalhostOnly bool
+	// AdminChannelzPort is TCP port to publish gRPC channelz service, it is not published if empty
+	AdminChannelzPort string
+
+	// DB DataStore parameters section
+	// DatastoreDBHost is host of database
+	DatastoreDBHost string
+	// DatastoreDBUser string
+	DatastoreDBUser string
+	// DatastoreDBPassword string
+	DatastoreDBPassword string
+	// DatastoreDBSchema string
+	DatastoreDBSchema string
+	// DatastoreHealthCheckInterval is how often database is checked to be reachable
+	DatastoreHealthCheckInterval time.Duration
+	// DatastoreErrorRateThreshold is database error rate (0..1) server stops being ready at, it is not tracked if 0
+	DatastoreErrorRateThreshold float64
+	// DatastoreErrorRateWindow is rolling window database error rate is computed over
+	DatastoreErrorRateWindow time.Duration
+	// DatastoreErrorRateMinRequests is number of calls in the window required to exceed the threshold
+	DatastoreErrorRateMinRequests int
+	// DatastoreCircuitBreaker turns on rejecting calls using database while error rate exceeds threshold
+	DatastoreCircuitBreaker bool
+	// ReadOnly turns on read-only mode for maintenance, calls changing todos are rejected
+	ReadOnly bool
+
+	// Alert parameters section
+	// AlertWebhookURL is URL dependency error rate changes are posted to, they are not posted if empty
+	AlertWebhookURL string
+	// AlertWebhookSecret is secret alert webhook requests are signed with, they are not signed if empty
+	AlertWebhookSecret string
+
+	// Events parameters section
+	// EventsKafkaBrokers is comma separated list of host:port addresses of Kafka brokers events of todo changes
+	// are published to, they are not published if empty
+	EventsKafkaBrokers string
+	// EventsKafkaTopic is Kafka topic events are published to
+	EventsKafkaTopic string
+	// EventsNATSURL is comma separated list of URLs of NATS servers events are published to with JetStream
+	// instead of Kafka, they are not published if empty
+	EventsNATSURL string
+	// EventsNATSStream is JetStream stream events are stored in, it is created if it does not exist
+	EventsNATSStream string
+	// EventsNATSSubject is NATS subject events are published to
+	EventsNATSSubject string
+	// EventsEncoding is encoding of published events: json or protobuf
+	EventsEncoding string
+	// EventsRelayInterval is how often events stored in outbox table with todo changes are polled for and published
+	EventsRelayInterval time.Duration
+	// EventsRelayMaxAttempts is number of attempts after which event stays in outbox table as failed
+	EventsRelayMaxAttempts int
+
+	// Webhook parameters section
+	// WebhookDelivery turns on posting of events to webhooks created by Admin Service, it is off by default
+	WebhookDelivery bool
+	// WebhookPollInterval is how often pending webhook deliveries are looked for
+	WebhookPollInterval time.Duration
+	// WebhookTimeout is how long single webhook request may take
+	WebhookTimeout time.Duration
+	// WebhookMaxAttempts is number of attempts after which webhook delivery fails
+	WebhookMaxAttempts int
+
+	// Reminder parameters section, reminders are fired if any of notification channels is configured
+	// ReminderInterval is how far ahead due reminders are looked for
+	ReminderInterval time.Duration
+	// ReminderMaxDelay is how late reminder may fire, e.g. after server was down
+	ReminderMaxDelay time.Duration
+	// ReminderEmailHost is host name of SMTP server reminders are emailed through, they are not emailed if empty
+	ReminderEmailHost string
+	// ReminderEmailPort is port of SMTP server
+	ReminderEmailPort int
+	// ReminderEmailUsername and ReminderEmailPassword authenticate to SMTP server
+	ReminderEmailUsername string
+	ReminderEmailPassword string
+	// ReminderEmailFrom is sender address of reminder emails
+	ReminderEmailFrom string
+	// ReminderEmailTo is comma separated list of addresses reminders are emailed to
+	ReminderEmailTo string
+	// ReminderEmailSubject and ReminderEmailBody are templates of reminder emails, defaults are used if empty
+	ReminderEmailSubject string
+	ReminderEmailBody    string
+	// ReminderFCMCredentialsFile is Google service account key reminders are pushed to FCM devices with
+	ReminderFCMCredentialsFile string
+	// ReminderFCMProjectID is Firebase project ID, it is read from credentials if empty
+	ReminderFCMProjectID string
+	// ReminderWebPushPublicKey and ReminderWebPushPrivateKey are VAPID keys reminders are pushed to browsers with
+	ReminderWebPushPublicKey  string
+	ReminderWebPushPrivateKey string
+	// ReminderWebPushSubscriber is contact email sent to Web Push services
+	ReminderWebPushSubscriber string
+	// ReminderChatWebhookURL is URL of Slack or Teams incoming webhook reminders are posted to, they are not posted if empty
+	ReminderChatWebhookURL string
+	// ReminderChatFormat is format of chat messages: slack or teams
+	ReminderChatFormat string
+	// ReminderChatTemplate and ReminderChatDigestTemplate are templates of chat messages, defaults of format are used if empty
+	ReminderChatTemplate       string
+	ReminderChatDigestTemplate string
+	// ReminderChatRateLimit is maximum number of chat messages posted per second
+	ReminderChatRateLimit float64
+	// ReminderDigestCron is cron spec digests of upcoming reminders are posted to chat on, e.g. "0 8 * * *", they are not posted if empty
+	ReminderDigestCron string
+	// ReminderDigestWindow is how far ahead digest lists reminders
+	ReminderDigestWindow time.Duration
+
+	// TLS parameters section
+	// TLSCertFile is path to PEM encoded certificate, TLS is disabled if empty
+	TLSCertFile string
+	// TLSKeyFile is path to PEM encoded private key of the certificate
+	TLSKeyFile string
+	// TLSReloadInterval is how often certificate files are checked for changes, they are not checked if 0
+	TLSReloadInterval time.Duration
+
+	// Auth parameters section
+	// AuthHMACSecret is shared secret callers sign requests with, requests are not authenticated if empty
+	AuthHMACSecret string
+	// AuthAPIKey requires Todo Service calls to send API key created by Admin Service,
+	// Admin Service calls always require API key with "admin" scope
+	AuthAPIKey bool
+
+	// Error reporting parameters section
+	// SentryDSN is Sentry project DSN panics and server side errors are reported to, they are not reported if empty
+	SentryDSN string
+	// SentryEnvironment is environment name events are tagged with, e.g. production
+	SentryEnvironment string
+
+	// Tracing parameters section
+	// TracingOTLPEndpoint is host:port of OTLP collector spans are exported to, tracing is disabled if empty
+	TracingOTLPEndpoint string
+	// TracingServiceName is service name spans are tagged with
+	TracingServiceName string
+
+	// Log parameters section
+	// LogLevel is global log level: Debug(-1), Info(0), Warn(1), Error(2), DPanic(3), Panic(4), Fatal(5)
+	LogLevel      int
+	LogTimeFormat string
+	// LogFormat is format of console log output: json or console
+	LogFormat string
+	// LogPayloads turns on logging of gRPC request and response messages
+	LogPayloads bool
+	// LogRedactFields is comma separated list of message fields redacted from logged payloads
+	LogRedactFields string
+	// LogErrorStacks turns on logging of stack traces of server side errors
+	LogErrorStacks bool
+	// LogSamplingInitial is number of identical log entries logged every second, sampling is disabled if 0
+	LogSamplingInitial int
+	// LogSamplingThereafter is how often identical entries are logged after LogSamplingInitial is exceeded
+	LogSamplingThereafter int
+	// LogFile is file entries below Error level are also written to
+	LogFile string
+	// LogErrorFile is file entries of Error level and above are also written to
+	LogErrorFile string
+	// LogFileMaxSize is size in megabytes log files are rotated at
+	LogFileMaxSize int
+	// LogFileMaxAge is number of days rotated log files are kept
+	LogFileMaxAge int
+	// LogFileMaxBackups is number of rotated log files kept
+	LogFileMaxBackups int
+	// LogFileCompress turns on compression of rotated log files
+	LogFileCompress bool
+	// LogHTTPAccessFormat is format of HTTP access log: json or combined
+	LogHTTPAccessFormat string
+}
+
+// DatabaseDSN returns MySQL data source name of database settings
+func (cfg *Config) DatabaseDSN() string {
+	// add MySQL driver
+	param := "parseTime=true"
+
+	return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s",
+		cfg.DatastoreDBUser,
+		cfg.DatastoreDBPassword,
+		cfg.DatastoreDBHost,
+		cfg.DatastoreDBSchema,
+		param,
+	)
+}
+
+// DefaultConfig returns configuration with defaults of server flags
+func DefaultConfig() Config {
+	return Config{
+		GRPCPort:              "9090",
+		GRPCDefaultTimeout:    30 * time.Second,
+		GRPCKeepaliveTime:     2 * time.Hour,
+		GRPCKeepaliveTimeout:  20 * time.Second,
+		GRPCKeepaliveMinTime:  5 * time.Minute,
+		HTTPPort:              "8080",
+		HTTPDocs:              true,
+		HTTPCompressionTypes:  "application/json",
+		HTTPReadHeaderTimeout: 5 * time.Second,
+		HTTPReadTimeout:       30 * time.Second,
+		HTTPWriteTimeout:      60 * time.Second,
+		HTTPIdleTimeout:       120 * time.Second,
+		HTTPRequestTimeout:    30 * time.Second,
+		ShutdownTimeout:       30 * time.Second,
+		AdminLocalhostOnly:    true,
+
+		DatastoreDBHost:               "localhost:3306",
+		DatastoreDBUser:               "root",
+		DatastoreDBSchema:             "todo",
+		DatastoreHealthCheckInterval:  5 * time.Second,
+		DatastoreErrorRateWindow:      time.Minute,
+		DatastoreErrorRateMinRequests: 20,
+
+		EventsKafkaTopic:       "todo-events",
+		EventsNATSStream:       "TODO_EVENTS",
+		EventsNATSSubject:      "todo.events",
+		EventsEncoding:         events.EncodingJSON,
+		EventsRelayInterval:    time.Second,
+		EventsRelayMaxAttempts: events.DefaultOutboxOptions.MaxAttempts,
+
+		WebhookPollInterval: time.Second,
+		WebhookTimeout:      webhook.DefaultDeliveryOptions.Timeout,
+		WebhookMaxAttempts:  webhook.DefaultDeliveryOptions.MaxAttempts,
+
+		ReminderInterval:  reminder.DefaultOptions.Interval,
+		ReminderMaxDelay:  reminder.DefaultOptions.MaxDelay,
+		ReminderEmailPort: 587,
+
+		ReminderChatFormat:    notify.ChatSlack,
+		ReminderChatRateLimit: 1,
+		ReminderDigestWindow:  24 * time.Hour,
+
+		TLSReloadInterval:  time.Minute,
+		TracingServiceName: "todo-service",
+
+		LogFormat:             logger.FormatJSON,
+		LogRedactFields:       strings.Join(grpcmiddleware.DefaultSensitiveFields, ","),
+		LogSamplingThereafter: 100,
+		LogFileMaxSize:        100,
+		LogHTTPAccessFormat:   restmiddleware.AccessLogJSON,
+	}
+}
@@ -0,0 +1,176 @@
+package server
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/maslow123/go-grpc/pkg/protocol/listen"
+	"github.com/maslow123/go-grpc/pkg/protocol/singleport"
+)
+
+// listeners are listeners of gRPC server and HTTP gateway, servers listen on their ports themselves if they are empty
+type listeners struct {
+	grpc []net.Listener
+	http []net.Listener
+	// grpcTLSConfig is TLS configuration of gRPC server, it is nil if TLS is terminated by listeners
+	grpcTLSConfig *tls.Config
+	// grpcEndpoint is address gateway dials gRPC server at
+	grpcEndpoint string
+	// shared splits gRPC port between both servers if it is not nil
+	shared *singleport.Listeners
+}
+
+// splitList splits comma separated list, empty items are skipped
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); len(item) > 0 {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// grpcAddresses returns addresses gRPC server listens on with TLS if it is configured
+func (cfg *Config) grpcAddresses() []string {
+	switch {
+	case len(cfg.GRPCListen) > 0:
+		return []string{cfg.GRPCListen}
+	case len(cfg.GRPCAddr) > 0:
+		return splitList(cfg.GRPCAddr)
+	default:
+		return []string{":" + cfg.GRPCPort}
+	}
+}
+
+// httpAddresses returns addresses HTTP gateway listens on with TLS if it is configured
+func (cfg *Config) httpAddresses() []string {
+	switch {
+	case len(cfg.HTTPListen) > 0:
+		return []string{cfg.HTTPListen}
+	case len(cfg.HTTPAddr) > 0:
+		return splitList(cfg.HTTPAddr)
+	default:
+		return []string{":" + cfg.HTTPPort}
+	}
+}
+
+// httpsPort returns TCP port HTTP gateway is served over TLS on, it is the gRPC port if they share it
+func (cfg *Config) httpsPort() string {
+	address := cfg.httpAddresses()[0]
+	if cfg.SinglePort {
+		address = cfg.grpcAddresses()[0]
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return ""
+	}
+	return port
+}
+
+// openListeners listens on addresses of both servers, servers are left to listen themselves
+// if they listen on single TCP address without PROXY protocol.
+// Gateway dials gRPC server at its first address.
+func openListeners(cfg *Config, tlsConfig *tls.Config) (*listeners, error) {
+	grpcAddresses := cfg.grpcAddresses()
+	grpcPlaintext := splitList(cfg.GRPCPlaintextAddr)
+	httpAddresses := cfg.httpAddresses()
+	httpPlaintext := splitList(cfg.HTTPPlaintextAddr)
+
+	ls := &listeners{grpcTLSConfig: tlsConfig, grpcEndpoint: grpcAddresses[0]}
+	if !listen.IsUnix(ls.grpcEndpoint) {
+		ls.grpcEndpoint = dialAddress(ls.grpcEndpoint)
+	}
+
+	// listeners opened before failure are closed, so their ports are not left bound
+	opened := false
+	defer func() {
+		if opened {
+			return
+		}
+		for _, l := range append(ls.grpc, ls.http...) {
+			l.Close()
+		}
+	}()
+
+	// share gRPC port with gateway, TLS is terminated by shared listener then
+	if cfg.SinglePort {
+		root, err := cfg.listen(grpcAddresses[0])
+		if err != nil {
+			return nil, fmt.Errorf("Failed to listen on gRPC port: %v", err)
+		}
+		ls.shared = singleport.New(root, tlsConfig)
+		ls.grpc, ls.http = []net.Listener{ls.shared.GRPC}, []net.Listener{ls.shared.HTTP}
+		ls.grpcTLSConfig = nil
+		opened = true
+		return ls, nil
+	}
+
+	if len(grpcAddresses) > 1 || len(grpcPlaintext) > 0 || len(cfg.GRPCListen) > 0 || cfg.ProxyProtocol {
+		// gRPC server credentials apply to all its listeners, so TLS is terminated by listeners
+		// if some of them are plain text
+		terminate := tlsConfig != nil && len(grpcPlaintext) > 0
+		if terminate {
+			ls.grpcTLSConfig = nil
+		}
+
+		for _, address := range grpcAddresses {
+			l, err := cfg.listen(address)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to listen on gRPC address '%s': %v", address, err)
+			}
+			if terminate {
+				l = listen.TLS(l, tlsConfig)
+			}
+			ls.grpc = append(ls.grpc, l)
+		}
+		for _, address := range grpcPlaintext {
+			l, err := cfg.listen(address)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to listen on gRPC address '%s': %v", address, err)
+			}
+			ls.grpc = append(ls.grpc, l)
+		}
+	}
+
+	if len(httpAddresses) > 1 || len(httpPlaintext) > 0 || len(cfg.HTTPListen) > 0 || cfg.ProxyProtocol {
+		for _, address := range httpAddresses {
+			l, err := cfg.listen(address)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to listen on HTTP address '%s': %v", address, err)
+			}
+			if tlsConfig != nil {
+				l = listen.TLS(l, tlsConfig)
+			}
+			ls.http = append(ls.http, l)
+		}
+		for _, address := range httpPlaintext {
+			l, err := cfg.listen(address)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to listen on HTTP address '%s': %v", address, err)
+			}
+			ls.http = append(ls.http, l)
+		}
+	}
+
+	opened = true
+	return ls, nil
+}
+
+// listen listens on address, PROXY protocol headers of load balancers are read if they are turned on
+func (cfg *Config) listen(address string) (net.Listener, error) {
+	l, err := listen.Listen(address)
+	if err != nil || !cfg.ProxyProtocol {
+		return l, err
+	}
+
+	pl, err := listen.ProxyProtocol(l, splitList(cfg.ProxyProtocolTrusted))
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+	return pl, nil
+}
@@ -0,0 +1,556 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	// mysql driver
+	_ "github.com/go-sql-driver/mysql"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/certs"
+	"github.com/maslow123/go-grpc/pkg/errorrate"
+	"github.com/maslow123/go-grpc/pkg/errorreport"
+	"github.com/maslow123/go-grpc/pkg/events"
+	"github.com/maslow123/go-grpc/pkg/jobs"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/notify"
+	"github.com/maslow123/go-grpc/pkg/protocol/admin"
+	"github.com/maslow123/go-grpc/pkg/protocol/grpc"
+	grpcmiddleware "github.com/maslow123/go-grpc/pkg/protocol/grpc/middleware"
+	"github.com/maslow123/go-grpc/pkg/protocol/metrics"
+	"github.com/maslow123/go-grpc/pkg/protocol/rest"
+	restmiddleware "github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+	"github.com/maslow123/go-grpc/pkg/reminder"
+	"github.com/maslow123/go-grpc/pkg/tracing"
+	"github.com/maslow123/go-grpc/pkg/webhook"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Server runs gRPC server and HTTP gateway of Todo Service, it is created by New
+type Server struct {
+	cfg      Config
+	log      *zap.Logger
+	db       *sql.DB
+	store    v1.TodoStore
+	readOnly *grpcmiddleware.ReadOnly
+
+	// mu guards tlsCerts, it is set while server is running
+	mu       sync.Mutex
+	tlsCerts *certs.Reloader
+}
+
+// Option configures Server
+type Option func(*Server)
+
+// WithConfig replaces whole configuration, options changing single settings must follow it
+func WithConfig(cfg Config) Option {
+	return func(s *Server) {
+		s.cfg = cfg
+	}
+}
+
+// WithLogger sets logger of the server, nothing is logged if it is not set
+func WithLogger(log *zap.Logger) Option {
+	return func(s *Server) {
+		s.log = log
+	}
+}
+
+// WithDB sets database of the service, database settings are not used then.
+// It is not closed by the server.
+func WithDB(db *sql.DB) Option {
+	return func(s *Server) {
+		s.db = db
+	}
+}
+
+// WithStore sets store todos are kept in instead of todo table of database, events are published for its changes
+// too. Admin Service, webhooks and reminders keep using database, since they claim rows by SQL.
+func WithStore(store v1.TodoStore) Option {
+	return func(s *Server) {
+		s.store = store
+	}
+}
+
+// WithGRPCAddr sets comma separated list of TCP addresses gRPC server listens on
+func WithGRPCAddr(address string) Option {
+	return func(s *Server) {
+		s.cfg.GRPCAddr = address
+	}
+}
+
+// WithHTTPAddr sets comma separated list of TCP addresses HTTP gateway listens on
+func WithHTTPAddr(address string) Option {
+	return func(s *Server) {
+		s.cfg.HTTPAddr = address
+	}
+}
+
+// New creates server with DefaultConfig changed by opts
+func New(opts ...Option) *Server {
+	s := &Server{cfg: DefaultConfig()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.log = logger.OrNop(s.log)
+
+	s.readOnly = &grpcmiddleware.ReadOnly{}
+	s.readOnly.Set(s.cfg.ReadOnly)
+
+	return s
+}
+
+// SetReadOnly turns read-only mode on or off, calls changing todos are rejected while it is on
+func (s *Server) SetReadOnly(on bool) {
+	s.readOnly.Set(on)
+}
+
+// ReloadTLS reloads TLS certificate files of running server, it does nothing if TLS is off
+func (s *Server) ReloadTLS() error {
+	s.mu.Lock()
+	tlsCerts := s.tlsCerts
+	s.mu.Unlock()
+
+	if tlsCerts == nil {
+		return nil
+	}
+	return tlsCerts.Reload()
+}
+
+// closingPublisher is events.Publisher sending events in background until it is closed
+type closingPublisher interface {
+	events.Publisher
+	Close() error
+}
+
+// newPublisher returns publisher of events of todo changes configured by cfg and its name stored in outbox,
+// it is nil if events are not published
+func newPublisher(cfg *Config, log *zap.Logger) (string, closingPublisher, error) {
+	switch {
+	case len(cfg.EventsKafkaBrokers) > 0:
+		p, err := events.NewKafkaPublisher(events.KafkaConfig{
+			Brokers:  splitList(cfg.EventsKafkaBrokers),
+			Topic:    cfg.EventsKafkaTopic,
+			Encoding: cfg.EventsEncoding,
+		}, log)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to create Kafka publisher: %v", err)
+		}
+		return "kafka", p, nil
+	case len(cfg.EventsNATSURL) > 0:
+		p, err := events.NewNATSPublisher(events.NATSConfig{
+			URL:      cfg.EventsNATSURL,
+			Stream:   cfg.EventsNATSStream,
+			Subject:  cfg.EventsNATSSubject,
+			Encoding: cfg.EventsEncoding,
+		}, log)
+		if err != nil {
+			return "", nil, fmt.Errorf("Failed to create NATS publisher: %v", err)
+		}
+		return "nats", p, nil
+	}
+	return "", nil, nil
+}
+
+// newReminderChannels returns notification channels reminders are sent through, there are none if none is configured
+func newReminderChannels(ctx context.Context, cfg *Config, db *sql.DB, log *zap.Logger) ([]notify.Channel, error) {
+	var channels []notify.Channel
+	if len(cfg.ReminderEmailHost) > 0 {
+		email, err := notify.NewEmail(notify.EmailConfig{
+			Host:     cfg.ReminderEmailHost,
+			Port:     cfg.ReminderEmailPort,
+			Username: cfg.ReminderEmailUsername,
+			Password: cfg.ReminderEmailPassword,
+			From:     cfg.ReminderEmailFrom,
+			To:       splitList(cfg.ReminderEmailTo),
+			Subject:  cfg.ReminderEmailSubject,
+			Body:     cfg.ReminderEmailBody,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create email channel: %v", err)
+		}
+		channels = append(channels, email)
+	}
+	if len(cfg.ReminderFCMCredentialsFile) > 0 || len(cfg.ReminderWebPushPublicKey) > 0 {
+		push, err := notify.NewPush(ctx, db, notify.PushConfig{
+			FCMCredentialsFile: cfg.ReminderFCMCredentialsFile,
+			FCMProjectID:       cfg.ReminderFCMProjectID,
+			VAPIDPublicKey:     cfg.ReminderWebPushPublicKey,
+			VAPIDPrivateKey:    cfg.ReminderWebPushPrivateKey,
+			VAPIDSubscriber:    cfg.ReminderWebPushSubscriber,
+		}, log)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create push channel: %v", err)
+		}
+		channels = append(channels, push)
+	}
+	if len(cfg.ReminderChatWebhookURL) > 0 {
+		chat, err := notify.NewChat(notify.ChatConfig{
+			WebhookURL:     cfg.ReminderChatWebhookURL,
+			Format:         cfg.ReminderChatFormat,
+			Template:       cfg.ReminderChatTemplate,
+			DigestTemplate: cfg.ReminderChatDigestTemplate,
+			RateLimit:      cfg.ReminderChatRateLimit,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Failed to create chat channel: %v", err)
+		}
+		channels = append(channels, chat)
+	}
+	return channels, nil
+}
+
+// Run runs gRPC server and HTTP gateway until ctx is done or any of them fails,
+// nil is returned if they are stopped by ctx
+func (s *Server) Run(ctx context.Context) error {
+	cfg := s.cfg
+	log := s.log
+
+	if err := cfg.validate(s.db == nil); err != nil {
+		return err
+	}
+
+	// servers and background workers share ctx, it is canceled when any of them fails
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	g, ctx := errgroup.WithContext(ctx)
+
+	// goroutines already started are stopped and waited for when Run fails,
+	// so none of them outlives Run or uses resources closed by deferred calls
+	stop := func(err error) error {
+		cancel()
+		_ = g.Wait()
+		return err
+	}
+
+	// load TLS certificate, it is reloaded by ReloadTLS and when files change
+	var tlsConfig *tls.Config
+	if len(cfg.TLSCertFile) > 0 {
+		tlsCerts, err := certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, log)
+		if err != nil {
+			return fmt.Errorf("Failed to load TLS certificate: %v", err)
+		}
+		g.Go(func() error {
+			tlsCerts.Watch(ctx, cfg.TLSReloadInterval)
+			return nil
+		})
+
+		s.mu.Lock()
+		s.tlsCerts = tlsCerts
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			s.tlsCerts = nil
+			s.mu.Unlock()
+		}()
+
+		tlsConfig = tlsCerts.TLSConfig()
+	}
+
+	// report panics and server side errors to Sentry
+	var reporter errorreport.Reporter
+	if len(cfg.SentryDSN) > 0 {
+		sentry, err := errorreport.NewSentry(cfg.SentryDSN, cfg.SentryEnvironment)
+		if err != nil {
+			return stop(fmt.Errorf("Failed to initialize Sentry: %v", err))
+		}
+		defer sentry.Flush(2 * time.Second)
+
+		reporter = sentry
+	}
+
+	// export spans of calls and database queries
+	if len(cfg.TracingOTLPEndpoint) > 0 {
+		shutdown, err := tracing.Init(ctx, cfg.TracingOTLPEndpoint, cfg.TracingServiceName)
+		if err != nil {
+			return stop(fmt.Errorf("Failed to initialize tracing: %v", err))
+		}
+		defer func() {
+			_ = shutdown(context.Background())
+		}()
+	}
+
+	db := s.db
+	if db == nil {
+		var err error
+		db, err = tracing.OpenDB("mysql", cfg.DatabaseDSN())
+		if err != nil {
+			return stop(fmt.Errorf("Failed to open database: %v", err))
+		}
+		defer db.Close()
+	}
+
+	// track database error rate, server stops being ready while it exceeds threshold
+	readinessCheck := db.PingContext
+	var dbErrors *errorrate.Tracker
+	if cfg.DatastoreErrorRateThreshold > 0 {
+		registry := errorrate.NewRegistry()
+		registry.OnChange(errorrate.LogHook(log))
+		if len(cfg.AlertWebhookURL) > 0 {
+			registry.OnChange(errorrate.WebhookHook(cfg.AlertWebhookURL, cfg.AlertWebhookSecret, log))
+		}
+
+		dbErrors = registry.Register("database", errorrate.Options{
+			Window:      cfg.DatastoreErrorRateWindow,
+			Threshold:   cfg.DatastoreErrorRateThreshold,
+			MinRequests: cfg.DatastoreErrorRateMinRequests,
+		})
+
+		readinessCheck = func(ctx context.Context) error {
+			if err := db.PingContext(ctx); err != nil {
+				return err
+			}
+			return registry.Check(ctx)
+		}
+	}
+
+	// background jobs are stopped together with servers
+	runner := jobs.NewRunner(log)
+
+	// publish events of todo changes
+	publishers := map[string]events.Publisher{}
+	name, publisher, err := newPublisher(&cfg, log)
+	if err != nil {
+		return stop(err)
+	}
+	if publisher != nil {
+		// buffered events are sent after servers are stopped
+		defer publisher.Close()
+
+		publishers[name] = publisher
+	}
+	if cfg.WebhookDelivery {
+		dispatcher := webhook.NewDispatcher(db, webhook.DeliveryOptions{
+			Timeout:     cfg.WebhookTimeout,
+			MaxAttempts: cfg.WebhookMaxAttempts,
+		}, log)
+		runner.Register("webhook-delivery", jobs.Every(cfg.WebhookPollInterval), dispatcher.DeliverPending)
+
+		publishers["webhook"] = dispatcher
+	}
+	store := s.store
+	switch {
+	case store == nil && len(publishers) > 0:
+		// events are stored in transaction of the change and relayed, so they are not lost
+		outbox := events.NewOutbox(db, publishers, events.OutboxOptions{
+			MaxAttempts: cfg.EventsRelayMaxAttempts,
+		}, log)
+		runner.Register("event-relay", jobs.Every(cfg.EventsRelayInterval), outbox.Relay)
+		store = v1.NewSQLTodoStoreWithRecorder(db, outbox.Record)
+	case store == nil:
+		store = v1.NewSQLTodoStore(db)
+	case len(publishers) > 0:
+		// store set by WithStore cannot share transaction with outbox, events are published after changes
+		var all []events.Publisher
+		for _, p := range publishers {
+			all = append(all, p)
+		}
+		store = events.NewStore(store, events.Multi(all...), log)
+	}
+
+	// fire reminders of todos
+	channels, err := newReminderChannels(ctx, &cfg, db, log)
+	if err != nil {
+		return stop(err)
+	}
+	if len(channels) > 0 {
+		scheduler := reminder.NewScheduler(db, reminder.Options{
+			Interval: cfg.ReminderInterval,
+			MaxDelay: cfg.ReminderMaxDelay,
+		}, channels, log)
+		runner.Register("reminder-scheduler", jobs.Every(cfg.ReminderInterval), scheduler.FireDue)
+	}
+	if len(cfg.ReminderDigestCron) > 0 {
+		var digestChannels []notify.DigestChannel
+		for _, c := range channels {
+			if dc, ok := c.(notify.DigestChannel); ok {
+				digestChannels = append(digestChannels, dc)
+			}
+		}
+		schedule, err := jobs.Cron(cfg.ReminderDigestCron)
+		if err != nil {
+			return stop(err)
+		}
+		digester := reminder.NewDigester(db, cfg.ReminderDigestWindow, digestChannels, log)
+		runner.Register("reminder-digest", schedule, digester.Send)
+	}
+	g.Go(func() error {
+		return runner.Run(ctx)
+	})
+
+	v1API := v1.NewTodoServiceServerWithStore(store)
+	v1AdminAPI := v1.NewAdminServiceServer(db)
+
+	// run metrics server
+	if len(cfg.MetricsPort) > 0 {
+		g.Go(func() error {
+			return metrics.RunServer(ctx, cfg.MetricsPort, log)
+		})
+	}
+
+	// log runtime stats
+	if cfg.MetricsLogRuntimeInterval > 0 {
+		g.Go(func() error {
+			metrics.LogRuntimeStats(ctx, log, cfg.MetricsLogRuntimeInterval)
+			return nil
+		})
+	}
+
+	// run admin server
+	if len(cfg.AdminPort) > 0 {
+		g.Go(func() error {
+			return admin.RunServer(ctx, cfg.AdminPort, cfg.AdminLocalhostOnly, log)
+		})
+	}
+	if len(cfg.AdminChannelzPort) > 0 {
+		g.Go(func() error {
+			return admin.RunChannelzServer(ctx, cfg.AdminChannelzPort, cfg.AdminLocalhostOnly, log)
+		})
+	}
+
+	// listen on all addresses of both servers before they are started
+	ls, err := openListeners(&cfg, tlsConfig)
+	if err != nil {
+		return stop(err)
+	}
+	if ls.shared != nil {
+		g.Go(ls.shared.Serve)
+	}
+
+	// run HTTPS redirect server
+	if len(cfg.HTTPRedirectAddr) > 0 {
+		g.Go(func() error {
+			return rest.RunRedirectServer(ctx, cfg.HTTPRedirectAddr, cfg.httpsPort(), log)
+		})
+	}
+
+	// gRPC server is stopped only after gateway is drained,
+	// so requests it forwards are handled while gRPC server is still serving
+	grpcCtx, stopGRPC := context.WithCancel(context.Background())
+	defer stopGRPC()
+
+	// run HTTP gateway
+	g.Go(func() error {
+		defer stopGRPC()
+
+		return rest.RunServer(ctx, rest.Config{
+			Logger:       log,
+			GRPCPort:     cfg.GRPCPort,
+			GRPCEndpoint: ls.grpcEndpoint,
+			HTTPPort:     cfg.HTTPPort,
+			HTTPAddress:  cfg.httpAddresses()[0],
+			Listeners:    ls.http,
+			TLSConfig:    tlsConfig,
+			H2C:          cfg.HTTPH2C,
+			Docs:         cfg.HTTPDocs,
+			GraphQL:      cfg.HTTPGraphQL,
+
+			HSTSMaxAge:            cfg.HTTPHSTSMaxAge,
+			HSTSIncludeSubdomains: cfg.HTTPHSTSIncludeSubdomains,
+
+			ForwardHeaders:   strings.Split(cfg.HTTPForwardHeaders, ","),
+			ResponseMetadata: strings.Split(cfg.HTTPResponseMetadata, ","),
+			Marshal: rest.MarshalOptions{
+				EmitUnpopulated: cfg.HTTPJSONEmitUnpopulated,
+				CamelCase:       cfg.HTTPJSONCamelCase,
+				EnumsAsInts:     cfg.HTTPJSONEnumsAsInts,
+				Indent:          jsonIndent(cfg.HTTPJSONIndent),
+			},
+			Compression: restmiddleware.CompressionOptions{
+				MinSize:      cfg.HTTPCompressionMinSize,
+				ContentTypes: strings.Split(cfg.HTTPCompressionTypes, ","),
+			},
+			MaxRecvMsgSize:    cfg.GRPCMaxSendMsgSize,
+			MaxSendMsgSize:    cfg.GRPCMaxRecvMsgSize,
+			HMACSecret:        cfg.AuthHMACSecret,
+			ReadinessCheck:    readinessCheck,
+			ErrorReporter:     reporter,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			ReadTimeout:       cfg.HTTPReadTimeout,
+			WriteTimeout:      cfg.HTTPWriteTimeout,
+			IdleTimeout:       cfg.HTTPIdleTimeout,
+			RequestTimeout:    cfg.HTTPRequestTimeout,
+			ShutdownTimeout:   cfg.ShutdownTimeout,
+			AccessLogFormat:   cfg.LogHTTPAccessFormat,
+		})
+	})
+
+	// run gRPC server
+	g.Go(func() error {
+		if ls.shared != nil {
+			// both servers are stopped once gRPC server is stopped
+			defer ls.shared.Close()
+		}
+
+		return grpc.RunServer(grpcCtx, v1API, v1AdminAPI, grpc.Config{
+			Logger:     log,
+			Port:       cfg.GRPCPort,
+			Address:    cfg.grpcAddresses()[0],
+			Listeners:  ls.grpc,
+			Reflection: cfg.GRPCReflection,
+			XDS:        cfg.XDS,
+			Keepalive: keepalive.ServerParameters{
+				MaxConnectionIdle:     cfg.GRPCKeepaliveMaxConnectionIdle,
+				MaxConnectionAge:      cfg.GRPCKeepaliveMaxConnectionAge,
+				MaxConnectionAgeGrace: cfg.GRPCKeepaliveMaxConnectionAgeGrace,
+				Time:                  cfg.GRPCKeepaliveTime,
+				Timeout:               cfg.GRPCKeepaliveTimeout,
+			},
+			KeepaliveEnforcement: keepalive.EnforcementPolicy{
+				MinTime:             cfg.GRPCKeepaliveMinTime,
+				PermitWithoutStream: cfg.GRPCKeepalivePermitWithoutStream,
+			},
+			MaxConnections:       cfg.GRPCMaxConnections,
+			MaxConcurrentStreams: uint32(cfg.GRPCMaxConcurrentStreams),
+			MaxClientCalls:       cfg.GRPCMaxClientCalls,
+			MaxRecvMsgSize:       cfg.GRPCMaxRecvMsgSize,
+			MaxSendMsgSize:       cfg.GRPCMaxSendMsgSize,
+			DefaultTimeout:       cfg.GRPCDefaultTimeout,
+			CompressionThreshold: cfg.GRPCCompressionThreshold,
+			TLSConfig:            ls.grpcTLSConfig,
+			HMACSecret:           cfg.AuthHMACSecret,
+			APIKeys:              v1.NewAPIKeyLookup(db),
+			APIKeyAuth:           cfg.AuthAPIKey,
+			LogPayloads:          cfg.LogPayloads,
+			RedactFields:         strings.Split(cfg.LogRedactFields, ","),
+			LogErrorStacks:       cfg.LogErrorStacks,
+			ErrorReporter:        reporter,
+
+			ShutdownTimeout:     cfg.ShutdownTimeout,
+			HealthCheck:         readinessCheck,
+			HealthCheckInterval: cfg.DatastoreHealthCheckInterval,
+			DatabaseErrors:      dbErrors,
+			DatabaseCircuit:     cfg.DatastoreCircuitBreaker,
+			ReadOnly:            s.readOnly,
+		})
+	})
+
+	// first failure is returned, nil if servers are stopped by ctx
+	return g.Wait()
+}
+
+// dialAddress returns address to dial server listening on address, servers listening on all interfaces are dialed over loopback
+func dialAddress(address string) string {
+	host, port, _ := net.SplitHostPort(address)
+	if ip := net.ParseIP(host); len(host) == 0 || (ip != nil && ip.IsUnspecified()) {
+		return net.JoinHostPort("localhost", port)
+	}
+	return address
+}
+
+// jsonIndent returns indentation of JSON responses
+func jsonIndent(indent bool) string {
+	if indent {
+		return "  "
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/maslow123/go-grpc/pkg/events"
+	"github.com/maslow123/go-grpc/pkg/jobs"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"github.com/maslow123/go-grpc/pkg/notify"
+	"github.com/maslow123/go-grpc/pkg/protocol/listen"
+	restmiddleware "github.com/maslow123/go-grpc/pkg/protocol/rest/middleware"
+)
+
+// validationErrors are problems of configuration, each names flag it is caused by
+type validationErrors []string
+
+// add records problem of flag key
+func (e *validationErrors) add(key string, format string, args ...interface{}) {
+	*e = append(*e, "-"+key+": "+fmt.Sprintf(format, args...))
+}
+
+// Error lists all problems
+func (e validationErrors) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e, "\n  ")
+}
+
+// validPort reports whether port is TCP port number
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
+
+// Validate checks whole configuration, all problems are reported at once
+func (cfg *Config) Validate() error {
+	return cfg.validate(true)
+}
+
+// validate checks configuration, database settings are checked only if database is opened by server
+func (cfg *Config) validate(database bool) error {
+	var errs validationErrors
+
+	// ports
+	ports := []struct {
+		key, value string
+	}{
+		{"grpc-port", cfg.GRPCPort},
+		{"http-port", cfg.HTTPPort},
+		{"metrics-port", cfg.MetricsPort},
+		{"admin-port", cfg.AdminPort},
+		{"admin-channelz-port", cfg.AdminChannelzPort},
+	}
+	for _, p := range ports {
+		if len(p.value) > 0 && !validPort(p.value) {
+			errs.add(p.key, "invalid TCP port '%s', 1-65535 expected", p.value)
+		}
+	}
+
+	// gRPC server addresses
+	if len(cfg.GRPCListen) > 0 && !listen.IsUnix(cfg.GRPCListen) {
+		errs.add("grpc-listen", "invalid Unix domain socket '%s', unix:///path expected", cfg.GRPCListen)
+	}
+	for _, address := range splitList(cfg.GRPCAddr) {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			errs.add("grpc-addr", "invalid TCP address '%s', host:port expected", address)
+		}
+	}
+	for _, address := range splitList(cfg.GRPCPlaintextAddr) {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			errs.add("grpc-plaintext-addr", "invalid TCP address '%s', host:port expected", address)
+		}
+	}
+	if len(cfg.GRPCPort) == 0 && len(cfg.GRPCAddr) == 0 && len(cfg.GRPCListen) == 0 {
+		errs.add("grpc-port", "gRPC server needs port, -grpc-addr or -grpc-listen")
+	}
+	if cfg.XDS && (cfg.SinglePort || len(cfg.GRPCListen) > 0) {
+		errs.add("xds", "xDS server must listen on its own TCP port, it cannot be used with -single-port or -grpc-listen")
+	}
+	if cfg.SinglePort && (len(splitList(cfg.GRPCAddr)) > 1 || len(cfg.GRPCPlaintextAddr) > 0) {
+		errs.add("single-port", "single port is shared by listening on one address, it cannot be used with several -grpc-addr or -grpc-plaintext-addr")
+	}
+
+	// PROXY protocol
+	if cfg.ProxyProtocol && len(splitList(cfg.ProxyProtocolTrusted)) == 0 {
+		errs.add("proxy-protocol-trusted", "CIDRs of load balancers are required with -proxy-protocol")
+	}
+	for _, cidr := range splitList(cfg.ProxyProtocolTrusted) {
+		if _, _, err := net.ParseCIDR(cidr); err != nil && net.ParseIP(cidr) == nil {
+			errs.add("proxy-protocol-trusted", "invalid CIDR '%s', e.g. 10.0.0.0/8 expected", cidr)
+		}
+	}
+
+	// HTTP gateway addresses
+	if len(cfg.HTTPListen) > 0 && !listen.IsUnix(cfg.HTTPListen) {
+		errs.add("http-listen", "invalid Unix domain socket '%s', unix:///path expected", cfg.HTTPListen)
+	}
+	for _, address := range splitList(cfg.HTTPAddr) {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			errs.add("http-addr", "invalid TCP address '%s', host:port expected", address)
+		}
+	}
+	for _, address := range splitList(cfg.HTTPPlaintextAddr) {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			errs.add("http-plaintext-addr", "invalid TCP address '%s', host:port expected", address)
+		}
+	}
+	if len(cfg.HTTPPort) == 0 && len(cfg.HTTPAddr) == 0 && len(cfg.HTTPListen) == 0 && !cfg.SinglePort {
+		errs.add("http-port", "HTTP gateway needs port, -http-addr, -http-listen or -single-port")
+	}
+
+	// database
+	if database && len(cfg.DatastoreDBHost) == 0 {
+		errs.add("db-host", "MySQL host is required")
+	}
+	if database && len(cfg.DatastoreDBUser) == 0 {
+		errs.add("db-user", "MySQL user is required")
+	}
+	if database && len(cfg.DatastoreDBSchema) == 0 {
+		errs.add("db-schema", "MySQL schema is required")
+	}
+	if cfg.DatastoreHealthCheckInterval <= 0 {
+		errs.add("db-health-check-interval", "must be positive")
+	}
+	if cfg.DatastoreErrorRateThreshold < 0 || cfg.DatastoreErrorRateThreshold > 1 {
+		errs.add("db-error-rate-threshold", "invalid error rate '%v', 0..1 expected", cfg.DatastoreErrorRateThreshold)
+	}
+
+	// events
+	for _, address := range splitList(cfg.EventsKafkaBrokers) {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			errs.add("events-kafka-brokers", "invalid TCP address '%s', host:port expected", address)
+		}
+	}
+	if len(cfg.EventsKafkaBrokers) > 0 && len(cfg.EventsKafkaTopic) == 0 {
+		errs.add("events-kafka-topic", "Kafka topic is required with -events-kafka-brokers")
+	}
+	if len(cfg.EventsKafkaBrokers) > 0 && len(cfg.EventsNATSURL) > 0 {
+		errs.add("events-nats-url", "events are published either to Kafka or to NATS, it cannot be used with -events-kafka-brokers")
+	}
+	if len(cfg.EventsNATSURL) > 0 && (len(cfg.EventsNATSStream) == 0 || len(cfg.EventsNATSSubject) == 0) {
+		errs.add("events-nats-stream", "JetStream stream and subject are required with -events-nats-url")
+	}
+	if cfg.EventsEncoding != events.EncodingJSON && cfg.EventsEncoding != events.EncodingProtobuf {
+		errs.add("events-encoding", "invalid event encoding '%s', %s or %s expected", cfg.EventsEncoding, events.EncodingJSON, events.EncodingProtobuf)
+	}
+	if cfg.EventsRelayInterval <= 0 {
+		errs.add("events-relay-interval", "must be positive")
+	}
+	if cfg.EventsRelayMaxAttempts <= 0 {
+		errs.add("events-relay-max-attempts", "must be positive")
+	}
+
+	// webhooks
+	if cfg.WebhookDelivery && cfg.WebhookPollInterval <= 0 {
+		errs.add("webhook-poll-interval", "must be positive when webhooks are delivered")
+	}
+	if cfg.WebhookDelivery && cfg.WebhookMaxAttempts <= 0 {
+		errs.add("webhook-max-attempts", "must be positive when webhooks are delivered")
+	}
+
+	// reminders
+	if cfg.ReminderInterval <= 0 {
+		errs.add("reminder-interval", "must be positive")
+	}
+	if cfg.ReminderMaxDelay <= 0 {
+		errs.add("reminder-max-delay", "must be positive")
+	}
+	if len(cfg.ReminderEmailHost) > 0 {
+		if cfg.ReminderEmailPort < 1 || cfg.ReminderEmailPort > 65535 {
+			errs.add("reminder-email-port", "invalid TCP port '%d', 1-65535 expected", cfg.ReminderEmailPort)
+		}
+		if len(cfg.ReminderEmailFrom) == 0 || len(splitList(cfg.ReminderEmailTo)) == 0 {
+			errs.add("reminder-email-to", "sender and recipients are required with -reminder-email-host")
+		}
+	}
+	if (len(cfg.ReminderWebPushPublicKey) > 0) != (len(cfg.ReminderWebPushPrivateKey) > 0) {
+		errs.add("reminder-webpush-private-key", "both VAPID keys are required for Web Push")
+	}
+	if len(cfg.ReminderWebPushPublicKey) > 0 && len(cfg.ReminderWebPushSubscriber) == 0 {
+		errs.add("reminder-webpush-subscriber", "contact email is required with -reminder-webpush-public-key")
+	}
+	if len(cfg.ReminderChatWebhookURL) > 0 {
+		if u, err := url.Parse(cfg.ReminderChatWebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			errs.add("reminder-chat-webhook-url", "invalid URL '%s', http or https URL expected", cfg.ReminderChatWebhookURL)
+		}
+		if cfg.ReminderChatFormat != notify.ChatSlack && cfg.ReminderChatFormat != notify.ChatTeams {
+			errs.add("reminder-chat-format", "invalid chat format '%s', %s or %s expected", cfg.ReminderChatFormat, notify.ChatSlack, notify.ChatTeams)
+		}
+		if cfg.ReminderChatRateLimit <= 0 {
+			errs.add("reminder-chat-rate-limit", "must be positive")
+		}
+	}
+	if len(cfg.ReminderDigestCron) > 0 {
+		if _, err := jobs.Cron(cfg.ReminderDigestCron); err != nil {
+			errs.add("reminder-digest-cron", "%v", err)
+		}
+		if len(cfg.ReminderChatWebhookURL) == 0 {
+			errs.add("reminder-digest-cron", "digests are posted to chat, -reminder-chat-webhook-url is required")
+		}
+		if cfg.ReminderDigestWindow <= 0 {
+			errs.add("reminder-digest-window", "must be positive")
+		}
+	}
+
+	// TLS
+	if len(cfg.TLSCertFile) > 0 && len(cfg.TLSKeyFile) == 0 {
+		errs.add("tls-key-file", "private key is required with -tls-cert-file")
+	}
+	if len(cfg.TLSKeyFile) > 0 && len(cfg.TLSCertFile) == 0 {
+		errs.add("tls-cert-file", "certificate is required with -tls-key-file")
+	}
+	if cfg.TLSReloadInterval < 0 {
+		errs.add("tls-reload-interval", "must not be negative")
+	}
+	if len(cfg.HTTPRedirectAddr) > 0 {
+		if len(cfg.TLSCertFile) == 0 {
+			errs.add("http-redirect-addr", "HTTPS redirect server requires -tls-cert-file")
+		}
+		if _, _, err := net.SplitHostPort(cfg.HTTPRedirectAddr); err != nil {
+			errs.add("http-redirect-addr", "invalid TCP address '%s', host:port expected", cfg.HTTPRedirectAddr)
+		}
+	}
+
+	// logging
+	if cfg.LogLevel < -1 || cfg.LogLevel > 5 {
+		errs.add("log-level", "invalid log level %d, -1 (Debug) to 5 (Fatal) expected", cfg.LogLevel)
+	}
+	if cfg.LogFormat != logger.FormatJSON && cfg.LogFormat != logger.FormatConsole {
+		errs.add("log-format", "invalid log format '%s', %s or %s expected", cfg.LogFormat, logger.FormatJSON, logger.FormatConsole)
+	}
+	if cfg.LogHTTPAccessFormat != restmiddleware.AccessLogJSON && cfg.LogHTTPAccessFormat != restmiddleware.AccessLogCombined {
+		errs.add("log-http-access-format", "invalid HTTP access log format '%s', %s or %s expected",
+			cfg.LogHTTPAccessFormat, restmiddleware.AccessLogJSON, restmiddleware.AccessLogCombined)
+	}
+	if cfg.LogSamplingInitial > 0 && cfg.LogSamplingThereafter <= 0 {
+		errs.add("log-sampling-thereafter", "must be positive when sampling is enabled by -log-sampling-initial")
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
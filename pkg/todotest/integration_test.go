@@ -0,0 +1,340 @@
+//go:build integration
+// +build integration
+
+package todotest_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/client"
+	"github.com/maslow123/go-grpc/pkg/jobs"
+	"github.com/maslow123/go-grpc/pkg/notify"
+	grpcserver "github.com/maslow123/go-grpc/pkg/protocol/grpc"
+	"github.com/maslow123/go-grpc/pkg/reminder"
+	"github.com/maslow123/go-grpc/pkg/todotest"
+)
+
+// callTimeout is how long single call of test may take
+const callTimeout = 10 * time.Second
+
+// newMySQLServer starts Todo and Admin Service with gateway backed by MySQL in container,
+// client sends API key with admin scope
+func newMySQLServer(t *testing.T, db *todotest.Database) *todotest.Server {
+	db.Truncate(t)
+	admin := v1.NewAdminServiceServer(db.DB)
+	key, err := admin.CreateApiKey(context.Background(), &v1.CreateApiKeyRequest{Api: "v1", Name: "test", Scopes: []string{"admin"}})
+	if err != nil {
+		t.Fatalf("failed to create admin API key: %v", err)
+	}
+
+	return todotest.NewServer(t, v1.NewSQLTodoStore(db.DB),
+		todotest.WithGateway(),
+		todotest.WithAdminServer(admin),
+		todotest.WithServerConfig(grpcserver.Config{HealthCheck: db.DB.PingContext, APIKeys: v1.NewAPIKeyLookup(db.DB)}),
+		todotest.WithClientOptions(client.WithMetadata(client.APIKeyHeader, key.Key)),
+	)
+}
+
+func TestMySQL(t *testing.T) {
+	db := todotest.StartMySQL(t)
+
+	t.Run("TodoService", func(t *testing.T) { testTodoService(t, newMySQLServer(t, db)) })
+	t.Run("AdminService", func(t *testing.T) { testAdminService(t, newMySQLServer(t, db)) })
+	t.Run("Gateway", func(t *testing.T) { testGateway(t, newMySQLServer(t, db)) })
+	t.Run("Reminders", func(t *testing.T) {
+		db.Truncate(t)
+		testReminders(t, db)
+	})
+}
+
+// testTodoService calls every Todo Service method over gRPC
+func testTodoService(t *testing.T, s *todotest.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	c := v1.NewTodoServiceClient(s.Client.Conn())
+
+	// MySQL stores timestamps with second precision
+	reminder := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+	pbReminder, _ := ptypes.TimestampProto(reminder)
+
+	created, err := c.Create(ctx, &v1.CreateRequest{Api: "v1", Todo: &v1.Todo{
+		Title: "title", Description: "description", Reminder: pbReminder,
+	}})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if created.Id <= 0 {
+		t.Fatalf("Create returned ID %d, positive ID expected", created.Id)
+	}
+
+	read, err := c.Read(ctx, &v1.ReadRequest{Api: "v1", Id: created.Id})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	got, _ := ptypes.Timestamp(read.Todo.Reminder)
+	if read.Todo.Title != "title" || read.Todo.Description != "description" || !got.Equal(reminder) {
+		t.Errorf("Read returned %v, todo as created expected", read.Todo)
+	}
+
+	updated, err := c.Update(ctx, &v1.UpdateRequest{Api: "v1", Todo: &v1.Todo{
+		Id: created.Id, Title: "new title", Description: "new description", Reminder: pbReminder,
+	}})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if updated.Updated != 1 {
+		t.Errorf("Update updated %d todos, 1 expected", updated.Updated)
+	}
+	// no rows are updated if todo does not exist
+	missing, err := c.Update(ctx, &v1.UpdateRequest{Api: "v1", Todo: &v1.Todo{
+		Id: created.Id + 1000, Title: "title", Reminder: pbReminder,
+	}})
+	if err != nil {
+		t.Fatalf("Update of missing todo failed: %v", err)
+	}
+	if missing.Updated != 0 {
+		t.Errorf("Update of missing todo updated %d todos, 0 expected", missing.Updated)
+	}
+
+	all, err := c.ReadAll(ctx, &v1.ReadAllRequest{Api: "v1"})
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if len(all.Todos) != 1 || all.Todos[0].Title != "new title" {
+		t.Errorf("ReadAll returned %v, updated todo expected", all.Todos)
+	}
+
+	if _, err := c.Delete(ctx, &v1.DeleteRequest{Api: "v1", Id: created.Id}); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := c.Delete(ctx, &v1.DeleteRequest{Api: "v1", Id: created.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("Delete of deleted todo returned %v, NotFound expected", err)
+	}
+	if _, err := c.Read(ctx, &v1.ReadRequest{Api: "v1", Id: created.Id}); status.Code(err) != codes.NotFound {
+		t.Errorf("Read of deleted todo returned %v, NotFound expected", err)
+	}
+
+	if _, err := c.Create(ctx, &v1.CreateRequest{Api: "v2", Todo: &v1.Todo{Reminder: pbReminder}}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("Create of unsupported API returned %v, Unimplemented expected", err)
+	}
+
+	version, err := c.GetVersion(ctx, &v1.GetVersionRequest{Api: "v1"})
+	if err != nil {
+		t.Fatalf("GetVersion failed: %v", err)
+	}
+	if len(version.GoVersion) == 0 {
+		t.Errorf("GetVersion returned %v, Go version expected", version)
+	}
+}
+
+// testAdminService calls every Admin Service method over gRPC
+func testAdminService(t *testing.T, s *todotest.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	c := v1.NewAdminServiceClient(s.Client.Conn())
+
+	created, err := c.CreateApiKey(ctx, &v1.CreateApiKeyRequest{Api: "v1", Name: "ci", Scopes: []string{"todo:read"}})
+	if err != nil {
+		t.Fatalf("CreateApiKey failed: %v", err)
+	}
+	if len(created.Key) == 0 || !strings.HasPrefix(created.Key, created.ApiKey.Prefix) {
+		t.Errorf("CreateApiKey returned key '%s' with prefix '%s', key starting with prefix expected",
+			created.Key, created.ApiKey.Prefix)
+	}
+
+	list, err := c.ListApiKeys(ctx, &v1.ListApiKeysRequest{Api: "v1"})
+	if err != nil {
+		t.Fatalf("ListApiKeys failed: %v", err)
+	}
+	var listed bool
+	for _, k := range list.ApiKeys {
+		listed = listed || (k.Id == created.ApiKey.Id && k.Name == "ci")
+	}
+	if !listed {
+		t.Errorf("ListApiKeys returned %v, created API key expected", list.ApiKeys)
+	}
+
+	rotated, err := c.RotateApiKey(ctx, &v1.RotateApiKeyRequest{Api: "v1", Id: created.ApiKey.Id})
+	if err != nil {
+		t.Fatalf("RotateApiKey failed: %v", err)
+	}
+	if rotated.Key == created.Key {
+		t.Errorf("RotateApiKey returned the same key, new key expected")
+	}
+
+	revoked, err := c.RevokeApiKey(ctx, &v1.RevokeApiKeyRequest{Api: "v1", Id: created.ApiKey.Id})
+	if err != nil {
+		t.Fatalf("RevokeApiKey failed: %v", err)
+	}
+	if revoked.Revoked != 1 {
+		t.Errorf("RevokeApiKey revoked %d API keys, 1 expected", revoked.Revoked)
+	}
+}
+
+// testGateway calls every Todo Service method through HTTP/REST gateway
+func testGateway(t *testing.T, s *todotest.Server) {
+	reminder := time.Now().UTC().Add(time.Hour).Truncate(time.Second).Format(time.RFC3339)
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	do(t, s, http.MethodPost, "/v1/todo",
+		fmt.Sprintf(`{"api":"v1","todo":{"title":"title","description":"description","reminder":"%s"}}`, reminder),
+		http.StatusOK, &created)
+	if len(created.ID) == 0 || created.ID == "0" {
+		t.Fatalf("POST /v1/todo returned ID '%s', positive ID expected", created.ID)
+	}
+
+	var read struct {
+		Todo struct {
+			Title    string `json:"title"`
+			Reminder string `json:"reminder"`
+		} `json:"todo"`
+	}
+	do(t, s, http.MethodGet, "/v1/todo/"+created.ID, "", http.StatusOK, &read)
+	if read.Todo.Title != "title" || read.Todo.Reminder != reminder {
+		t.Errorf("GET /v1/todo/%s returned %+v, todo as created expected", created.ID, read.Todo)
+	}
+
+	var updated struct {
+		Updated string `json:"updated"`
+	}
+	do(t, s, http.MethodPut, "/v1/todo/"+created.ID,
+		fmt.Sprintf(`{"api":"v1","todo":{"title":"new title","reminder":"%s"}}`, reminder),
+		http.StatusOK, &updated)
+	if updated.Updated != "1" {
+		t.Errorf("PUT /v1/todo/%s updated '%s' todos, 1 expected", created.ID, updated.Updated)
+	}
+
+	var all struct {
+		Todos []struct {
+			Title string `json:"title"`
+		} `json:"todos"`
+	}
+	do(t, s, http.MethodGet, "/v1/todo/all", "", http.StatusOK, &all)
+	if len(all.Todos) != 1 || all.Todos[0].Title != "new title" {
+		t.Errorf("GET /v1/todo/all returned %+v, updated todo expected", all.Todos)
+	}
+
+	do(t, s, http.MethodDelete, "/v1/todo/"+created.ID, "", http.StatusOK, nil)
+	do(t, s, http.MethodGet, "/v1/todo/"+created.ID, "", http.StatusNotFound, nil)
+
+	var version struct {
+		Api string `json:"api"`
+	}
+	do(t, s, http.MethodGet, "/version", "", http.StatusOK, &version)
+	if version.Api != "v1" {
+		t.Errorf("GET /version returned API '%s', v1 expected", version.Api)
+	}
+}
+
+// recordingChannel is notification channel recording sent reminders
+type recordingChannel struct {
+	sent chan notify.Reminder
+}
+
+func (c *recordingChannel) Name() string {
+	return "recording"
+}
+
+func (c *recordingChannel) Send(ctx context.Context, r notify.Reminder) error {
+	c.sent <- r
+	return nil
+}
+
+// testReminders runs two schedulers and checks due reminders fire once, also after they are changed
+func testReminders(t *testing.T, db *todotest.Database) {
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	store := v1.NewSQLTodoStore(db.DB)
+
+	create := func(title string, at time.Time) int64 {
+		pb, _ := ptypes.TimestampProto(at)
+		id, err := store.Create(ctx, &v1.Todo{Title: title, Reminder: pb})
+		if err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		return id
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	due := create("due", now.Add(time.Second))
+	create("missed", now.Add(-2*time.Hour))
+	create("later", now.Add(time.Hour))
+
+	c := &recordingChannel{sent: make(chan notify.Reminder, 10)}
+	o := reminder.Options{Interval: 500 * time.Millisecond, MaxDelay: time.Hour}
+	for i := 0; i < 2; i++ {
+		runner := jobs.NewRunner(nil)
+		runner.Register("reminder-scheduler", jobs.Every(o.Interval), reminder.NewScheduler(db.DB, o, []notify.Channel{c}, nil).FireDue)
+		go runner.Run(ctx)
+	}
+
+	expect := func(title string) {
+		t.Helper()
+		select {
+		case r := <-c.sent:
+			if r.TodoID != due || r.Title != title {
+				t.Fatalf("reminder of todo %d '%s' sent, todo %d '%s' expected", r.TodoID, r.Title, due, title)
+			}
+		case <-ctx.Done():
+			t.Fatalf("reminder of '%s' is not sent", title)
+		}
+	}
+	expect("due")
+
+	// changed reminder fires again
+	pb, _ := ptypes.TimestampProto(time.Now().UTC().Truncate(time.Second).Add(time.Second))
+	if _, err := store.Update(ctx, &v1.Todo{Id: due, Title: "changed", Reminder: pb}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	expect("changed")
+
+	select {
+	case r := <-c.sent:
+		t.Fatalf("reminder of todo %d '%s' sent, none expected", r.TodoID, r.Title)
+	case <-time.After(2 * time.Second):
+	}
+}
+
+// do sends request to gateway, checks status code of response and decodes its body into v if it is not nil
+func do(t *testing.T, s *todotest.Server, method, path, body string, code int, v interface{}) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), callTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, s.GatewayURL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read response of %s %s: %v", method, path, err)
+	}
+	if resp.StatusCode != code {
+		t.Fatalf("%s %s returned %d %s, %d expected", method, path, resp.StatusCode, b, code)
+	}
+	if v != nil {
+		if err := json.Unmarshal(b, v); err != nil {
+			t.Fatalf("failed to decode response of %s %s: %v", method, path, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+//go:build integration
+// +build integration
+
+package todotest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	// mysql driver
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/maslow123/go-grpc/pkg/migrations"
+)
+
+const (
+	// MySQLImage is image of MySQL started by StartMySQL
+	MySQLImage = "mysql:8.0"
+
+	// mysqlPort is port MySQL listens on in container
+	mysqlPort = "3306/tcp"
+
+	// mysqlPassword is password of root user of MySQL in container
+	mysqlPassword = "password"
+
+	// mysqlSchema is database created in container
+	mysqlSchema = "todo"
+
+	// startupTimeout is how long container may take to start and accept connections
+	startupTimeout = 2 * time.Minute
+)
+
+// Database is database running in container for integration tests
+type Database struct {
+	// DB is connection pool of the database, migrations are applied
+	DB *sql.DB
+	// DSN is data source name of the database, e.g. to run server against it
+	DSN string
+}
+
+// StartMySQL starts MySQL in container and applies migrations, container is removed when test ends.
+// Docker must be available, test fails otherwise, so storage changes are not merged untested.
+func StartMySQL(t testing.TB) *Database {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(context.Background(), startupTimeout)
+	defer cancel()
+
+	c, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        MySQLImage,
+			ExposedPorts: []string{mysqlPort},
+			Env: map[string]string{
+				"MYSQL_ROOT_PASSWORD": mysqlPassword,
+				"MYSQL_DATABASE":      mysqlSchema,
+			},
+			WaitingFor: wait.ForListeningPort(mysqlPort).WithStartupTimeout(startupTimeout),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start MySQL container: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := c.Terminate(context.Background()); err != nil {
+			t.Errorf("failed to remove MySQL container: %v", err)
+		}
+	})
+
+	host, err := c.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get host of MySQL container: %v", err)
+	}
+	port, err := c.MappedPort(ctx, mysqlPort)
+	if err != nil {
+		t.Fatalf("failed to get port of MySQL container: %v", err)
+	}
+
+	// same parameters as DSN of the server
+	dsn := fmt.Sprintf("root:%s@tcp(%s:%s)/%s?parseTime=true", mysqlPassword, host, port.Port(), mysqlSchema)
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	// port may be open before MySQL accepts connections
+	for err = db.PingContext(ctx); err != nil; err = db.PingContext(ctx) {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("MySQL container is not ready: %v", err)
+		case <-time.After(time.Second):
+		}
+	}
+
+	if _, err := migrations.Up(ctx, db); err != nil {
+		t.Fatalf("failed to migrate database: %v", err)
+	}
+
+	return &Database{DB: db, DSN: dsn}
+}
+
+// Truncate deletes all rows of tables created by migrations, so tests sharing database start empty
+func (d *Database) Truncate(t testing.TB) {
+	t.Helper()
+
+	for _, table := range []string{"todo", "api_key", "webhook", "webhook_delivery", "device", "event_outbox"} {
+		if _, err := d.DB.Exec("TRUNCATE TABLE `" + table + "`"); err != nil {
+			t.Fatalf("failed to truncate table %s: %v", table, err)
+		}
+	}
+}
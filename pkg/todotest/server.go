@@ -0,0 +1,177 @@
+package todotest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"github.com/maslow123/go-grpc/pkg/client"
+	grpcserver "github.com/maslow123/go-grpc/pkg/protocol/grpc"
+	"github.com/maslow123/go-grpc/pkg/protocol/rest"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufSize is size of buffer of in-process connections
+const bufSize = 1 << 20
+
+// readyTimeout is how long NewServer waits for client to connect
+const readyTimeout = 5 * time.Second
+
+// bufTarget is target in-process listener is dialed by, it is not resolved
+const bufTarget = "passthrough:///bufnet"
+
+// Server is Todo Service running in process for tests
+type Server struct {
+	// Client is connected to the service, it is closed when test ends
+	Client *client.Client
+	// GatewayURL is base URL of HTTP/REST gateway, e.g. "http://127.0.0.1:41234", it is empty without WithGateway
+	GatewayURL string
+}
+
+// Option configures test server
+type Option func(*options)
+
+type options struct {
+	gateway     bool
+	grpcConfig  grpcserver.Config
+	restConfig  rest.Config
+	clientOpts  []client.Option
+	adminServer v1.AdminServiceServer
+}
+
+// WithGateway starts HTTP/REST gateway on loopback interface too, it forwards requests to the service in process
+func WithGateway() Option {
+	return func(o *options) {
+		o.gateway = true
+	}
+}
+
+// WithServerConfig configures gRPC server, e.g. HMACSecret, listeners are set by NewServer
+func WithServerConfig(cfg grpcserver.Config) Option {
+	return func(o *options) {
+		o.grpcConfig = cfg
+	}
+}
+
+// WithGatewayConfig configures gateway started by WithGateway, listeners and gRPC endpoint are set by NewServer
+func WithGatewayConfig(cfg rest.Config) Option {
+	return func(o *options) {
+		o.gateway = true
+		o.restConfig = cfg
+	}
+}
+
+// WithClientOptions adds options of Server.Client, e.g. client.WithHMACSecret
+func WithClientOptions(opts ...client.Option) Option {
+	return func(o *options) {
+		o.clientOpts = append(o.clientOpts, opts...)
+	}
+}
+
+// WithAdminServer serves Admin Service, it is unimplemented by default
+func WithAdminServer(s v1.AdminServiceServer) Option {
+	return func(o *options) {
+		o.adminServer = s
+	}
+}
+
+// NewServer starts Todo Service keeping todos in store over in-process connection and returns it with ready client,
+// empty in-memory store of NewStore is used if store is nil. Servers are stopped and client is closed when test ends.
+func NewServer(t testing.TB, store v1.TodoStore, opts ...Option) *Server {
+	t.Helper()
+
+	o := options{adminServer: &v1.UnimplementedAdminServiceServer{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if store == nil {
+		store = NewStore()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stopped []chan struct{}
+	// run runs server until test ends, it fails test if server fails before
+	run := func(name string, serve func() error) {
+		done := make(chan struct{})
+		stopped = append(stopped, done)
+		go func() {
+			defer close(done)
+			if err := serve(); err != nil && ctx.Err() == nil {
+				t.Errorf("%s server failed: %v", name, err)
+			}
+		}()
+	}
+	t.Cleanup(func() {
+		cancel()
+		for _, done := range stopped {
+			<-done
+		}
+	})
+
+	lis := bufconn.Listen(bufSize)
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+
+	grpcConfig := o.grpcConfig
+	grpcConfig.Listeners = []net.Listener{lis}
+	api := v1.NewTodoServiceServerWithStore(store)
+	run("gRPC", func() error {
+		return grpcserver.RunServer(ctx, api, o.adminServer, grpcConfig)
+	})
+
+	readyCtx, readyCancel := context.WithTimeout(ctx, readyTimeout)
+	defer readyCancel()
+	// gRPC server replaces global gRPC logger before it accepts connections,
+	// so nothing else dials it before first connection is accepted
+	probe, err := lis.DialContext(readyCtx)
+	if err != nil {
+		t.Fatalf("test server is not ready: %v", err)
+	}
+	_ = probe.Close()
+
+	s := &Server{}
+	if o.gateway {
+		httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("failed to listen for gateway: %v", err)
+		}
+		restConfig := o.restConfig
+		restConfig.Listeners = []net.Listener{httpLis}
+		restConfig.GRPCEndpoint = bufTarget
+		restConfig.GRPCDialOptions = append(restConfig.GRPCDialOptions, dialer)
+		if len(restConfig.HMACSecret) == 0 {
+			restConfig.HMACSecret = grpcConfig.HMACSecret
+		}
+		run("HTTP/REST gateway", func() error {
+			return rest.RunServer(ctx, restConfig)
+		})
+		s.GatewayURL = "http://" + httpLis.Addr().String()
+	}
+
+	clientOpts := []client.Option{client.WithDialOptions(dialer)}
+	if len(grpcConfig.HMACSecret) > 0 {
+		clientOpts = append(clientOpts, client.WithHMACSecret(grpcConfig.HMACSecret))
+	}
+	c, err := client.Dial(bufTarget, append(clientOpts, o.clientOpts...)...)
+	if err != nil {
+		t.Fatalf("failed to dial test server: %v", err)
+	}
+	// client is closed before servers are stopped, cleanups run in reverse order
+	t.Cleanup(func() { _ = c.Close() })
+	s.Client = c
+
+	conn := c.Conn()
+	conn.Connect()
+	for st := conn.GetState(); st != connectivity.Ready; st = conn.GetState() {
+		if !conn.WaitForStateChange(readyCtx, st) {
+			t.Fatalf("test server is not ready: %v", readyCtx.Err())
+		}
+	}
+
+	return s
+}
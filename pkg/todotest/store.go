@@ -0,0 +1,88 @@
+package todotest
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	v1 "github.com/maslow123/go-grpc/pkg/api/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// memoryStore is v1.TodoStore keeping todos in memory
+type memoryStore struct {
+	mu     sync.Mutex
+	lastID int64
+	todos  map[int64]*v1.Todo
+}
+
+// NewStore returns empty v1.TodoStore keeping todos in memory, it is safe for concurrent use
+func NewStore() v1.TodoStore {
+	return &memoryStore{todos: map[int64]*v1.Todo{}}
+}
+
+// Create stores copy of td with next ID
+func (s *memoryStore) Create(_ context.Context, td *v1.Todo) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	t := proto.Clone(td).(*v1.Todo)
+	t.Id = s.lastID
+	s.todos[t.Id] = t
+
+	return t.Id, nil
+}
+
+// Read returns copy of todo with id
+func (s *memoryStore) Read(_ context.Context, id int64) (*v1.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.todos[id]
+	if !ok {
+		return nil, v1.ErrNotFound
+	}
+
+	return proto.Clone(t).(*v1.Todo), nil
+}
+
+// ReadAll returns copies of all todos ordered by ID
+func (s *memoryStore) ReadAll(context.Context) ([]*v1.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]*v1.Todo, 0, len(s.todos))
+	for _, t := range s.todos {
+		list = append(list, proto.Clone(t).(*v1.Todo))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Id < list[j].Id })
+
+	return list, nil
+}
+
+// Update replaces todo with td.Id by copy of td
+func (s *memoryStore) Update(_ context.Context, td *v1.Todo) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.todos[td.Id]; !ok {
+		return 0, nil
+	}
+	s.todos[td.Id] = proto.Clone(td).(*v1.Todo)
+
+	return 1, nil
+}
+
+// Delete deletes todo with id
+func (s *memoryStore) Delete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.todos[id]; !ok {
+		return v1.ErrNotFound
+	}
+	delete(s.todos, id)
+
+	return nil
+}
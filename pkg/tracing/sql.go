@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	"database/sql"
+
+	"github.com/XSAM/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+// OpenDB opens database with driver wrapped to record query spans. Spans are only
+// created as children of span of the current request, they carry SQL statement
+// and an event for every row returned.
+func OpenDB(driverName, dsn string) (*sql.DB, error) {
+	name, err := otelsql.Register(driverName, semconv.DBSystemMySQL.Value.AsString(),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			RowsNext:       true,
+			DisableErrSkip: true,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.Open(name, dsn)
+}
@@ -0,0 +1,27 @@
+package version
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Build information, it is set at build time, e.g.
+// go build -ldflags "-X github.com/maslow123/go-grpc/pkg/version.Version=v1.0.0"
+var (
+	// Version is release version of the build
+	Version = "dev"
+	// GitCommit is git commit the build is made from
+	GitCommit = "unknown"
+	// BuildDate is date and time of the build in RFC 3339 format
+	BuildDate = "unknown"
+)
+
+// GoVersion returns Go version the build is made with
+func GoVersion() string {
+	return runtime.Version()
+}
+
+// String returns build information in human readable form
+func String() string {
+	return fmt.Sprintf("%s (commit %s, built %s, %s)", Version, GitCommit, BuildDate, GoVersion())
+}
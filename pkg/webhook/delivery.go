@@ -0,0 +1,262 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/events"
+	"github.com/maslow123/go-grpc/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// EventHeader is HTTP header that contains type of posted event
+	EventHeader = "X-Webhook-Event"
+
+	// DeliveryHeader is HTTP header that contains ID of delivery, it is the same for all attempts
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	// delivery statuses stored in webhook_delivery table, they match v1.WebhookDelivery_Status names
+	statusPending   = "PENDING"
+	statusDelivered = "DELIVERED"
+	statusFailed    = "FAILED"
+
+	// maxErrorLen is maximum length of stored error of failed attempt
+	maxErrorLen = 1024
+)
+
+// DeliveryOptions configures delivery of events to webhooks
+type DeliveryOptions struct {
+	// Timeout is how long single attempt may take
+	Timeout time.Duration
+	// MaxAttempts is number of attempts after which delivery fails
+	MaxAttempts int
+	// RetryBackoff is delay before second attempt, it is doubled for every next attempt up to an hour
+	RetryBackoff time.Duration
+	// BatchSize is maximum number of deliveries attempted at the same time
+	BatchSize int
+}
+
+// DefaultDeliveryOptions are options used for zero fields of DeliveryOptions
+var DefaultDeliveryOptions = DeliveryOptions{
+	Timeout:      10 * time.Second,
+	MaxAttempts:  8,
+	RetryBackoff: 10 * time.Second,
+	BatchSize:    20,
+}
+
+// maxRetryBackoff is maximum delay between attempts
+const maxRetryBackoff = time.Hour
+
+// Dispatcher delivers events of todo changes to webhooks subscribed to them.
+// Deliveries are stored in database by Publish and posted by DeliverPending, so they survive restarts.
+// Event is stored once for every webhook even if it is published again, and delivery is leased while
+// it is attempted, so it is posted once even if several servers run Dispatcher.
+type Dispatcher struct {
+	db     *sql.DB
+	o      DeliveryOptions
+	client *http.Client
+	log    *zap.Logger
+}
+
+// NewDispatcher returns dispatcher of deliveries stored in db
+func NewDispatcher(db *sql.DB, o DeliveryOptions, log *zap.Logger) *Dispatcher {
+	if o.Timeout <= 0 {
+		o.Timeout = DefaultDeliveryOptions.Timeout
+	}
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = DefaultDeliveryOptions.MaxAttempts
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = DefaultDeliveryOptions.RetryBackoff
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultDeliveryOptions.BatchSize
+	}
+
+	return &Dispatcher{
+		db:     db,
+		o:      o,
+		client: &http.Client{Timeout: o.Timeout},
+		log:    logger.OrNop(log),
+	}
+}
+
+// Publish stores delivery of event for every webhook subscribed to its type, it implements events.Publisher
+func (d *Dispatcher) Publish(ctx context.Context, e events.Event) error {
+	payload, err := e.Encode(events.EncodingJSON)
+	if err != nil {
+		return err
+	}
+
+	// webhooks without events filter get all events, deliveries of event published before are kept
+	query := `INSERT INTO webhook_delivery(webhook_id, event_id, event_type, payload, next_attempt_at)
+		SELECT id, ?, ?, ?, ? FROM webhook WHERE events = '' OR FIND_IN_SET(?, events) > 0
+		ON DUPLICATE KEY UPDATE webhook_delivery.id = webhook_delivery.id`
+	if _, err := d.db.ExecContext(ctx, query, e.ID, string(e.Type), payload, time.Now().UTC(), string(e.Type)); err != nil {
+		return fmt.Errorf("failed to insert into webhook_delivery: %v", err)
+	}
+	return nil
+}
+
+// delivery is pending delivery of event to webhook
+type delivery struct {
+	id        int64
+	eventID   string
+	eventType string
+	payload   []byte
+	attempts  int
+	url       string
+	secret    string
+}
+
+// DeliverPending attempts batch of due deliveries, it is run by jobs.Runner to poll for them
+func (d *Dispatcher) DeliverPending(ctx context.Context) error {
+	now := time.Now().UTC()
+	query := `SELECT d.id, d.event_id, d.event_type, d.payload, d.attempts, w.url, w.secret
+		FROM webhook_delivery d JOIN webhook w ON w.id = d.webhook_id
+		WHERE d.status = ? AND d.next_attempt_at <= ? ORDER BY d.id LIMIT ?`
+	rows, err := d.db.QueryContext(ctx, query, statusPending, now, d.o.BatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to select from webhook_delivery: %v", err)
+	}
+	var due []delivery
+	for rows.Next() {
+		var dl delivery
+		if err := rows.Scan(&dl.id, &dl.eventID, &dl.eventType, &dl.payload, &dl.attempts, &dl.url, &dl.secret); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to retrieve field values from webhook_delivery: %v", err)
+		}
+		due = append(due, dl)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to retrieve data from webhook_delivery: %v", err)
+	}
+
+	// attempts already started are waited for even if claiming fails, so none outlives the run
+	var wg sync.WaitGroup
+	var claimErr error
+	for _, dl := range due {
+		// delivery is leased, so other dispatchers skip it while it is attempted
+		claimed, err := d.claim(ctx, dl.id, now)
+		if err != nil {
+			claimErr = err
+			break
+		}
+		if !claimed {
+			continue
+		}
+
+		wg.Add(1)
+		go func(dl delivery) {
+			defer wg.Done()
+			d.attempt(ctx, dl)
+		}(dl)
+	}
+	wg.Wait()
+
+	return claimErr
+}
+
+// claim leases due delivery until attempt times out, it reports whether delivery was leased
+func (d *Dispatcher) claim(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `UPDATE webhook_delivery SET next_attempt_at = ? WHERE id = ? AND status = ? AND next_attempt_at <= ?`
+	res, err := d.db.ExecContext(ctx, query, now.Add(2*d.o.Timeout), id, statusPending, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim webhook_delivery: %v", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to retrieve rows affected value: %v", err)
+	}
+	return rows == 1, nil
+}
+
+// attempt posts delivery and stores result
+func (d *Dispatcher) attempt(ctx context.Context, dl delivery) {
+	code, err := d.post(ctx, dl)
+	attempts := dl.attempts + 1
+	now := time.Now().UTC()
+
+	var (
+		query string
+		args  []interface{}
+	)
+	switch {
+	case err == nil:
+		query = `UPDATE webhook_delivery SET status = ?, attempts = ?, response_code = ?, last_error = '', delivered_at = ? WHERE id = ?`
+		args = []interface{}{statusDelivered, attempts, code, now, dl.id}
+	case attempts >= d.o.MaxAttempts:
+		d.log.Warn("Webhook delivery failed",
+			zap.Int64("delivery", dl.id),
+			zap.Int("attempts", attempts),
+			zap.String("reason", err.Error()),
+		)
+		query = `UPDATE webhook_delivery SET status = ?, attempts = ?, response_code = ?, last_error = ? WHERE id = ?`
+		args = []interface{}{statusFailed, attempts, code, truncate(err.Error()), dl.id}
+	default:
+		query = `UPDATE webhook_delivery SET attempts = ?, response_code = ?, last_error = ?, next_attempt_at = ? WHERE id = ?`
+		args = []interface{}{attempts, code, truncate(err.Error()), now.Add(d.backoff(attempts)), dl.id}
+	}
+
+	// result is stored even if server is stopping, so attempt is not repeated
+	if _, err := d.db.ExecContext(context.Background(), query, args...); err != nil {
+		d.log.Error("Failed to update webhook_delivery",
+			zap.Int64("delivery", dl.id),
+			zap.String("reason", err.Error()),
+		)
+	}
+}
+
+// post sends signed event to webhook, it returns HTTP status code of response, 0 if there is none
+func (d *Dispatcher) post(ctx context.Context, dl delivery) (int, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, dl.url, bytes.NewReader(dl.payload))
+	if err != nil {
+		return 0, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(EventHeader, dl.eventType)
+	r.Header.Set(DeliveryHeader, strconv.FormatInt(dl.id, 10))
+	SignRequest(r, dl.secret, time.Now(), dl.payload)
+
+	resp, err := d.client.Do(r)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// body is drained, so connection is reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns delay before next attempt after attempts failed ones
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	b := d.o.RetryBackoff
+	for i := 1; i < attempts && b < maxRetryBackoff; i++ {
+		b *= 2
+	}
+	if b > maxRetryBackoff {
+		b = maxRetryBackoff
+	}
+	return b
+}
+
+// truncate returns s cut to length stored in webhook_delivery
+func truncate(s string) string {
+	if len(s) > maxErrorLen {
+		return s[:maxErrorLen]
+	}
+	return s
+}
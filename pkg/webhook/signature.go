@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader is HTTP header that contains payload signature
+	SignatureHeader = "X-Signature"
+
+	// TimestampHeader is HTTP header that contains unix time the payload was signed at
+	TimestampHeader = "X-Signature-Timestamp"
+
+	// signaturePrefix is scheme of the signature in SignatureHeader
+	signaturePrefix = "sha256="
+)
+
+var (
+	// ErrMissingSignature is returned when request has no signature headers
+	ErrMissingSignature = errors.New("webhook signature is missing")
+
+	// ErrInvalidSignature is returned when signature does not match payload
+	ErrInvalidSignature = errors.New("webhook signature is invalid")
+
+	// ErrExpiredSignature is returned when signature timestamp is outside of allowed tolerance
+	ErrExpiredSignature = errors.New("webhook signature is expired")
+)
+
+// Sign returns signature of payload signed at t with subscription secret.
+// Timestamp is part of the signed message, so receivers can reject replayed payloads.
+func Sign(secret string, t time.Time, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(t.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest sets signature headers of webhook request
+func SignRequest(r *http.Request, secret string, t time.Time, payload []byte) {
+	r.Header.Set(TimestampHeader, strconv.FormatInt(t.Unix(), 10))
+	r.Header.Set(SignatureHeader, Sign(secret, t, payload))
+}
+
+// Verify checks signature headers of received webhook payload.
+// Signatures older or newer than tolerance relative to now are rejected.
+func Verify(secret string, h http.Header, payload []byte, tolerance time.Duration, now time.Time) error {
+	sig := h.Get(SignatureHeader)
+	ts := h.Get(TimestampHeader)
+	if len(sig) == 0 || len(ts) == 0 {
+		return ErrMissingSignature
+	}
+
+	if !strings.HasPrefix(sig, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	t := time.Unix(unix, 0)
+	if d := now.Sub(t); d > tolerance || d < -tolerance {
+		return ErrExpiredSignature
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(Sign(secret, t, payload))) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
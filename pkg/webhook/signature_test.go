@@ -0,0 +1,66 @@
+package webhook_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/maslow123/go-grpc/pkg/webhook"
+)
+
+func TestVerify(t *testing.T) {
+	const (
+		secret    = "secret"
+		tolerance = 5 * time.Minute
+	)
+	payload := []byte(`{"type":"TodoCreated"}`)
+	signedAt := time.Unix(1700000000, 0)
+
+	r, err := http.NewRequest(http.MethodPost, "http://localhost/hook", nil)
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	webhook.SignRequest(r, secret, signedAt, payload)
+	signed := r.Header
+
+	// header returns copy of signed headers with key set to value, key is removed if value is empty
+	header := func(key, value string) http.Header {
+		h := signed.Clone()
+		if len(value) == 0 {
+			h.Del(key)
+		} else {
+			h.Set(key, value)
+		}
+		return h
+	}
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		payload []byte
+		now     time.Time
+		want    error
+	}{
+		{"valid", secret, signed, payload, signedAt, nil},
+		{"clock behind within tolerance", secret, signed, payload, signedAt.Add(-tolerance), nil},
+		{"clock ahead within tolerance", secret, signed, payload, signedAt.Add(tolerance), nil},
+		{"replayed", secret, signed, payload, signedAt.Add(tolerance + time.Second), webhook.ErrExpiredSignature},
+		{"signed in future", secret, signed, payload, signedAt.Add(-tolerance - time.Second), webhook.ErrExpiredSignature},
+		{"missing signature", secret, header(webhook.SignatureHeader, ""), payload, signedAt, webhook.ErrMissingSignature},
+		{"missing timestamp", secret, header(webhook.TimestampHeader, ""), payload, signedAt, webhook.ErrMissingSignature},
+		{"unknown scheme", secret, header(webhook.SignatureHeader, "md5=00"), payload, signedAt, webhook.ErrInvalidSignature},
+		{"invalid timestamp", secret, header(webhook.TimestampHeader, "yesterday"), payload, signedAt, webhook.ErrInvalidSignature},
+		{"other timestamp", secret, header(webhook.TimestampHeader, strconv.FormatInt(signedAt.Unix()+1, 10)), payload, signedAt, webhook.ErrInvalidSignature},
+		{"other secret", "other", signed, payload, signedAt, webhook.ErrInvalidSignature},
+		{"other payload", secret, signed, []byte(`{}`), signedAt, webhook.ErrInvalidSignature},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := webhook.Verify(tt.secret, tt.header, tt.payload, tolerance, tt.now); err != tt.want {
+				t.Fatalf("Verify returned %v, %v expected", err, tt.want)
+			}
+		})
+	}
+}